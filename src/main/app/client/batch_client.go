@@ -0,0 +1,100 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/src/main/app/helpers/arrays"
+	"github.com/src/main/app/metrics"
+	"github.com/src/main/app/server"
+)
+
+// BatchClient posts json arrays to a bulk endpoint.
+type BatchClient interface {
+	// PostBatch returns an error by item, nil for the succeeded ones. A failed request fails every item.
+	PostBatch(ids []string, items []json.RawMessage) []error
+}
+
+// BatchResultDTO is the result of an item in the json array of the bulk response, matched by id, or by position
+// when the results have no ids.
+type BatchResultDTO struct {
+	ID     string `json:"id,omitempty"`
+	Status int    `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// RetryAfter seconds of a retry status code.
+	RetryAfter int `json:"retry_after,omitempty"`
+}
+
+// PostBatch posts the items as a json array, the response is a json array of BatchResultDTO. The status of each
+// item is mapped by the response contract, an item without result is a plain failure.
+func (c HTTPPusherClient) PostBatch(ids []string, items []json.RawMessage) []error {
+	errs := make([]error, len(items))
+
+	response, err := c.send(strings.Join(ids, ","), items)
+	if err != nil {
+		metrics.Collector.IncrementCounter(metrics.BatchErrors)
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	var results []BatchResultDTO
+	if err = json.Unmarshal(response.Bytes(), &results); err != nil {
+		metrics.Collector.IncrementCounter(metrics.BatchErrors)
+		for i := range errs {
+			errs[i] = fmt.Errorf("invalid bulk response: %w", err)
+		}
+		return errs
+	}
+
+	resultsByID := make(map[string]BatchResultDTO, len(results))
+	for _, result := range results {
+		if result.ID != "" {
+			resultsByID[result.ID] = result
+		}
+	}
+
+	for i := range items {
+		result, found := resultsByID[ids[i]]
+		if len(resultsByID) == 0 && i < len(results) {
+			result, found = results[i], true
+		}
+
+		switch {
+		case !found:
+			errs[i] = errors.New("missing result in bulk response")
+		case result.Status < 200 || result.Status >= 300:
+			errs[i] = c.toItemError(result)
+		}
+	}
+
+	return errs
+}
+
+// toItemError applies the response contract to an item, the reject header does not apply.
+func (c HTTPPusherClient) toItemError(result BatchResultDTO) error {
+	message := result.Error
+	if message == "" {
+		message = http.StatusText(result.Status)
+	}
+	err := server.NewError(result.Status, message)
+
+	if arrays.Contains(c.contract.RejectStatusCodes, result.Status) {
+		return &RejectError{Err: err}
+	}
+
+	if arrays.Contains(c.contract.RetryStatusCodes, result.Status) && result.RetryAfter > 0 {
+		delay := time.Second * time.Duration(result.RetryAfter)
+		if c.contract.MaxRetryAfter > 0 {
+			delay = min(delay, c.contract.MaxRetryAfter)
+		}
+		return &RetryError{Delay: delay, Err: err}
+	}
+
+	return err
+}
@@ -0,0 +1,86 @@
+package client_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arielsrv/ikp_go-restclient/rest"
+	"github.com/src/main/app/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBulkTarget(t *testing.T, statusCode int, response string) *httptest.Server {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"id":"1"},{"id":"2"},{"id":"3"},{"id":"4"}]`, string(body))
+
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(target.Close)
+
+	return target
+}
+
+func postBatch(target *httptest.Server) []error {
+	batchClient := client.NewHTTPPusherClient(&rest.RequestBuilder{Timeout: time.Second}, target.URL+"/bulk")
+
+	return batchClient.PostBatch([]string{"1", "2", "3", "4"}, []json.RawMessage{
+		json.RawMessage(`{"id":"1"}`),
+		json.RawMessage(`{"id":"2"}`),
+		json.RawMessage(`{"id":"3"}`),
+		json.RawMessage(`{"id":"4"}`),
+	})
+}
+
+func TestHTTPPusherClient_PostBatch(t *testing.T) {
+	target := newBulkTarget(t, http.StatusMultiStatus, `[
+		{"id": "4", "status": 429, "retry_after": 30},
+		{"id": "1", "status": 201},
+		{"id": "2", "status": 422, "error": "invalid order"}
+	]`)
+
+	errs := postBatch(target)
+	assert.Len(t, errs, 4)
+	assert.NoError(t, errs[0])
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, errs[1], &rejectErr)
+	assert.Contains(t, errs[1].Error(), "invalid order")
+
+	assert.Error(t, errs[2])
+	assert.False(t, errors.As(errs[2], &rejectErr))
+
+	var retryErr *client.RetryError
+	assert.ErrorAs(t, errs[3], &retryErr)
+	assert.Equal(t, time.Second*30, retryErr.Delay)
+}
+
+func TestHTTPPusherClient_PostBatchByPosition(t *testing.T) {
+	target := newBulkTarget(t, http.StatusOK, `[{"status": 200}, {"status": 500}, {"status": 200}, {"status": 200}]`)
+
+	errs := postBatch(target)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+	assert.NoError(t, errs[3])
+}
+
+func TestHTTPPusherClient_PostBatchErr(t *testing.T) {
+	for statusCode, response := range map[int]string{
+		http.StatusUnprocessableEntity: `{"error": "invalid batch"}`,
+		http.StatusOK:                  `{"results": []}`,
+	} {
+		errs := postBatch(newBulkTarget(t, statusCode, response))
+		assert.Len(t, errs, 4)
+		for _, err := range errs {
+			assert.Error(t, err)
+		}
+	}
+}
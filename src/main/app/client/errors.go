@@ -0,0 +1,33 @@
+package client
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetryError is a target response asking to push the message again after Delay, from the Retry-After header.
+type RetryError struct {
+	Delay time.Duration
+	Err   error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("retry after %s: %s", e.Delay, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// RejectError is a permanent reject of the message by the target, it must not be pushed again.
+type RejectError struct {
+	Err error
+}
+
+func (e *RejectError) Error() string {
+	return fmt.Sprintf("rejected: %s", e.Err)
+}
+
+func (e *RejectError) Unwrap() error {
+	return e.Err
+}
@@ -1,12 +1,15 @@
 package client
 
 import (
+	"encoding/json"
 	"errors"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/arielsrv/ikp_go-restclient/rest"
+	"github.com/src/main/app/helpers/arrays"
 	"github.com/src/main/app/log"
 	"github.com/src/main/app/metrics"
 	"github.com/src/main/app/server"
@@ -14,23 +17,73 @@ import (
 
 type AppClient interface {
 	PostMessage(body *RequestBody) error
+	// PostPayload posts a body already encoded as json, i.e. a transformed message.
+	PostPayload(id string, payload json.RawMessage) error
+	// PostBinary posts a binary payload as it is, the request builder must use rest.BYTES and a Content-Type header.
+	PostBinary(id string, payload []byte) error
 }
 
 type HTTPPusherClient struct {
 	rb             rest.IRequestBuilder
 	targetEndpoint string
+	contract       ResponseContract
 }
 
-func NewHTTPPusherClient(rb rest.IRequestBuilder, endpoint string) HTTPPusherClient {
-	return HTTPPusherClient{
+// ResponseContract maps the non 2xx target responses to the message outcome, a 2xx is always an ack.
+type ResponseContract struct {
+	// RetryStatusCodes with a Retry-After header delay the next push of the message.
+	RetryStatusCodes []int
+	// RejectStatusCodes are permanent rejects, the message goes to the dead letter queue.
+	RejectStatusCodes []int
+	// RejectHeader in any non 2xx response is a permanent reject.
+	RejectHeader string
+	// MaxRetryAfter caps the Retry-After delay, the sqs max visibility timeout by default.
+	MaxRetryAfter time.Duration
+}
+
+// DefaultResponseContract is used when the client is created without contract.
+var DefaultResponseContract = ResponseContract{
+	RetryStatusCodes:  []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+	RejectStatusCodes: []int{http.StatusUnprocessableEntity},
+	RejectHeader:      "X-Reject-Message",
+	MaxRetryAfter:     time.Hour * 12,
+}
+
+func NewHTTPPusherClient(rb rest.IRequestBuilder, endpoint string, contract ...ResponseContract) HTTPPusherClient {
+	httpPusherClient := HTTPPusherClient{
 		rb:             rb,
 		targetEndpoint: endpoint,
+		contract:       DefaultResponseContract,
 	}
+
+	if len(contract) > 0 {
+		httpPusherClient.contract = contract[0]
+	}
+
+	return httpPusherClient
 }
 
 func (c HTTPPusherClient) PostMessage(requestBody *RequestBody) error {
+	return c.post(requestBody.ID, requestBody)
+}
+
+func (c HTTPPusherClient) PostPayload(id string, payload json.RawMessage) error {
+	return c.post(id, payload)
+}
+
+func (c HTTPPusherClient) PostBinary(id string, payload []byte) error {
+	return c.post(id, payload)
+}
+
+func (c HTTPPusherClient) post(id string, body any) error {
+	_, err := c.send(id, body)
+	return err
+}
+
+// send posts the body, the response is returned with the error of the contract when the target answered.
+func (c HTTPPusherClient) send(id string, body any) (*rest.Response, error) {
 	startTime := time.Now()
-	response := c.rb.Post(c.targetEndpoint, requestBody)
+	response := c.rb.Post(c.targetEndpoint, body)
 	elapsedTime := time.Since(startTime)
 
 	metrics.Collector.RecordExecutionTime(metrics.PusherHTTPTime, elapsedTime)
@@ -39,10 +92,10 @@ func (c HTTPPusherClient) PostMessage(requestBody *RequestBody) error {
 		var err net.Error
 		if errors.As(response.Err, &err) && err.Timeout() {
 			log.Warnf("pusher timeout, discuss cap theorem, possible inconsistency ensure handle duplicates from target app, "+
-				"MessageId: %s", requestBody.ID)
+				"MessageId: %s", id)
 			metrics.Collector.IncrementCounter(metrics.PusherHTTPTimeout)
 		}
-		return response.Err
+		return nil, response.Err
 	}
 
 	switch {
@@ -55,10 +108,52 @@ func (c HTTPPusherClient) PostMessage(requestBody *RequestBody) error {
 	}
 
 	if !c.isSuccess(response) {
-		return server.NewError(response.StatusCode, response.String())
+		return response, c.toError(response)
+	}
+
+	return response, nil
+}
+
+// toError applies the response contract: reject, retry after or a plain failure.
+func (c HTTPPusherClient) toError(response *rest.Response) error {
+	err := server.NewError(response.StatusCode, response.String())
+
+	if arrays.Contains(c.contract.RejectStatusCodes, response.StatusCode) ||
+		(c.contract.RejectHeader != "" && response.Header.Get(c.contract.RejectHeader) != "") {
+		return &RejectError{Err: err}
+	}
+
+	if arrays.Contains(c.contract.RetryStatusCodes, response.StatusCode) {
+		if delay, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			if c.contract.MaxRetryAfter > 0 {
+				delay = min(delay, c.contract.MaxRetryAfter)
+			}
+			return &RetryError{Delay: delay, Err: err}
+		}
+	}
+
+	return err
+}
+
+// parseRetryAfter reads delay seconds or an http date, a past date is no delay.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Second * time.Duration(seconds), true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
 	}
 
-	return nil
+	return max(time.Until(date), 0), true
 }
 
 func (c HTTPPusherClient) isSuccess(response *rest.Response) bool {
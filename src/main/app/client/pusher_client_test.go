@@ -1,12 +1,15 @@
 package client_test
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/arielsrv/ikp_go-restclient/rest"
 	"github.com/src/main/app/client"
+	"github.com/src/main/app/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -103,6 +106,22 @@ func TestNewHTTPPusherClient(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestNewHTTPPusherClientPostPayload(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	rb.On("Post").Return(getResponse())
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news").PostPayload("1", json.RawMessage(`{"order_id":1}`))
+	assert.NoError(t, err)
+}
+
+func TestNewHTTPPusherClientPostBinary(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	rb.On("Post").Return(getResponse())
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news").PostBinary("1", []byte{0x02, 0x04})
+	assert.NoError(t, err)
+}
+
 func TestNewHTTPPusherClientErr_400(t *testing.T) {
 	rb := new(MockRequestBuilder)
 	rb.On("Post").Return(getHTTPErrorResponse(http.StatusBadRequest))
@@ -208,3 +227,96 @@ func getResponse() *rest.Response {
 
 	return response
 }
+
+func getContractResponse(statusCode int, header http.Header) *rest.Response {
+	response := getHTTPErrorResponse(statusCode)
+	response.Header = header
+
+	return response
+}
+
+func TestNewHTTPPusherClientRetryAfter(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	rb.On("Post").Return(getContractResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"60"}}))
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news").PostMessage(&client.RequestBody{ID: "1"})
+
+	var retryErr *client.RetryError
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, time.Minute, retryErr.Delay)
+
+	var apiError *server.Error
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, http.StatusTooManyRequests, apiError.StatusCode)
+}
+
+func TestNewHTTPPusherClientRetryAfterDate(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	retryAfter := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	rb.On("Post").Return(getContractResponse(http.StatusServiceUnavailable, http.Header{"Retry-After": {retryAfter}}))
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news").PostMessage(&client.RequestBody{ID: "1"})
+
+	var retryErr *client.RetryError
+	assert.ErrorAs(t, err, &retryErr)
+	assert.InDelta(t, time.Hour.Seconds(), retryErr.Delay.Seconds(), 2)
+}
+
+func TestNewHTTPPusherClientRetryAfterMax(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	rb.On("Post").Return(getContractResponse(http.StatusTooManyRequests, http.Header{"Retry-After": {"600"}}))
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news", client.ResponseContract{
+		RetryStatusCodes: []int{http.StatusTooManyRequests},
+		MaxRetryAfter:    time.Minute,
+	}).PostMessage(&client.RequestBody{ID: "1"})
+
+	var retryErr *client.RetryError
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, time.Minute, retryErr.Delay)
+}
+
+func TestNewHTTPPusherClientRetryWithoutRetryAfter(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	rb.On("Post").Return(getContractResponse(http.StatusServiceUnavailable, http.Header{}))
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news").PostMessage(&client.RequestBody{ID: "1"})
+
+	var retryErr *client.RetryError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &retryErr))
+}
+
+func TestNewHTTPPusherClientRejectStatusCode(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	rb.On("Post").Return(getContractResponse(http.StatusUnprocessableEntity, http.Header{}))
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news").PostMessage(&client.RequestBody{ID: "1"})
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+}
+
+func TestNewHTTPPusherClientRejectHeader(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	rb.On("Post").Return(getContractResponse(http.StatusBadRequest, http.Header{"X-Reject-Message": {"invalid order"}}))
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news").PostMessage(&client.RequestBody{ID: "1"})
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+}
+
+func TestNewHTTPPusherClientWithoutContract(t *testing.T) {
+	rb := new(MockRequestBuilder)
+	rb.On("Post").Return(getContractResponse(http.StatusUnprocessableEntity, http.Header{"Retry-After": {"60"}}))
+
+	err := client.NewHTTPPusherClient(rb, "https://my.app/news", client.ResponseContract{}).
+		PostMessage(&client.RequestBody{ID: "1"})
+
+	var rejectErr *client.RejectError
+	var retryErr *client.RetryError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &rejectErr))
+	assert.False(t, errors.As(err, &retryErr))
+}
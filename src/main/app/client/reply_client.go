@@ -0,0 +1,38 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Reply is the response of the target to a push.
+type Reply struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// ReplyClient posts a message and returns the response of the target.
+type ReplyClient interface {
+	// PostReply posts a client.RequestBody or a json payload. The reply is returned for the final outcomes, a 2xx
+	// or a RejectError, the retries have no reply.
+	PostReply(id string, body any) (*Reply, error)
+}
+
+func (c HTTPPusherClient) PostReply(id string, body any) (*Reply, error) {
+	response, err := c.send(id, body)
+	if response == nil {
+		return nil, err
+	}
+
+	var rejectErr *RejectError
+	if err != nil && !errors.As(err, &rejectErr) {
+		return nil, err
+	}
+
+	return &Reply{
+		StatusCode: response.StatusCode,
+		Header:     response.Header,
+		Body:       response.Bytes(),
+	}, err
+}
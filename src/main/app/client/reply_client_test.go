@@ -0,0 +1,48 @@
+package client_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/arielsrv/ikp_go-restclient/rest"
+	"github.com/src/main/app/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func postReply(t *testing.T, statusCode int, response string) (*client.Reply, error) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(target.Close)
+
+	return client.NewHTTPPusherClient(&rest.RequestBuilder{Timeout: time.Second}, target.URL+"/orders").
+		PostReply("1", &client.RequestBody{ID: "1", Msg: "hello"})
+}
+
+func TestHTTPPusherClient_PostReply(t *testing.T) {
+	reply, err := postReply(t, http.StatusCreated, `{"invoice_id": 10}`)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, reply.StatusCode)
+	assert.JSONEq(t, `{"invoice_id": 10}`, string(reply.Body))
+}
+
+func TestHTTPPusherClient_PostReplyReject(t *testing.T) {
+	reply, err := postReply(t, http.StatusUnprocessableEntity, `{"error": "invalid order"}`)
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, reply.StatusCode)
+}
+
+func TestHTTPPusherClient_PostReplyErr(t *testing.T) {
+	reply, err := postReply(t, http.StatusInternalServerError, "internal server error")
+
+	var rejectErr *client.RejectError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &rejectErr))
+	assert.Nil(t, reply)
+}
@@ -0,0 +1,123 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type Format string
+
+const (
+	Avro     Format = "avro"
+	Protobuf Format = "protobuf"
+)
+
+// Codec converts a binary payload to json.
+type Codec interface {
+	JSON(payload []byte) (json.RawMessage, error)
+}
+
+// New returns the codec of a descriptor, an avro schema or a protobuf descriptor set with the messageType.
+func New(format Format, descriptor []byte, messageType string) (Codec, error) {
+	switch format {
+	case Avro:
+		return NewAvro(descriptor)
+	case Protobuf:
+		return NewProtobuf(descriptor, messageType)
+	default:
+		return nil, fmt.Errorf("codec: invalid format %s", format)
+	}
+}
+
+type AvroCodec struct {
+	codec *goavro.Codec
+}
+
+func NewAvro(schema []byte) (*AvroCodec, error) {
+	avroCodec, err := goavro.NewCodec(string(schema))
+	if err != nil {
+		return nil, fmt.Errorf("codec avro: %w", err)
+	}
+
+	return &AvroCodec{codec: avroCodec}, nil
+}
+
+// JSON returns the avro json encoding, unions are wrapped in an object with the type name.
+func (c AvroCodec) JSON(payload []byte) (json.RawMessage, error) {
+	native, remaining, err := c.codec.NativeFromBinary(payload)
+	if err != nil {
+		return nil, fmt.Errorf("codec avro: %w", err)
+	}
+
+	if len(remaining) > 0 {
+		return nil, fmt.Errorf("codec avro: %d trailing bytes", len(remaining))
+	}
+
+	textual, err := c.codec.TextualFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("codec avro: %w", err)
+	}
+
+	return textual, nil
+}
+
+type ProtobufCodec struct {
+	descriptor protoreflect.MessageDescriptor
+	marshal    protojson.MarshalOptions
+}
+
+// NewProtobuf reads a descriptor set with its imports, protoc --include_imports --descriptor_set_out.
+func NewProtobuf(descriptorSet []byte, messageType string) (*ProtobufCodec, error) {
+	if messageType == "" {
+		return nil, errors.New("codec protobuf: message type is required")
+	}
+
+	var fileDescriptorSet descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(descriptorSet, &fileDescriptorSet); err != nil {
+		return nil, fmt.Errorf("codec protobuf: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(&fileDescriptorSet)
+	if err != nil {
+		return nil, fmt.Errorf("codec protobuf: %w", err)
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(messageType))
+	if err != nil {
+		return nil, fmt.Errorf("codec protobuf: %s: %w", messageType, err)
+	}
+
+	messageDescriptor, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("codec protobuf: %s is not a message", messageType)
+	}
+
+	return &ProtobufCodec{
+		descriptor: messageDescriptor,
+		marshal:    protojson.MarshalOptions{UseProtoNames: true},
+	}, nil
+}
+
+// JSON returns the protobuf json mapping with the field names of the descriptor.
+func (c ProtobufCodec) JSON(payload []byte) (json.RawMessage, error) {
+	message := dynamicpb.NewMessage(c.descriptor)
+	if err := proto.Unmarshal(payload, message); err != nil {
+		return nil, fmt.Errorf("codec protobuf: %w", err)
+	}
+
+	encoded, err := c.marshal.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("codec protobuf: %w", err)
+	}
+
+	return encoded, nil
+}
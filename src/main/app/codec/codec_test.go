@@ -0,0 +1,102 @@
+package codec_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/src/main/app/codec"
+	"github.com/src/main/app/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func readSchema(t *testing.T, name string) []byte {
+	schema, err := os.ReadFile(config.Resource(name))
+	assert.NoError(t, err)
+	return schema
+}
+
+func avroOrder(t *testing.T) []byte {
+	avroCodec, err := goavro.NewCodec(string(readSchema(t, "schemas/order_created.avsc")))
+	assert.NoError(t, err)
+
+	payload, err := avroCodec.BinaryFromNative(nil, map[string]any{
+		"order_id":    int64(1),
+		"customer_id": int64(2),
+		"status":      "created",
+	})
+	assert.NoError(t, err)
+
+	return payload
+}
+
+func protobufOrder() []byte {
+	payload := protowire.AppendTag(nil, 1, protowire.VarintType)
+	payload = protowire.AppendVarint(payload, 1)
+	payload = protowire.AppendTag(payload, 2, protowire.VarintType)
+	payload = protowire.AppendVarint(payload, 2)
+	payload = protowire.AppendTag(payload, 3, protowire.BytesType)
+	return protowire.AppendString(payload, "created")
+}
+
+func TestNew(t *testing.T) {
+	avroCodec, err := codec.New(codec.Avro, readSchema(t, "schemas/order_created.avsc"), "")
+	assert.NoError(t, err)
+	assert.IsType(t, &codec.AvroCodec{}, avroCodec)
+
+	protobufCodec, err := codec.New(codec.Protobuf, readSchema(t, "schemas/order_created.pb"), "orders.OrderCreated")
+	assert.NoError(t, err)
+	assert.IsType(t, &codec.ProtobufCodec{}, protobufCodec)
+
+	_, err = codec.New("thrift", nil, "")
+	assert.Error(t, err)
+}
+
+func TestAvroCodec_JSON(t *testing.T) {
+	avroCodec, err := codec.NewAvro(readSchema(t, "schemas/order_created.avsc"))
+	assert.NoError(t, err)
+
+	actual, err := avroCodec.JSON(avroOrder(t))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"order_id": 1, "customer_id": 2, "status": "created"}`, string(actual))
+
+	_, err = avroCodec.JSON([]byte{0x02})
+	assert.Error(t, err)
+
+	_, err = avroCodec.JSON(append(avroOrder(t), 0x00))
+	assert.Error(t, err)
+}
+
+func TestNewAvroErr(t *testing.T) {
+	_, err := codec.NewAvro([]byte(`{"type": "record"}`))
+	assert.Error(t, err)
+}
+
+func TestProtobufCodec_JSON(t *testing.T) {
+	protobufCodec, err := codec.NewProtobuf(readSchema(t, "schemas/order_created.pb"), "orders.OrderCreated")
+	assert.NoError(t, err)
+
+	actual, err := protobufCodec.JSON(protobufOrder())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"order_id": "1", "customer_id": "2", "status": "created"}`, string(actual))
+
+	_, err = protobufCodec.JSON([]byte{0xff})
+	assert.Error(t, err)
+}
+
+func TestNewProtobufErr(t *testing.T) {
+	descriptorSet := readSchema(t, "schemas/order_created.pb")
+
+	_, err := codec.NewProtobuf(descriptorSet, "")
+	assert.Error(t, err)
+
+	_, err = codec.NewProtobuf(descriptorSet, "orders.OrderPaid")
+	assert.Error(t, err)
+
+	_, err = codec.NewProtobuf(descriptorSet, "orders")
+	assert.Error(t, err)
+
+	_, err = codec.NewProtobuf([]byte{0xff}, "orders.OrderCreated")
+	assert.Error(t, err)
+}
@@ -0,0 +1,174 @@
+package codec
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/arielsrv/ikp_go-restclient/rest"
+	"github.com/src/main/app/server"
+)
+
+// Registry schema types, an empty type is avro.
+const (
+	AvroSchemaType     = "AVRO"
+	ProtobufSchemaType = "PROTOBUF"
+)
+
+const magicByte = 0
+
+var (
+	ErrWireFormat     = errors.New("codec: invalid wire format")
+	ErrSchemaNotFound = errors.New("codec: schema not found")
+)
+
+// Resolver returns the codec of a payload and the payload to decode.
+type Resolver interface {
+	Resolve(payload []byte) (Codec, []byte, error)
+}
+
+type fixed struct {
+	codec Codec
+}
+
+// Fixed resolves every payload with the same codec, i.e. of a local descriptor.
+func Fixed(codec Codec) Resolver {
+	return fixed{codec: codec}
+}
+
+func (f fixed) Resolve(payload []byte) (Codec, []byte, error) {
+	return f.codec, payload, nil
+}
+
+type RegistryConfig struct {
+	URL string
+	// MessageType of the protobuf schemas, the message indexes of the wire format are skipped.
+	MessageType string
+}
+
+// Registry resolves the codec of the schema id of the wire format, magic byte 0 and a 4 bytes big endian id,
+// from a schema registry compatible api: GET /schemas/ids/{id}. The protobuf schemas are base64 descriptor sets.
+// The schemas of an id never change, so they are cached without expiration.
+type Registry struct {
+	url         string
+	messageType string
+	rb          rest.IRequestBuilder
+	mutex       sync.RWMutex
+	codecs      map[int]Codec
+}
+
+type SchemaDTO struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+func NewRegistry(config RegistryConfig, rb rest.IRequestBuilder) (*Registry, error) {
+	if config.URL == "" {
+		return nil, errors.New("codec registry: url is required")
+	}
+
+	return &Registry{
+		url:         strings.TrimSuffix(config.URL, "/"),
+		messageType: config.MessageType,
+		rb:          rb,
+		codecs:      map[int]Codec{},
+	}, nil
+}
+
+func (r *Registry) Resolve(payload []byte) (Codec, []byte, error) {
+	if len(payload) < 5 || payload[0] != magicByte {
+		return nil, nil, ErrWireFormat
+	}
+
+	id := int(binary.BigEndian.Uint32(payload[1:5]))
+	codec, err := r.codec(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	payload = payload[5:]
+	if _, ok := codec.(*ProtobufCodec); ok {
+		if payload, err = skipMessageIndexes(payload); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return codec, payload, nil
+}
+
+func (r *Registry) codec(id int) (Codec, error) {
+	r.mutex.RLock()
+	codec, found := r.codecs[id]
+	r.mutex.RUnlock()
+	if found {
+		return codec, nil
+	}
+
+	response := r.rb.Get(fmt.Sprintf("%s/schemas/ids/%d", r.url, id))
+	if response.Err != nil {
+		return nil, fmt.Errorf("codec registry: %w", response.Err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: id %d", ErrSchemaNotFound, id)
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("codec registry: %w", server.NewError(response.StatusCode, response.String()))
+	}
+
+	var schemaDTO SchemaDTO
+	if err := json.Unmarshal(response.Bytes(), &schemaDTO); err != nil {
+		return nil, fmt.Errorf("codec registry: %w", err)
+	}
+
+	codec, err := newRegistryCodec(schemaDTO, r.messageType)
+	if err != nil {
+		return nil, fmt.Errorf("codec registry: schema %d: %w", id, err)
+	}
+
+	r.mutex.Lock()
+	r.codecs[id] = codec
+	r.mutex.Unlock()
+
+	return codec, nil
+}
+
+func newRegistryCodec(schemaDTO SchemaDTO, messageType string) (Codec, error) {
+	switch schemaDTO.SchemaType {
+	case "", AvroSchemaType:
+		return NewAvro([]byte(schemaDTO.Schema))
+	case ProtobufSchemaType:
+		descriptorSet, err := base64.StdEncoding.DecodeString(schemaDTO.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("not a base64 descriptor set: %w", err)
+		}
+		return NewProtobuf(descriptorSet, messageType)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", schemaDTO.SchemaType)
+	}
+}
+
+// skipMessageIndexes skips the zigzag varint count and indexes of the message in the schema, a single 0 is the
+// first message.
+func skipMessageIndexes(payload []byte) ([]byte, error) {
+	count, n := binary.Varint(payload)
+	if n <= 0 || count < 0 {
+		return nil, ErrWireFormat
+	}
+	payload = payload[n:]
+
+	for i := int64(0); i < count; i++ {
+		if _, n = binary.Varint(payload); n <= 0 {
+			return nil, ErrWireFormat
+		}
+		payload = payload[n:]
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,112 @@
+package codec_test
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arielsrv/ikp_go-restclient/rest"
+	"github.com/src/main/app/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRegistryAPI(t *testing.T, requests *atomic.Int32) *httptest.Server {
+	schemas := map[string]codec.SchemaDTO{
+		"/schemas/ids/1": {Schema: string(readSchema(t, "schemas/order_created.avsc"))},
+		"/schemas/ids/2": {
+			Schema:     base64.StdEncoding.EncodeToString(readSchema(t, "schemas/order_created.pb")),
+			SchemaType: codec.ProtobufSchemaType,
+		},
+		"/schemas/ids/3": {Schema: "syntax = \"proto3\";", SchemaType: codec.ProtobufSchemaType},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		schema, found := schemas[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(w).Encode(schema))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func wireFormat(id uint32, payload ...[]byte) []byte {
+	framed := binary.BigEndian.AppendUint32([]byte{0}, id)
+	for _, part := range payload {
+		framed = append(framed, part...)
+	}
+	return framed
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	var requests atomic.Int32
+	registry, err := codec.NewRegistry(codec.RegistryConfig{
+		URL:         newRegistryAPI(t, &requests).URL,
+		MessageType: "orders.OrderCreated",
+	}, &rest.RequestBuilder{Timeout: time.Second})
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		avroCodec, payload, resolveErr := registry.Resolve(wireFormat(1, avroOrder(t)))
+		assert.NoError(t, resolveErr)
+		actual, resolveErr := avroCodec.JSON(payload)
+		assert.NoError(t, resolveErr)
+		assert.JSONEq(t, `{"order_id": 1, "customer_id": 2, "status": "created"}`, string(actual))
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// message indexes: a single 0 is the first message, or a count and the indexes
+	for _, indexes := range [][]byte{{0x00}, {0x02, 0x00}} {
+		protobufCodec, payload, resolveErr := registry.Resolve(wireFormat(2, indexes, protobufOrder()))
+		assert.NoError(t, resolveErr)
+		actual, resolveErr := protobufCodec.JSON(payload)
+		assert.NoError(t, resolveErr)
+		assert.JSONEq(t, `{"order_id": "1", "customer_id": "2", "status": "created"}`, string(actual))
+	}
+}
+
+func TestRegistry_ResolveErr(t *testing.T) {
+	var requests atomic.Int32
+	registry, err := codec.NewRegistry(codec.RegistryConfig{
+		URL:         newRegistryAPI(t, &requests).URL,
+		MessageType: "orders.OrderCreated",
+	}, &rest.RequestBuilder{Timeout: time.Second})
+	assert.NoError(t, err)
+
+	_, _, err = registry.Resolve(avroOrder(t))
+	assert.ErrorIs(t, err, codec.ErrWireFormat)
+
+	_, _, err = registry.Resolve(wireFormat(9, avroOrder(t)))
+	assert.ErrorIs(t, err, codec.ErrSchemaNotFound)
+
+	_, _, err = registry.Resolve(wireFormat(3, []byte{0x00}))
+	assert.Error(t, err)
+
+	_, _, err = registry.Resolve(wireFormat(2))
+	assert.ErrorIs(t, err, codec.ErrWireFormat)
+}
+
+func TestNewRegistryErr(t *testing.T) {
+	_, err := codec.NewRegistry(codec.RegistryConfig{}, &rest.RequestBuilder{})
+	assert.Error(t, err)
+}
+
+func TestFixed(t *testing.T) {
+	avroCodec, err := codec.NewAvro(readSchema(t, "schemas/order_created.avsc"))
+	assert.NoError(t, err)
+
+	resolved, payload, err := codec.Fixed(avroCodec).Resolve([]byte{0x01})
+	assert.NoError(t, err)
+	assert.Equal(t, avroCodec, resolved)
+	assert.Equal(t, []byte{0x01}, payload)
+}
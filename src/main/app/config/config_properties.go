@@ -18,6 +18,8 @@ const (
 	File = "config.yml"
 )
 
+var resourcesPath string
+
 func init() {
 	showWd()
 	_, caller, _, _ := runtime.Caller(0)
@@ -32,6 +34,8 @@ func init() {
 		root = path.Join(wd, "/src")
 	}
 
+	resourcesPath = fmt.Sprintf("%s/resources", root)
+
 	propertiesPath, environment, scope :=
 		fmt.Sprintf("%s/resources/config", root),
 		env.GetEnv(),
@@ -70,6 +74,11 @@ func init() {
 	log.Infof("ENV: %s, SCOPE: %s", environment, scope)
 }
 
+// Resource returns the path of a file in src/resources, i.e. schemas/order.json.
+func Resource(name string) string {
+	return path.Join(resourcesPath, name)
+}
+
 func showWd() {
 	wd, err := os.Getwd()
 	if err != nil {
@@ -88,6 +97,15 @@ func String(key string) string {
 	return value
 }
 
+func TryString(key string, defaultValue string) string {
+	value, err := archaius.GetValue(key).ToString()
+	if err != nil {
+		log.Warnf(fmt.Sprintf("warn: config %s not found, fallback to %s", key, defaultValue))
+		return defaultValue
+	}
+	return value
+}
+
 func TryBool(key string, defaultValue bool) bool {
 	value := archaius.Exist(key)
 	if !value {
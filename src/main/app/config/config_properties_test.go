@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/src/main/app/config"
+	"github.com/src/main/app/helpers/files"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,6 +19,12 @@ func TestAppConfig(t *testing.T) {
 	stringValue = config.String("missing")
 	assert.Equal(t, "", stringValue)
 
+	stringValue = config.TryString("missing", "fallback")
+	assert.Equal(t, "fallback", stringValue)
+
+	stringValue = config.TryString("key", "fallback")
+	assert.Equal(t, "value", stringValue)
+
 	boolValue := config.TryBool("enable", true)
 	assert.True(t, boolValue)
 
@@ -30,3 +37,7 @@ func TestAppConfig(t *testing.T) {
 	intValue = config.TryInt("threads", 1)
 	assert.Equal(t, 5, intValue)
 }
+
+func TestResource(t *testing.T) {
+	assert.True(t, files.Exist(config.Resource("config/config.yml")))
+}
@@ -0,0 +1,96 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+)
+
+// Batch pushes the messages in batches instead of one request by message. A batch is pushed when it has Size
+// messages, or Window after its first message, the window must be shorter than the visibility timeout.
+type Batch struct {
+	Pusher pusher.BatchPusher
+	Size   int
+	Window time.Duration
+}
+
+func NewBatch(batchPusher pusher.BatchPusher, size int, window time.Duration) (*Batch, error) {
+	if batchPusher == nil {
+		return nil, errors.New("batch: pusher is required")
+	}
+
+	if size <= 0 {
+		return nil, errors.New("batch: size must be positive")
+	}
+
+	if window <= 0 {
+		return nil, errors.New("batch: window must be positive")
+	}
+
+	return &Batch{
+		Pusher: batchPusher,
+		Size:   size,
+		Window: window,
+	}, nil
+}
+
+// enqueue adds the accepted messages to the next batch, the workers wait while a batch is pushed.
+func (c Consumer) enqueue(ctx context.Context, message *queue.MessageDTO) {
+	if !c.accept(ctx, message) {
+		return
+	}
+
+	select {
+	case c.batches <- message:
+	case <-ctx.Done():
+	}
+}
+
+// batcher pushes the batches until the consumer stops, the messages of an incomplete batch are redelivered after
+// their visibility timeout.
+func (c Consumer) batcher(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	window := time.NewTimer(c.batch.Window)
+	window.Stop()
+	defer window.Stop()
+
+	messages := make([]*queue.MessageDTO, 0, c.batch.Size)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-c.batches:
+			messages = append(messages, message)
+			if len(messages) == 1 {
+				window.Reset(c.batch.Window)
+			}
+			if len(messages) < c.batch.Size {
+				continue
+			}
+			// a full batch drains the tick of its window, if any, for the next batch
+			if !window.Stop() {
+				select {
+				case <-window.C:
+				default:
+				}
+			}
+		case <-window.C:
+		}
+
+		c.sendBatch(ctx, messages)
+		messages = make([]*queue.MessageDTO, 0, c.batch.Size)
+	}
+}
+
+// sendBatch deletes the succeeded messages, the failed ones follow the outcome of their error.
+func (c Consumer) sendBatch(ctx context.Context, messages []*queue.MessageDTO) {
+	errs := c.batch.Pusher.SendBatch(messages)
+	for i, message := range messages {
+		c.complete(ctx, message, errs[i])
+	}
+}
@@ -2,10 +2,13 @@ package consumer
 
 import (
 	"context"
+	"errors"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/src/main/app/client"
 	"github.com/src/main/app/helpers/arrays"
 	"github.com/src/main/app/infrastructure/queue"
 	"github.com/src/main/app/log"
@@ -17,7 +20,13 @@ import (
 
 type Consumer struct {
 	queueService     queue.Service
+	deadLetterQueue  queue.Service
+	retryPolicy      *RetryPolicy
+	filter           *Filter
+	validator        *Validator
 	pusher           pusher.Pusher
+	batch            *Batch
+	batches          chan *queue.MessageDTO
 	workers          int
 	taskResolverType TaskResolverType
 	taskResolver     *TaskResolver[queue.MessageDTO]
@@ -29,12 +38,33 @@ type Config struct {
 	Pusher           pusher.Pusher
 	Workers          int
 	TaskResolverType TaskResolverType
+	// DeadLetterQueue receives the messages rejected by the target, without it they are dropped.
+	DeadLetterQueue queue.Service
+	// RetryPolicy re-publishes the failed messages with a delay, without it the backend redelivers them.
+	RetryPolicy *RetryPolicy
+	// Filter acks the messages not matched without a push, without it every message is pushed.
+	Filter *Filter
+	// Validator moves the messages invalid for their json schema to the dead letter queue before the push.
+	Validator *Validator
+	// Batch pushes the messages in batches with its pusher instead of Pusher.
+	Batch *Batch
 }
 
 func NewConsumer(config Config, consumerService services.IConsumerService) Consumer {
+	var batches chan *queue.MessageDTO
+	if config.Batch != nil {
+		batches = make(chan *queue.MessageDTO, config.Batch.Size)
+	}
+
 	return Consumer{
 		queueService:     config.QueueService,
+		deadLetterQueue:  config.DeadLetterQueue,
+		retryPolicy:      config.RetryPolicy,
+		filter:           config.Filter,
+		validator:        config.Validator,
 		pusher:           config.Pusher,
+		batch:            config.Batch,
+		batches:          batches,
 		workers:          config.Workers,
 		taskResolverType: config.TaskResolverType,
 		taskResolver:     ProvideTaskResolver(),
@@ -52,6 +82,11 @@ func (c Consumer) Start(ctx context.Context) {
 
 	go c.collectMetrics(ctx, wg, c.workers)
 
+	if c.batch != nil {
+		wg.Add(1)
+		go c.batcher(ctx, wg)
+	}
+
 	wg.Wait()
 }
 
@@ -82,6 +117,11 @@ func (c Consumer) collectMetrics(ctx context.Context, wg *sync.WaitGroup, curren
 func (c Consumer) worker(ctx context.Context, wg *sync.WaitGroup, workerID int) {
 	defer wg.Done()
 
+	handle := c.sendAndDelete
+	if c.batch != nil {
+		handle = c.enqueue
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -109,19 +149,172 @@ func (c Consumer) worker(ctx context.Context, wg *sync.WaitGroup, workerID int)
 				time.Sleep(time.Millisecond * 1000)
 				continue
 			}
-			resolver.Process(ctx, messages, c.sendAndDelete)
+			resolver.Process(ctx, messages, handle)
 		}
 	}
 }
 
 func (c Consumer) sendAndDelete(ctx context.Context, message *queue.MessageDTO) {
+	if !c.accept(ctx, message) {
+		return
+	}
+
+	c.complete(ctx, message, c.pusher.SendMessage(message))
+}
+
+// accept applies the filter and the validation, the messages not accepted are already deleted or rejected.
+func (c Consumer) accept(ctx context.Context, message *queue.MessageDTO) bool {
+	if c.filter != nil && !c.filter.Matches(message) {
+		log.Debugf("message %s filtered\n", message.MessageID)
+		metrics.Collector.IncrementCounter(metrics.Filtered)
+		c.delete(ctx, message)
+		return false
+	}
+
+	if c.validator != nil {
+		var validationErr *ValidationError
+		if err := c.validator.Validate(message); errors.As(err, &validationErr) {
+			log.Warnf("validation error: %s, msg: %s\n", err.Error(), message.Body)
+			metrics.Collector.IncrementCounter(metrics.ValidationFailed)
+			c.reject(ctx, message, validationErr.Attributes())
+			return false
+		}
+	}
+
+	return true
+}
+
+// Ingest runs a message delivered to the app, i.e. a sns http notification, through the filter, the validation and
+// the pusher of the consumer. There is no queue message to delete or retry: a filtered message returns nil, an invalid
+// or rejected one goes to the dead letter queue and any other failure is returned so the sender redelivers it.
+func (c Consumer) Ingest(ctx context.Context, message *queue.MessageDTO) error {
+	if c.filter != nil && !c.filter.Matches(message) {
+		log.Debugf("message %s filtered\n", message.MessageID)
+		metrics.Collector.IncrementCounter(metrics.Filtered)
+		return nil
+	}
+
+	if c.validator != nil {
+		var validationErr *ValidationError
+		if err := c.validator.Validate(message); errors.As(err, &validationErr) {
+			log.Warnf("validation error: %s, msg: %s\n", err.Error(), message.Body)
+			metrics.Collector.IncrementCounter(metrics.ValidationFailed)
+			return c.deadLetter(ctx, message, validationErr.Attributes())
+		}
+	}
+
 	err := c.pusher.SendMessage(message)
-	if err != nil {
+	var rejectErr *client.RejectError
+	if errors.As(err, &rejectErr) {
+		log.Warnf("pusher reject: %s, msg: %s\n", err.Error(), message.Body)
+		return c.deadLetter(ctx, message, nil)
+	}
+
+	return err
+}
+
+// complete honors the target response contract: an ack deletes the message, a reject moves it to the
+// dead letter queue and a retry after delays its redelivery. Any other failure keeps the backend default,
+// or the retry policy when it is configured.
+func (c Consumer) complete(ctx context.Context, message *queue.MessageDTO, err error) {
+	var retryErr *client.RetryError
+	var rejectErr *client.RejectError
+	switch {
+	case err == nil:
+		c.delete(ctx, message)
+	case errors.As(err, &rejectErr):
+		log.Warnf("pusher reject: %s, msg: %s\n", err.Error(), message.Body)
+		c.reject(ctx, message, nil)
+	case errors.As(err, &retryErr):
+		log.Warnf("pusher retry: %s, msg: %s\n", err.Error(), message.Body)
+		c.retry(ctx, message, retryErr.Delay)
+	default:
 		log.Errorf("pusher error: %s, msg: %s\n", err.Error(), message.Body)
-	} else {
-		err = c.queueService.Delete(ctx, message.ReceiptHandle)
-		if err != nil {
-			log.Errorf("delete error: %s, msg: %s\n", err.Error(), message.Body)
+		c.retry(ctx, message, 0)
+	}
+}
+
+func (c Consumer) delete(ctx context.Context, message *queue.MessageDTO) {
+	if err := c.queueService.Delete(ctx, message.ReceiptHandle); err != nil {
+		log.Errorf("delete error: %s, msg: %s\n", err.Error(), message.Body)
+	}
+}
+
+func (c Consumer) nack(ctx context.Context, message *queue.MessageDTO, delay time.Duration) {
+	if nacker, ok := c.queueService.(queue.Nacker); ok {
+		if err := nacker.Nack(ctx, message.ReceiptHandle, delay); err != nil {
+			log.Errorf("nack error: %s, msg: %s\n", err.Error(), message.Body)
 		}
 	}
 }
+
+// retry re-publishes the message with the next delay of the retry policy and deletes the original, a delay
+// from the target is honored when it is longer. An exhausted message goes to the dead letter queue.
+func (c Consumer) retry(ctx context.Context, message *queue.MessageDTO, delay time.Duration) {
+	if c.retryPolicy == nil {
+		c.nack(ctx, message, delay)
+		return
+	}
+
+	attempt := Attempt(message) + 1
+	if attempt > c.retryPolicy.MaxAttempts {
+		log.Warnf("message %s exhausted %d retries\n", message.MessageID, c.retryPolicy.MaxAttempts)
+		metrics.Collector.IncrementCounter(metrics.RetryExhausted)
+		c.reject(ctx, message, nil)
+		return
+	}
+
+	attributes := make(map[string]string, len(message.Attributes)+2)
+	for name, value := range message.Attributes {
+		attributes[name] = value
+	}
+	attributes[RetryAttemptAttribute] = strconv.Itoa(attempt)
+	attributes[queue.OriginalMessageIDAttribute] = message.OriginalID()
+
+	if _, err := c.queueService.Send(ctx, queue.SendMessageDTO{
+		Body:       message.Body,
+		Attributes: attributes,
+		Delay:      min(max(c.retryPolicy.Delay(attempt), delay), MaxRetryDelay),
+	}); err != nil {
+		log.Errorf("retry error: %s, msg: %s\n", err.Error(), message.Body)
+		c.nack(ctx, message, delay)
+		return
+	}
+
+	metrics.Collector.IncrementCounter(metrics.RetryRepublished)
+	c.delete(ctx, message)
+}
+
+// reject sends the message to the dead letter queue before deleting it, a send error keeps it for a retry.
+func (c Consumer) reject(ctx context.Context, message *queue.MessageDTO, report map[string]string) {
+	if err := c.deadLetter(ctx, message, report); err != nil {
+		log.Errorf("dead letter queue error: %s, msg: %s\n", err.Error(), message.Body)
+		c.nack(ctx, message, 0)
+		return
+	}
+
+	c.delete(ctx, message)
+}
+
+// deadLetter sends the message to the dead letter queue, without it the message is dropped. The report attributes
+// are added to the ones of the message.
+func (c Consumer) deadLetter(ctx context.Context, message *queue.MessageDTO, report map[string]string) error {
+	if c.deadLetterQueue == nil {
+		log.Warnf("rejected message %s dropped, no dead letter queue\n", message.MessageID)
+		return nil
+	}
+
+	attributes := message.Attributes
+	if len(report) > 0 {
+		attributes = make(map[string]string, len(message.Attributes)+len(report))
+		for name, value := range message.Attributes {
+			attributes[name] = value
+		}
+		for name, value := range report {
+			attributes[name] = value
+		}
+	}
+
+	_, err := c.deadLetterQueue.Send(ctx, queue.SendMessageDTO{Body: message.Body, Attributes: attributes})
+	return err
+}
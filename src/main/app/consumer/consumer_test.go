@@ -4,15 +4,19 @@ import (
 	"container/list"
 	"context"
 	"errors"
+	"net/http"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/src/main/app/client"
 	"github.com/src/main/app/consumer"
 	"github.com/src/main/app/container"
 	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/server"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/ugurcsen/gods-generic/maps/hashmap"
@@ -27,6 +31,65 @@ func (m *MockPusher) SendMessage(*queue.MessageDTO) error {
 	return args.Error(0)
 }
 
+type MockNackQueueService struct {
+	mock.Mock
+}
+
+func (m *MockNackQueueService) Receive(context.Context) ([]queue.MessageDTO, error) {
+	args := m.Called()
+	return args.Get(0).([]queue.MessageDTO), args.Error(1)
+}
+
+func (m *MockNackQueueService) Send(context.Context, queue.SendMessageDTO) (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockNackQueueService) SendBatch(context.Context, []queue.SendMessageDTO) ([]queue.SendResultDTO, error) {
+	args := m.Called()
+	return args.Get(0).([]queue.SendResultDTO), args.Error(1)
+}
+
+func (m *MockNackQueueService) Delete(context.Context, string) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockNackQueueService) Count(context.Context) (*int, error) {
+	args := m.Called()
+	return args.Get(0).(*int), args.Error(1)
+}
+
+func (m *MockNackQueueService) Nack(_ context.Context, receiptHandle string, delay time.Duration) error {
+	args := m.Called(receiptHandle, delay)
+	return args.Error(0)
+}
+
+func TestNewConsumerNack(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(errors.New("internal server error"))
+
+	queueClient := new(MockNackQueueService)
+	queueClient.On("Receive").Return([]queue.MessageDTO{{Body: "msg", ReceiptHandle: "rpt1"}}, nil)
+	queueClient.On("Count").Return(aws.Int(1), nil)
+	queueClient.On("Nack", "rpt1", time.Duration(0)).Return(nil)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			Pusher:           httpPusher,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+		}, container.ProvideConsumerService()).
+		Start(ctx)
+
+	queueClient.AssertCalled(t, "Nack", "rpt1", time.Duration(0))
+	queueClient.AssertNotCalled(t, "Delete")
+}
+
 func TestNewConsumerAsync(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
 	defer cancel()
@@ -303,3 +366,436 @@ func TestNewConsumerStopped(t *testing.T) {
 	assert.NotNil(t, receiveMessageOutput.Messages[0])
 	assert.Equal(t, "msg", aws.ToString(receiveMessageOutput.Messages[0].Body))
 }
+
+func TestNewConsumerRetryAfter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	consumerService := container.ProvideConsumerService()
+	assert.NoError(t, consumerService.Start())
+
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(&client.RetryError{
+		Delay: time.Minute,
+		Err:   server.NewError(http.StatusTooManyRequests, "too many requests"),
+	})
+
+	queueClient := new(MockNackQueueService)
+	queueClient.On("Receive").Return([]queue.MessageDTO{{Body: "msg", ReceiptHandle: "rpt1"}}, nil)
+	queueClient.On("Count").Return(aws.Int(1), nil)
+	queueClient.On("Nack", "rpt1", time.Minute).Return(nil)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			Pusher:           httpPusher,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+		}, consumerService).
+		Start(ctx)
+
+	queueClient.AssertCalled(t, "Nack", "rpt1", time.Minute)
+	queueClient.AssertNotCalled(t, "Delete")
+}
+
+func TestNewConsumerReject(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	consumerService := container.ProvideConsumerService()
+	assert.NoError(t, consumerService.Start())
+
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(&client.RejectError{
+		Err: server.NewError(http.StatusUnprocessableEntity, "invalid order"),
+	})
+
+	broker := queue.NewMemoryBroker()
+	queueClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders", Parallel: 10, Timeout: 50, VisibilityTimeout: 30000,
+	}, broker)
+	assert.NoError(t, err)
+	deadLetterQueue, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders-dlq", Parallel: 10, Timeout: 50,
+	}, broker)
+	assert.NoError(t, err)
+
+	_, err = queueClient.Send(ctx, queue.SendMessageDTO{Body: "msg", Attributes: map[string]string{"event": "created"}})
+	assert.NoError(t, err)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			DeadLetterQueue:  deadLetterQueue,
+			Pusher:           httpPusher,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+		}, consumerService).
+		Start(ctx)
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, aws.ToInt(count))
+
+	messages, err := deadLetterQueue.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "msg", messages[0].Body)
+	assert.Equal(t, map[string]string{"event": "created"}, messages[0].Attributes)
+}
+
+func TestNewConsumerValidator(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	consumerService := container.ProvideConsumerService()
+	assert.NoError(t, consumerService.Start())
+
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(nil)
+
+	broker := queue.NewMemoryBroker()
+	queueClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders", Parallel: 10, Timeout: 50, VisibilityTimeout: 30000,
+	}, broker)
+	assert.NoError(t, err)
+	deadLetterQueue, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders-dlq", Parallel: 10, Timeout: 50,
+	}, broker)
+	assert.NoError(t, err)
+
+	_, err = queueClient.Send(ctx, queue.SendMessageDTO{Body: `{"order_id": 1}`, Attributes: map[string]string{"event": "order.created"}})
+	assert.NoError(t, err)
+	_, err = queueClient.Send(ctx, queue.SendMessageDTO{Body: `{"order_id": "1"}`, Attributes: map[string]string{"event": "order.created"}})
+	assert.NoError(t, err)
+
+	validator, err := consumer.NewValidator(consumer.ValidatorConfig{
+		TypeAttribute: "event",
+		Schemas:       map[string]string{"order.created": "schemas/order_created.json"},
+	})
+	assert.NoError(t, err)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			DeadLetterQueue:  deadLetterQueue,
+			Pusher:           httpPusher,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+			Validator:        validator,
+		}, consumerService).
+		Start(ctx)
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, aws.ToInt(count))
+	httpPusher.AssertNumberOfCalls(t, "SendMessage", 1)
+
+	messages, err := deadLetterQueue.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, `{"order_id": "1"}`, messages[0].Body)
+	assert.Equal(t, "order.created", messages[0].Attributes["event"])
+	assert.Contains(t, messages[0].Attributes[consumer.ValidationErrorAttribute], "/order_id")
+	assert.NotEmpty(t, messages[0].Attributes[consumer.ValidationReportAttribute])
+	assert.Contains(t, messages[0].Attributes[consumer.ValidationSchemaAttribute], "order_created.json")
+}
+
+func TestNewConsumerRejectWithoutDeadLetterQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	consumerService := container.ProvideConsumerService()
+	assert.NoError(t, consumerService.Start())
+
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(&client.RejectError{
+		Err: server.NewError(http.StatusUnprocessableEntity, "invalid order"),
+	})
+
+	queueClient := new(MockNackQueueService)
+	queueClient.On("Receive").Return([]queue.MessageDTO{{Body: "msg", ReceiptHandle: "rpt1"}}, nil)
+	queueClient.On("Count").Return(aws.Int(1), nil)
+	queueClient.On("Delete").Return(nil)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			Pusher:           httpPusher,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+		}, consumerService).
+		Start(ctx)
+
+	queueClient.AssertCalled(t, "Delete")
+	queueClient.AssertNotCalled(t, "Nack", mock.Anything, mock.Anything)
+}
+
+func TestNewConsumerRetryPolicy(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	consumerService := container.ProvideConsumerService()
+	assert.NoError(t, consumerService.Start())
+
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(errors.New("internal server error"))
+
+	broker := queue.NewMemoryBroker()
+	queueClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders", Parallel: 10, Timeout: 50, VisibilityTimeout: 30000,
+	}, broker)
+	assert.NoError(t, err)
+
+	_, err = queueClient.Send(ctx, queue.SendMessageDTO{Body: "msg", Attributes: map[string]string{"event": "created"}})
+	assert.NoError(t, err)
+
+	retryPolicy, err := consumer.NewRetryPolicy([]time.Duration{time.Minute}, 3)
+	assert.NoError(t, err)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			Pusher:           httpPusher,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+			RetryPolicy:      retryPolicy,
+		}, consumerService).
+		Start(ctx)
+
+	memoryQueue, found := broker.Get("orders")
+	assert.True(t, found)
+	assert.Equal(t, queue.MemoryQueueStats{Delayed: 1}, memoryQueue.Stats())
+	httpPusher.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestNewConsumerRetryPolicyExhausted(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	consumerService := container.ProvideConsumerService()
+	assert.NoError(t, consumerService.Start())
+
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(errors.New("internal server error"))
+
+	broker := queue.NewMemoryBroker()
+	queueClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders", Parallel: 10, Timeout: 50, VisibilityTimeout: 30000,
+	}, broker)
+	assert.NoError(t, err)
+	deadLetterQueue, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders-dlq", Parallel: 10, Timeout: 50,
+	}, broker)
+	assert.NoError(t, err)
+
+	messageID, err := queueClient.Send(ctx, queue.SendMessageDTO{Body: "msg", Attributes: map[string]string{"event": "created"}})
+	assert.NoError(t, err)
+
+	retryPolicy, err := consumer.NewRetryPolicy([]time.Duration{0}, 2)
+	assert.NoError(t, err)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			DeadLetterQueue:  deadLetterQueue,
+			Pusher:           httpPusher,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+			RetryPolicy:      retryPolicy,
+		}, consumerService).
+		Start(ctx)
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, aws.ToInt(count))
+	httpPusher.AssertNumberOfCalls(t, "SendMessage", 3)
+
+	messages, err := deadLetterQueue.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "msg", messages[0].Body)
+	assert.Equal(t, map[string]string{
+		"event":                          "created",
+		consumer.RetryAttemptAttribute:   "2",
+		queue.OriginalMessageIDAttribute: messageID,
+	}, messages[0].Attributes)
+}
+
+func TestNewConsumerFilter(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	consumerService := container.ProvideConsumerService()
+	assert.NoError(t, consumerService.Start())
+
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(nil)
+
+	queueClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders", Parallel: 10, Timeout: 50, VisibilityTimeout: 30000,
+	}, queue.NewMemoryBroker())
+	assert.NoError(t, err)
+
+	_, err = queueClient.Send(ctx, queue.SendMessageDTO{Body: `{"type": "order.created"}`})
+	assert.NoError(t, err)
+	_, err = queueClient.Send(ctx, queue.SendMessageDTO{Body: `{"type": "order.viewed"}`})
+	assert.NoError(t, err)
+
+	filter, err := consumer.NewFilter(`body.type == 'order.created'`)
+	assert.NoError(t, err)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			Pusher:           httpPusher,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+			Filter:           filter,
+		}, consumerService).
+		Start(ctx)
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, aws.ToInt(count))
+	httpPusher.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestNewConsumerIngest(t *testing.T) {
+	httpPusher := new(MockPusher)
+	httpPusher.On("SendMessage").Return(nil).Once()
+	httpPusher.On("SendMessage").Return(&client.RejectError{
+		Err: server.NewError(http.StatusUnprocessableEntity, "invalid order"),
+	}).Once()
+	httpPusher.On("SendMessage").Return(errors.New("internal server error")).Once()
+
+	deadLetterQueue, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders-dlq", Parallel: 10, Timeout: 50,
+	}, queue.NewMemoryBroker())
+	assert.NoError(t, err)
+
+	filter, err := consumer.NewFilter(`body.type != 'order.viewed'`)
+	assert.NoError(t, err)
+
+	validator, err := consumer.NewValidator(consumer.ValidatorConfig{
+		TypeField: "type",
+		Schemas:   map[string]string{"order.created": "schemas/order_created.json"},
+	})
+	assert.NoError(t, err)
+
+	orderConsumer := consumer.NewConsumer(consumer.Config{
+		DeadLetterQueue: deadLetterQueue,
+		Pusher:          httpPusher,
+		Filter:          filter,
+		Validator:       validator,
+	}, container.ProvideConsumerService())
+
+	ctx := context.Background()
+	assert.NoError(t, orderConsumer.Ingest(ctx, &queue.MessageDTO{Body: `{"type": "order.viewed"}`}))
+	assert.NoError(t, orderConsumer.Ingest(ctx, &queue.MessageDTO{Body: `{"type": "order.created", "order_id": "1"}`}))
+	httpPusher.AssertNotCalled(t, "SendMessage")
+
+	assert.NoError(t, orderConsumer.Ingest(ctx, &queue.MessageDTO{Body: `{"type": "order.created", "order_id": 1}`}))
+	assert.NoError(t, orderConsumer.Ingest(ctx, &queue.MessageDTO{Body: `{"type": "order.created", "order_id": 2}`}))
+	assert.Error(t, orderConsumer.Ingest(ctx, &queue.MessageDTO{Body: `{"type": "order.created", "order_id": 3}`}))
+	httpPusher.AssertNumberOfCalls(t, "SendMessage", 3)
+
+	messages, err := deadLetterQueue.Receive(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, `{"type": "order.created", "order_id": "1"}`, messages[0].Body)
+	assert.Contains(t, messages[0].Attributes[consumer.ValidationErrorAttribute], "/order_id")
+	assert.Equal(t, `{"type": "order.created", "order_id": 2}`, messages[1].Body)
+}
+
+type MockBatchPusher struct {
+	mu      sync.Mutex
+	batches [][]string
+}
+
+// SendBatch acks the ok messages, rejects the invalid ones and fails the others.
+func (m *MockBatchPusher) SendBatch(messages []*queue.MessageDTO) []error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var bodies []string
+	errs := make([]error, len(messages))
+	for i, message := range messages {
+		bodies = append(bodies, message.Body)
+		switch message.Body {
+		case "ok":
+		case "invalid":
+			errs[i] = &client.RejectError{Err: server.NewError(http.StatusUnprocessableEntity, "invalid order")}
+		default:
+			errs[i] = errors.New("internal server error")
+		}
+	}
+	m.batches = append(m.batches, bodies)
+
+	return errs
+}
+
+func TestNewConsumerBatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(500))
+	defer cancel()
+
+	consumerService := container.ProvideConsumerService()
+	assert.NoError(t, consumerService.Start())
+
+	broker := queue.NewMemoryBroker()
+	queueClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders", Parallel: 10, Timeout: 50, VisibilityTimeout: 30000,
+	}, broker)
+	assert.NoError(t, err)
+	deadLetterQueue, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name: "orders-dlq", Parallel: 10, Timeout: 50,
+	}, broker)
+	assert.NoError(t, err)
+
+	for _, body := range []string{"ok", "invalid", "failed", "ok", "ok"} {
+		_, err = queueClient.Send(ctx, queue.SendMessageDTO{Body: body})
+		assert.NoError(t, err)
+	}
+
+	batchPusher := new(MockBatchPusher)
+	batch, err := consumer.NewBatch(batchPusher, 2, time.Millisecond*50)
+	assert.NoError(t, err)
+
+	consumer.NewConsumer(
+		consumer.Config{
+			QueueService:     queueClient,
+			DeadLetterQueue:  deadLetterQueue,
+			Batch:            batch,
+			Workers:          1,
+			TaskResolverType: consumer.Sync,
+		}, consumerService).
+		Start(ctx)
+
+	var pushed []string
+	for _, bodies := range batchPusher.batches {
+		assert.LessOrEqual(t, len(bodies), 2)
+		pushed = append(pushed, bodies...)
+	}
+	assert.ElementsMatch(t, []string{"ok", "invalid", "failed", "ok", "ok"}, pushed)
+
+	// the failed message waits its visibility timeout
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, aws.ToInt(count))
+
+	messages, err := deadLetterQueue.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "invalid", messages[0].Body)
+}
+
+func TestNewBatchErr(t *testing.T) {
+	_, err := consumer.NewBatch(nil, 10, time.Second)
+	assert.Error(t, err)
+
+	_, err = consumer.NewBatch(new(MockBatchPusher), 0, time.Second)
+	assert.Error(t, err)
+
+	_, err = consumer.NewBatch(new(MockBatchPusher), 10, 0)
+	assert.Error(t, err)
+}
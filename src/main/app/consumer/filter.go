@@ -0,0 +1,107 @@
+package consumer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+)
+
+// Filter is a cel boolean expression evaluated before the push, the messages not matched are acked.
+// * envelope: sns notification fields (MessageId, Subject, TopicArn, Type, Timestamp), empty for raw payloads,
+// * attributes: message attributes,
+// * body: the json of the sns Message or of the raw payload, null when it is not json,
+// * raw: the sns Message or the raw payload as string.
+type Filter struct {
+	expression string
+	program    cel.Program
+}
+
+type filterEnvelope struct {
+	MessageID string `json:"MessageId"`
+	Subject   string `json:"Subject"`
+	TopicArn  string `json:"TopicArn"`
+	Type      string `json:"Type"`
+	Timestamp string `json:"Timestamp"`
+	Message   string `json:"Message"`
+}
+
+func NewFilter(expression string) (*Filter, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("envelope", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("attributes", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("body", cel.DynType),
+		cel.Variable("raw", cel.StringType),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("filter: %w", err)
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("filter %q: %w", expression, issues.Err())
+	}
+
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("filter %q: must be a boolean expression, given %s", expression, ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("filter %q: %w", expression, err)
+	}
+
+	return &Filter{
+		expression: expression,
+		program:    program,
+	}, nil
+}
+
+// Matches evaluates the filter, an evaluation error matches so the message is pushed instead of lost.
+// Use has() to check optional fields, i.e. has(body.type) && body.type == 'order.created'.
+func (f Filter) Matches(message *queue.MessageDTO) bool {
+	out, _, err := f.program.Eval(filterVariables(message))
+	if err != nil {
+		log.Warnf("filter %q error, message %s is pushed: %s", f.expression, message.MessageID, err)
+		return true
+	}
+
+	matches, ok := out.Value().(bool)
+	return !ok || matches
+}
+
+func filterVariables(message *queue.MessageDTO) map[string]any {
+	envelope := map[string]string{}
+	raw := message.Body
+
+	var snsEnvelope filterEnvelope
+	if err := json.Unmarshal([]byte(message.Body), &snsEnvelope); err == nil && snsEnvelope.Message != "" {
+		envelope = map[string]string{
+			"MessageId": snsEnvelope.MessageID,
+			"Subject":   snsEnvelope.Subject,
+			"TopicArn":  snsEnvelope.TopicArn,
+			"Type":      snsEnvelope.Type,
+			"Timestamp": snsEnvelope.Timestamp,
+		}
+		raw = snsEnvelope.Message
+	}
+
+	var body any
+	if err := json.Unmarshal([]byte(raw), &body); err != nil {
+		body = nil
+	}
+
+	attributes := message.Attributes
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+
+	return map[string]any{
+		"envelope":   envelope,
+		"attributes": attributes,
+		"body":       body,
+		"raw":        raw,
+	}
+}
@@ -0,0 +1,46 @@
+package consumer_test
+
+import (
+	"testing"
+
+	"github.com/src/main/app/consumer"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+const snsNotification = `{"Type":"Notification","MessageId":"1","Subject":"Order","TopicArn":"arn:aws:sns:us-east-1:1:orders",` +
+	`"Message":"{\"type\":\"order.created\",\"order\":{\"total\":150}}"}`
+
+func TestNewFilterErr(t *testing.T) {
+	_, err := consumer.NewFilter("body.type ==")
+	assert.Error(t, err)
+
+	_, err = consumer.NewFilter("raw")
+	assert.Error(t, err)
+}
+
+func TestFilter_Matches(t *testing.T) {
+	tests := []struct {
+		expression string
+		message    queue.MessageDTO
+		expected   bool
+	}{
+		{`envelope.Subject == 'Order'`, queue.MessageDTO{Body: snsNotification}, true},
+		{`body.type == 'order.created' && body.order.total > 100.0`, queue.MessageDTO{Body: snsNotification}, true},
+		{`body.type == 'order.cancelled'`, queue.MessageDTO{Body: snsNotification}, false},
+		{`attributes['event'] == 'created'`, queue.MessageDTO{Body: "{}", Attributes: map[string]string{"event": "created"}}, true},
+		{`'event' in attributes`, queue.MessageDTO{Body: "{}"}, false},
+		{`raw.startsWith('ping')`, queue.MessageDTO{Body: "ping"}, true},
+		{`has(body.type) && body.type == 'order.created'`, queue.MessageDTO{Body: `{"id": 1}`}, false},
+		// evaluation errors are pushed
+		{`body.type == 'order.created'`, queue.MessageDTO{Body: `{"id": 1}`}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			filter, err := consumer.NewFilter(tt.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, filter.Matches(&tt.message))
+		})
+	}
+}
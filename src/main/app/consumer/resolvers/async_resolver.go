@@ -5,7 +5,7 @@ import (
 	"sync"
 )
 
-type AsyncResolver[T comparable] struct {
+type AsyncResolver[T any] struct {
 }
 
 func (resolver AsyncResolver[T]) Process(ctx context.Context, elements []T, f func(ctx context.Context, element *T)) {
@@ -2,7 +2,7 @@ package resolvers
 
 import "context"
 
-type SyncResolver[T comparable] struct {
+type SyncResolver[T any] struct {
 }
 
 func (r SyncResolver[T]) Process(ctx context.Context, elements []T, f func(ctx context.Context, element *T)) {
@@ -0,0 +1,60 @@
+package consumer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/src/main/app/infrastructure/queue"
+)
+
+// RetryAttemptAttribute is the message attribute with the number of re-published retries.
+const RetryAttemptAttribute = "retry-attempt"
+
+// MaxRetryDelay is the sqs max delay seconds.
+const MaxRetryDelay = time.Minute * 15
+
+// RetryPolicy re-publishes the failed messages with an increasing delay, instead of waiting the visibility timeout.
+type RetryPolicy struct {
+	// Delays is the schedule by attempt, the last delay is repeated up to MaxAttempts.
+	Delays []time.Duration
+	// MaxAttempts is the number of retries before the message goes to the dead letter queue.
+	MaxAttempts int
+}
+
+func NewRetryPolicy(delays []time.Duration, maxAttempts int) (*RetryPolicy, error) {
+	if len(delays) == 0 {
+		return nil, errors.New("retry policy: delays are required")
+	}
+
+	for _, delay := range delays {
+		if delay < 0 || delay > MaxRetryDelay {
+			return nil, fmt.Errorf("retry policy: delay %s out of range 0 to %s", delay, MaxRetryDelay)
+		}
+	}
+
+	if maxAttempts <= 0 {
+		maxAttempts = len(delays)
+	}
+
+	return &RetryPolicy{
+		Delays:      delays,
+		MaxAttempts: maxAttempts,
+	}, nil
+}
+
+// Delay returns the delay of the attempt, starting at 1.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	return p.Delays[min(max(attempt, 1), len(p.Delays))-1]
+}
+
+// Attempt reads the retries of the message, a message without the attribute was not retried.
+func Attempt(message *queue.MessageDTO) int {
+	attempt, err := strconv.Atoi(message.Attributes[RetryAttemptAttribute])
+	if err != nil || attempt < 0 {
+		return 0
+	}
+
+	return attempt
+}
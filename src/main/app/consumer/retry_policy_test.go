@@ -0,0 +1,34 @@
+package consumer_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/src/main/app/consumer"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	policy, err := consumer.NewRetryPolicy([]time.Duration{time.Second, time.Minute}, 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, policy.MaxAttempts)
+	assert.Equal(t, time.Second, policy.Delay(1))
+	assert.Equal(t, time.Minute, policy.Delay(2))
+	assert.Equal(t, time.Minute, policy.Delay(5))
+}
+
+func TestNewRetryPolicyErr(t *testing.T) {
+	_, err := consumer.NewRetryPolicy(nil, 3)
+	assert.Error(t, err)
+
+	_, err = consumer.NewRetryPolicy([]time.Duration{time.Hour}, 3)
+	assert.Error(t, err)
+}
+
+func TestAttempt(t *testing.T) {
+	assert.Equal(t, 0, consumer.Attempt(&queue.MessageDTO{}))
+	assert.Equal(t, 0, consumer.Attempt(&queue.MessageDTO{Attributes: map[string]string{consumer.RetryAttemptAttribute: "x"}}))
+	assert.Equal(t, 2, consumer.Attempt(&queue.MessageDTO{Attributes: map[string]string{consumer.RetryAttemptAttribute: "2"}}))
+}
@@ -17,7 +17,7 @@ const (
 	Async TaskResolverType = "async"
 )
 
-type TaskResolver[T comparable] struct {
+type TaskResolver[T any] struct {
 	handlers *hashmap.Map[TaskResolverType, ElementHandler[T]]
 }
 
@@ -29,7 +29,7 @@ func (r *TaskResolver[T]) Resolve(taskResolverType TaskResolverType) (ElementHan
 	return value, nil
 }
 
-type ElementHandler[T comparable] interface {
+type ElementHandler[T any] interface {
 	Process(ctx context.Context, elements []T, f func(ctx context.Context, element *T))
 }
 
@@ -0,0 +1,229 @@
+package consumer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader" // http and https schema urls
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/infrastructure/queue"
+)
+
+// Validation report attributes of the invalid messages sent to the dead letter queue.
+const (
+	ValidationErrorAttribute  = "validation-error"
+	ValidationReportAttribute = "validation-report"
+	ValidationSchemaAttribute = "validation-schema"
+)
+
+// maxValidationReport keeps the report under the sqs message attributes limits.
+const maxValidationReport = 4096
+
+type ValidatorConfig struct {
+	// TypeAttribute is the message attribute with the event type.
+	TypeAttribute string
+	// TypeField is the dot path of the event type in the json of the sns Message or of the raw payload.
+	TypeField string
+	// Schemas by event type, a path in src/resources or an url.
+	Schemas map[string]string
+	// DefaultSchema validates the messages of the types without schema, without it they are not validated.
+	DefaultSchema string
+}
+
+// Validator validates the json of the sns Message, or of the raw payload, with the json schema of its event type.
+type Validator struct {
+	typeAttribute string
+	typeField     string
+	schemas       map[string]*jsonschema.Schema
+	defaultSchema *jsonschema.Schema
+}
+
+// ValidationError is an invalid message, Attributes is the report for the dead letter queue.
+type ValidationError struct {
+	Schema  string
+	Message string
+	Report  string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("schema %s: %s", e.Schema, e.Message)
+}
+
+func (e *ValidationError) Attributes() map[string]string {
+	return map[string]string{
+		ValidationErrorAttribute:  e.Message,
+		ValidationReportAttribute: e.Report,
+		ValidationSchemaAttribute: e.Schema,
+	}
+}
+
+type validationReportDTO struct {
+	InstanceLocation string `json:"instance_location"`
+	KeywordLocation  string `json:"keyword_location"`
+	Error            string `json:"error"`
+}
+
+func NewValidator(validatorConfig ValidatorConfig) (*Validator, error) {
+	if len(validatorConfig.Schemas) == 0 && validatorConfig.DefaultSchema == "" {
+		return nil, errors.New("validator: schemas are required")
+	}
+
+	if len(validatorConfig.Schemas) > 0 && validatorConfig.TypeAttribute == "" && validatorConfig.TypeField == "" {
+		return nil, errors.New("validator: type attribute or type field is required")
+	}
+
+	compiler := jsonschema.NewCompiler()
+	validator := &Validator{
+		typeAttribute: validatorConfig.TypeAttribute,
+		typeField:     validatorConfig.TypeField,
+		schemas:       make(map[string]*jsonschema.Schema, len(validatorConfig.Schemas)),
+	}
+
+	for eventType, location := range validatorConfig.Schemas {
+		schema, err := compiler.Compile(schemaURL(location))
+		if err != nil {
+			return nil, fmt.Errorf("validator: schema of %s: %w", eventType, err)
+		}
+		validator.schemas[eventType] = schema
+	}
+
+	if validatorConfig.DefaultSchema != "" {
+		schema, err := compiler.Compile(schemaURL(validatorConfig.DefaultSchema))
+		if err != nil {
+			return nil, fmt.Errorf("validator: default schema: %w", err)
+		}
+		validator.defaultSchema = schema
+	}
+
+	return validator, nil
+}
+
+// Validate returns a *ValidationError for an invalid message, a message without schema is valid.
+func (v Validator) Validate(message *queue.MessageDTO) error {
+	payload, body := decodePayload(message)
+
+	schema := v.schema(message, body)
+	if schema == nil {
+		return nil
+	}
+
+	if body == nil {
+		return &ValidationError{
+			Schema:  schema.Location,
+			Message: "payload is not json",
+			Report:  truncate(payload, maxValidationReport),
+		}
+	}
+
+	err := schema.Validate(body)
+	if err == nil {
+		return nil
+	}
+
+	var schemaErr *jsonschema.ValidationError
+	if !errors.As(err, &schemaErr) {
+		return &ValidationError{Schema: schema.Location, Message: err.Error()}
+	}
+
+	var report []validationReportDTO
+	for _, basicError := range schemaErr.BasicOutput().Errors {
+		// the root unit only says the document is invalid
+		if basicError.KeywordLocation == "" {
+			continue
+		}
+		report = append(report, validationReportDTO{
+			InstanceLocation: basicError.InstanceLocation,
+			KeywordLocation:  basicError.KeywordLocation,
+			Error:            basicError.Error,
+		})
+	}
+
+	encoded, _ := json.Marshal(report)
+	summary := "invalid payload"
+	if len(report) > 0 {
+		// the deepest unit is the most specific error
+		last := report[len(report)-1]
+		summary = fmt.Sprintf("%s: %s", last.InstanceLocation, last.Error)
+	}
+
+	return &ValidationError{
+		Schema:  schema.Location,
+		Message: summary,
+		Report:  truncate(string(encoded), maxValidationReport),
+	}
+}
+
+func (v Validator) schema(message *queue.MessageDTO, body any) *jsonschema.Schema {
+	var eventType string
+	switch {
+	case v.typeAttribute != "":
+		eventType = message.Attributes[v.typeAttribute]
+	case v.typeField != "":
+		eventType = fieldValue(body, v.typeField)
+	}
+
+	if schema, found := v.schemas[eventType]; found && eventType != "" {
+		return schema
+	}
+
+	return v.defaultSchema
+}
+
+// decodePayload returns the sns Message, or the raw payload, and its json, nil when it is not json.
+func decodePayload(message *queue.MessageDTO) (string, any) {
+	payload := message.Body
+
+	var snsEnvelope filterEnvelope
+	if err := json.Unmarshal([]byte(message.Body), &snsEnvelope); err == nil && snsEnvelope.Message != "" {
+		payload = snsEnvelope.Message
+	}
+
+	var body any
+	if err := json.Unmarshal([]byte(payload), &body); err != nil {
+		return payload, nil
+	}
+
+	return payload, body
+}
+
+func fieldValue(body any, path string) string {
+	value := body
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		value = object[key]
+	}
+
+	text, _ := value.(string)
+	return text
+}
+
+// schemaURL resolves a path in src/resources, urls are kept.
+func schemaURL(location string) string {
+	if parsed, err := url.Parse(location); err == nil && parsed.Scheme != "" && len(parsed.Scheme) > 1 {
+		return location
+	}
+
+	return config.Resource(location)
+}
+
+// truncate cuts the value on a rune boundary, the invalid utf-8 is dropped since sqs rejects it in attribute values.
+func truncate(value string, size int) string {
+	value = strings.ToValidUTF8(value, "")
+	if len(value) <= size {
+		return value
+	}
+
+	for size > 0 && !utf8.RuneStart(value[size]) {
+		size--
+	}
+
+	return value[:size]
+}
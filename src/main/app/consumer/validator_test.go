@@ -0,0 +1,101 @@
+package consumer_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/src/main/app/consumer"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewValidatorErr(t *testing.T) {
+	_, err := consumer.NewValidator(consumer.ValidatorConfig{})
+	assert.Error(t, err)
+
+	_, err = consumer.NewValidator(consumer.ValidatorConfig{
+		Schemas: map[string]string{"order.created": "schemas/order_created.json"},
+	})
+	assert.Error(t, err)
+
+	_, err = consumer.NewValidator(consumer.ValidatorConfig{DefaultSchema: "schemas/missing.json"})
+	assert.Error(t, err)
+}
+
+func TestValidator_Validate(t *testing.T) {
+	validator, err := consumer.NewValidator(consumer.ValidatorConfig{
+		TypeAttribute: "event",
+		Schemas:       map[string]string{"order.created": "schemas/order_created.json"},
+	})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		message queue.MessageDTO
+		valid   bool
+	}{
+		{queue.MessageDTO{Body: `{"order_id": 1}`, Attributes: map[string]string{"event": "order.created"}}, true},
+		{queue.MessageDTO{Body: `{"order_id": 0}`, Attributes: map[string]string{"event": "order.created"}}, false},
+		{queue.MessageDTO{Body: `{"customer_id": 1}`, Attributes: map[string]string{"event": "order.created"}}, false},
+		{queue.MessageDTO{Body: "msg", Attributes: map[string]string{"event": "order.created"}}, false},
+		{queue.MessageDTO{Body: "msg", Attributes: map[string]string{"event": "order.viewed"}}, true},
+		{queue.MessageDTO{Body: "msg"}, true},
+	}
+
+	for _, tt := range tests {
+		err = validator.Validate(&tt.message)
+		assert.Equal(t, tt.valid, err == nil, tt.message.Body)
+	}
+}
+
+func TestValidator_ValidateSNS(t *testing.T) {
+	validator, err := consumer.NewValidator(consumer.ValidatorConfig{
+		TypeField: "type",
+		Schemas:   map[string]string{"order.created": "schemas/order_created.json"},
+	})
+	assert.NoError(t, err)
+
+	err = validator.Validate(&queue.MessageDTO{Body: snsNotification})
+	assert.Error(t, err)
+
+	var validationErr *consumer.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+
+	attributes := validationErr.Attributes()
+	assert.Contains(t, attributes[consumer.ValidationSchemaAttribute], "schemas/order_created.json")
+	assert.Contains(t, attributes[consumer.ValidationErrorAttribute], "order_id")
+
+	var report []map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(attributes[consumer.ValidationReportAttribute]), &report))
+	assert.NotEmpty(t, report)
+}
+
+func TestValidator_ValidateURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"type": "object", "required": ["type"]}`))
+	}))
+	defer server.Close()
+
+	validator, err := consumer.NewValidator(consumer.ValidatorConfig{DefaultSchema: server.URL + "/schemas/order.json"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, validator.Validate(&queue.MessageDTO{Body: snsNotification}))
+	assert.Error(t, validator.Validate(&queue.MessageDTO{Body: `{"order_id": 1}`}))
+}
+
+func TestValidator_ValidateReportUTF8(t *testing.T) {
+	validator, err := consumer.NewValidator(consumer.ValidatorConfig{DefaultSchema: "schemas/order_created.json"})
+	assert.NoError(t, err)
+
+	err = validator.Validate(&queue.MessageDTO{Body: strings.Repeat("€", 2000) + "\xff"})
+
+	var validationErr *consumer.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+	report := validationErr.Attributes()[consumer.ValidationReportAttribute]
+	assert.True(t, utf8.ValidString(report))
+	assert.Len(t, report, 4095)
+}
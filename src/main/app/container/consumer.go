@@ -1,17 +1,520 @@
 package container
 
 import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/arielsrv/ikp_go-restclient/rest"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"github.com/src/main/app/client"
+	"github.com/src/main/app/codec"
 	"github.com/src/main/app/config"
 	"github.com/src/main/app/consumer"
+	"github.com/src/main/app/encryption"
+	"github.com/src/main/app/infrastructure/kvs"
 	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/infrastructure/topic"
 	"github.com/src/main/app/log"
+	"github.com/src/main/app/model"
 	"github.com/src/main/app/pusher"
 )
 
+var (
+	pusherOnce sync.Once
+	httpPusher pusher.Pusher
+)
+
+// ProvidePusher is the pusher of the consumers without transform template.
+// * Target responses are mapped by pusher.retry-status-codes, pusher.reject-status-codes and pusher.reject-header,
+// * see client.ResponseContract.
+// * With pusher.routes the messages are routed by content, pusher.target-endpoint is the default route.
+// * With pusher.fan-out.targets the default route pushes to several targets instead of pusher.target-endpoint.
+// * With a bridge block, pusher.bridge or the one of a route or target, the messages are published to a queue or topic.
+func ProvidePusher() pusher.Pusher {
+	pusherOnce.Do(func() {
+		httpPusher = newPusher(nil)
+	})
+
+	return httpPusher
+}
+
+// consumerPusher is messagePusher, with consumers.<name>.decode the avro or protobuf payloads are decoded first. The
+// binary output pushes the original payload to pusher.target-endpoint, without transformation nor enrichment.
+func consumerPusher(name string) pusher.Pusher {
+	key := func(property string) string {
+		return fmt.Sprintf("consumers.%s.decode.%s", name, property)
+	}
+
+	format := config.TryString(key("format"), "")
+	registryURL := config.TryString(key("registry.url"), "")
+	if format == "" && registryURL == "" {
+		return messagePusher(name)
+	}
+
+	output := pusher.DecoderOutput(config.TryString(key("output"), string(pusher.JSONOutput)))
+
+	var next pusher.Pusher
+	if output == pusher.BinaryOutput {
+		if config.TryString(fmt.Sprintf("consumers.%s.transform.template", name), "") != "" ||
+			config.TryString(fmt.Sprintf("consumers.%s.enrich.lookups", name), "") != "" ||
+			config.TryString(fmt.Sprintf("consumers.%s.decrypt.provider", name), "") != "" ||
+			config.TryString(fmt.Sprintf("consumers.%s.split.path", name), "") != "" {
+			log.Fatal(fmt.Errorf("consumer %s: binary output can not be decrypted, split, transformed nor enriched", name))
+		}
+		next = newBinaryPusher(config.TryString(key("content-type"), contentType(codec.Format(format))))
+	} else {
+		next = messagePusher(name)
+	}
+
+	var resolver codec.Resolver
+	if registryURL != "" {
+		restClient := config.TryString(key("registry.client"), "registry-client")
+		rb := config.ProvideRestClients().Get(restClient)
+		if rb == nil {
+			log.Fatal(fmt.Errorf("consumer %s: rest client %s not found", name, restClient))
+		}
+		registry, err := codec.NewRegistry(codec.RegistryConfig{
+			URL:         registryURL,
+			MessageType: config.TryString(key("message-type"), ""),
+		}, rb)
+		if err != nil {
+			log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+		}
+		resolver = registry
+	} else {
+		descriptor, err := os.ReadFile(config.Resource(config.String(key("schema"))))
+		if err != nil {
+			log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+		}
+		payloadCodec, err := codec.New(codec.Format(format), descriptor, config.TryString(key("message-type"), ""))
+		if err != nil {
+			log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+		}
+		resolver = codec.Fixed(payloadCodec)
+	}
+
+	decoder, err := pusher.NewDecoder(pusher.DecoderConfig{
+		Resolver: resolver,
+		Encoding: pusher.PayloadEncoding(config.TryString(key("encoding"), string(pusher.Base64Encoding))),
+		Output:   output,
+	}, next)
+	if err != nil {
+		log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+	}
+
+	return decoder
+}
+
+func contentType(format codec.Format) string {
+	switch format {
+	case codec.Avro:
+		return "application/avro"
+	case codec.Protobuf:
+		return "application/x-protobuf"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// messagePusher is ProvidePusher, or a pusher with the consumers.<name>.transform template of the consumer.
+// With consumers.<name>.enrich.lookups the messages are enriched before the push, with consumers.<name>.split the
+// parts are enriched and pushed one by one, and with consumers.<name>.decrypt they are decrypted first.
+func messagePusher(name string) pusher.Pusher {
+	return decrypter(name, splitter(name, enricher(name)))
+}
+
+func enricher(name string) pusher.Pusher {
+	key := func(property string) string {
+		return fmt.Sprintf("consumers.%s.%s", name, property)
+	}
+
+	var consumerPusher pusher.Pusher
+	if template := consumerTemplate(name); template != nil {
+		consumerPusher = newPusher(template)
+	} else {
+		consumerPusher = ProvidePusher()
+	}
+
+	lookupNames := splitList(config.TryString(key("enrich.lookups"), ""))
+	if len(lookupNames) == 0 {
+		return consumerPusher
+	}
+
+	lookups := make([]*pusher.Lookup, len(lookupNames))
+	for i, lookupName := range lookupNames {
+		lookupKey := func(property string) string {
+			return key(fmt.Sprintf("enrich.lookup.%s.%s", lookupName, property))
+		}
+
+		restClient := config.String(lookupKey("client"))
+		rb := config.ProvideRestClients().Get(restClient)
+		if rb == nil {
+			log.Fatal(fmt.Errorf("consumer %s: rest client %s not found", name, restClient))
+		}
+
+		lookup, err := pusher.NewLookup(pusher.LookupConfig{
+			Name:      lookupName,
+			URL:       config.String(lookupKey("url")),
+			Field:     config.TryString(lookupKey("field"), ""),
+			OnError:   pusher.LookupPolicy(config.TryString(lookupKey("on-error"), string(pusher.LookupFail))),
+			CacheSize: config.TryInt(lookupKey("cache-size"), 1000),
+			CacheTTL:  time.Millisecond * time.Duration(config.TryInt(lookupKey("cache-ttl"), 300000)),
+		}, rb)
+		if err != nil {
+			log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+		}
+		lookups[i] = lookup
+	}
+
+	return pusher.NewEnricher(lookups, consumerPusher)
+}
+
+// splitter reads consumers.<name>.split, a json path of the parts and the state-ttl of the delivered parts.
+func splitter(name string, next pusher.Pusher) pusher.Pusher {
+	path := config.TryString(fmt.Sprintf("consumers.%s.split.path", name), "")
+	if path == "" {
+		return next
+	}
+
+	consumerSplitter, err := pusher.NewSplitter(pusher.SplitterConfig{
+		Path:     path,
+		StateTTL: time.Millisecond * time.Duration(config.TryInt(fmt.Sprintf("consumers.%s.split.state-ttl", name), 86400000)),
+	}, next, kvs.NewElasticCacheClient[model.SplitStateDTO](ProvideKVSClient()))
+	if err != nil {
+		log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+	}
+
+	return consumerSplitter
+}
+
+// consumerTemplate reads consumers.<name>.transform, nil without template.
+func consumerTemplate(name string) *pusher.Template {
+	key := func(property string) string {
+		return fmt.Sprintf("consumers.%s.transform.%s", name, property)
+	}
+
+	text := config.TryString(key("template"), "")
+	if text == "" {
+		return nil
+	}
+
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{
+		Text:             text,
+		Headers:          keyValues(config.TryString(key("headers"), "")),
+		Sample:           config.TryString(key("sample"), ""),
+		SampleAttributes: keyValues(config.TryString(key("sample-attributes"), "")),
+	})
+	if err != nil {
+		log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+	}
+
+	return template
+}
+
+// decrypter reads consumers.<name>.decrypt, the static keys are id=base64 pairs of 256 bit keys for local runs.
+func decrypter(name string, next pusher.Pusher) pusher.Pusher {
+	key := func(property string) string {
+		return fmt.Sprintf("consumers.%s.decrypt.%s", name, property)
+	}
+
+	var keyProvider encryption.KeyProvider
+	switch provider := config.TryString(key("provider"), ""); provider {
+	case "":
+		return next
+	case "kms":
+		kmsKeyProvider, err := encryption.NewKMSKeyProvider(encryption.KMSConfig{
+			EncryptionContext: keyValues(config.TryString(key("kms.context"), "")),
+			CacheSize:         config.TryInt(key("kms.cache-size"), 1000),
+			CacheTTL:          time.Millisecond * time.Duration(config.TryInt(key("kms.cache-ttl"), 300000)),
+		}, kms.NewFromConfig(ProvideAWSConfig()))
+		if err != nil {
+			log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+		}
+		keyProvider = kmsKeyProvider
+	case "static":
+		keys := make(map[string][]byte)
+		for keyID, encoded := range keyValues(config.String(key("static.keys"))) {
+			value, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				log.Fatal(fmt.Errorf("consumer %s: static key %s: %w", name, keyID, err))
+			}
+			keys[keyID] = value
+		}
+		staticKeyProvider, err := encryption.NewStaticKeyProvider(keys)
+		if err != nil {
+			log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+		}
+		keyProvider = staticKeyProvider
+	default:
+		log.Fatal(fmt.Errorf("consumer %s: invalid key provider %s", name, provider))
+	}
+
+	consumerDecrypter, err := pusher.NewDecrypter(pusher.DecrypterConfig{
+		KeyProvider: keyProvider,
+		Fields:      splitList(config.TryString(key("fields"), "")),
+		Timeout:     time.Millisecond * time.Duration(config.TryInt(key("timeout"), 5000)),
+	}, next)
+	if err != nil {
+		log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+	}
+
+	return consumerDecrypter
+}
+
+func newPusher(template *pusher.Template) pusher.Pusher {
+	defaultPusher := fanOut(template)
+	if defaultPusher == nil {
+		defaultPusher = targetPusher(template)
+	}
+
+	routeNames := splitList(config.TryString("pusher.routes", ""))
+	if len(routeNames) == 0 {
+		return defaultPusher
+	}
+
+	routes := make([]pusher.Route, len(routeNames))
+	for i, name := range routeNames {
+		routes[i] = route(name, template)
+	}
+
+	return pusher.NewRouter(routes, pusher.Route{
+		Name:   pusher.DefaultRoute,
+		Pusher: defaultPusher,
+		Drop:   config.TryBool("pusher.default-route.drop", false),
+	})
+}
+
+// newHTTPPusher pushes to the endpoint template of the key with the rest client of the template, see
+// templateRequestBuilder.
+func newHTTPPusher(restClient string, endpointKey string, template *pusher.Template) pusher.Pusher {
+	rb := templateRequestBuilder(restClient, template)
+	contract := responseContract()
+
+	return endpointPusher(endpointKey, func(endpoint string) pusher.Pusher {
+		return pusher.NewHTTPPusher(client.NewHTTPPusherClient(rb, endpoint, contract), template)
+	})
+}
+
+// endpointPusher parses the endpoint template of the key, a static endpoint gets a single pusher and the endpoints
+// with message placeholders a pusher per message.
+func endpointPusher(endpointKey string, newPusher func(endpoint string) pusher.Pusher) pusher.Pusher {
+	endpoint := parseEndpoint(endpointKey)
+	if endpoint.Static() {
+		return newPusher(endpoint.String())
+	}
+
+	return pusher.NewEndpointPusher(endpoint, newPusher)
+}
+
+// parseEndpoint fails the startup with the placeholders that can not be resolved.
+func parseEndpoint(endpointKey string) *pusher.Endpoint {
+	endpoint, err := pusher.ParseEndpoint(config.String(endpointKey))
+	if err != nil {
+		log.Fatal(fmt.Errorf("%s: %w", endpointKey, err))
+	}
+
+	return endpoint
+}
+
+// targetPusher pushes to pusher.target-endpoint, or publishes to the pusher.bridge queue or topic. With
+// pusher.reply the target responses are published to a queue or topic.
+func targetPusher(template *pusher.Template) pusher.Pusher {
+	if bridgePusher := bridge("pusher", pusher.DefaultRoute, template); bridgePusher != nil {
+		return bridgePusher
+	}
+
+	replySender := sender("pusher.reply")
+	if replySender == nil {
+		return newHTTPPusher("target-client", "pusher.target-endpoint", template)
+	}
+
+	rb := templateRequestBuilder("target-client", template)
+	contract := responseContract()
+
+	// validated once at startup, the closure of a templated endpoint runs per message
+	replyPusher, err := pusher.NewReplyPusher(pusher.ReplyConfig{Sender: replySender, Template: template}, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return endpointPusher("pusher.target-endpoint", func(endpoint string) pusher.Pusher {
+		return replyPusher.WithReplyClient(client.NewHTTPPusherClient(rb, endpoint, contract))
+	})
+}
+
+// bridge reads the <prefix>.bridge queue or topic and its attribute-mapping, source=published name pairs, nil
+// without queue nor topic.
+func bridge(prefix string, name string, template *pusher.Template) pusher.Pusher {
+	bridgeSender := sender(prefix + ".bridge")
+	if bridgeSender == nil {
+		return nil
+	}
+
+	bridgePusher, err := pusher.NewBridge(pusher.BridgeConfig{
+		Name:       name,
+		Sender:     bridgeSender,
+		Template:   template,
+		Attributes: keyValues(config.TryString(prefix+".bridge.attribute-mapping", "")),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return bridgePusher
+}
+
+// sender reads <prefix>.queue, a queues-clients entry, or <prefix>.topic-arn, a sns topic, nil without them.
+func sender(prefix string) pusher.Sender {
+	if name := config.TryString(prefix+".queue", ""); name != "" {
+		return ProvideQueueSender(name)
+	}
+
+	if arn := config.TryString(prefix+".topic-arn", ""); arn != "" {
+		return topic.NewClient(topic.Config{
+			ARN:     arn,
+			Timeout: config.TryInt(prefix+".timeout", 1000),
+		}, ProvideAWSConfig())
+	}
+
+	return nil
+}
+
+// templateRequestBuilder is the rest client, a template with headers gets its own request builder on the same pool.
+func templateRequestBuilder(restClient string, template *pusher.Template) *rest.RequestBuilder {
+	rb := config.ProvideRestClients().Get(restClient)
+	if rb == nil {
+		log.Fatal(fmt.Errorf("rest client %s not found", restClient))
+	}
+
+	if template != nil && len(template.Headers()) > 0 {
+		rb = &rest.RequestBuilder{
+			Headers:        template.Headers(),
+			Timeout:        rb.Timeout,
+			ConnectTimeout: rb.ConnectTimeout,
+			CustomPool:     rb.CustomPool,
+		}
+	}
+
+	return rb
+}
+
+// newBinaryPusher pushes to pusher.target-endpoint with its own request builder on the pool of the target-client.
+func newBinaryPusher(contentType string) pusher.Pusher {
+	rb := config.ProvideRestClients().Get("target-client")
+	if rb == nil {
+		log.Fatal(fmt.Errorf("rest client %s not found", "target-client"))
+	}
+
+	binaryRB := &rest.RequestBuilder{
+		ContentType:    rest.BYTES,
+		Headers:        http.Header{"Content-Type": []string{contentType}},
+		Timeout:        rb.Timeout,
+		ConnectTimeout: rb.ConnectTimeout,
+		CustomPool:     rb.CustomPool,
+	}
+
+	contract := responseContract()
+
+	return endpointPusher("pusher.target-endpoint", func(endpoint string) pusher.Pusher {
+		return pusher.NewBinaryPusher(client.NewHTTPPusherClient(binaryRB, endpoint, contract))
+	})
+}
+
+func responseContract() client.ResponseContract {
+	return client.ResponseContract{
+		RetryStatusCodes:  statusCodes(config.TryString("pusher.retry-status-codes", "429,503")),
+		RejectStatusCodes: statusCodes(config.TryString("pusher.reject-status-codes", "422")),
+		RejectHeader:      config.TryString("pusher.reject-header", client.DefaultResponseContract.RejectHeader),
+		MaxRetryAfter:     time.Millisecond * time.Duration(config.TryInt("pusher.max-retry-after", 43200000)),
+	}
+}
+
+// fanOut reads pusher.fan-out: targets in order, the first one is the primary, ack-policy and state-ttl.
+func fanOut(template *pusher.Template) pusher.Pusher {
+	targetNames := splitList(config.TryString("pusher.fan-out.targets", ""))
+	if len(targetNames) == 0 {
+		return nil
+	}
+
+	targets := make([]pusher.Target, len(targetNames))
+	for i, name := range targetNames {
+		prefix := fmt.Sprintf("pusher.fan-out.target.%s", name)
+		targetPusher := bridge(prefix, name, template)
+		if targetPusher == nil {
+			targetPusher = newHTTPPusher(
+				config.TryString(prefix+".client", "target-client"),
+				prefix+".endpoint",
+				template)
+		}
+		targets[i] = pusher.Target{Name: name, Pusher: targetPusher}
+	}
+
+	fanOutPusher, err := pusher.NewFanOut(pusher.FanOutConfig{
+		Targets:   targets,
+		AckPolicy: pusher.AckPolicy(config.TryString("pusher.fan-out.ack-policy", string(pusher.AckAll))),
+		StateTTL:  time.Millisecond * time.Duration(config.TryInt("pusher.fan-out.state-ttl", 86400000)),
+	}, kvs.NewElasticCacheClient[model.FanOutStateDTO](ProvideKVSClient()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return fanOutPusher
+}
+
+// route reads pusher.route.<name>: attributes, fields and subject to match, a rest client and endpoint or a
+// bridge to push, or drop.
+func route(name string, template *pusher.Template) pusher.Route {
+	key := func(property string) string {
+		return fmt.Sprintf("pusher.route.%s.%s", name, property)
+	}
+
+	pusherRoute := pusher.Route{
+		Name: name,
+		Predicate: pusher.Predicate{
+			Attributes: keyValues(config.TryString(key("attributes"), "")),
+			Fields:     keyValues(config.TryString(key("fields"), "")),
+			Subject:    config.TryString(key("subject"), ""),
+		},
+		Drop: config.TryBool(key("drop"), false),
+	}
+
+	if !pusherRoute.Drop {
+		pusherRoute.Pusher = bridge(fmt.Sprintf("pusher.route.%s", name), name, template)
+	}
+
+	if !pusherRoute.Drop && pusherRoute.Pusher == nil {
+		pusherRoute.Pusher = newHTTPPusher(config.TryString(key("client"), "target-client"), key("endpoint"), template)
+	}
+
+	return pusherRoute
+}
+
+// keyValues parses a comma separated list of name=value pairs.
+func keyValues(value string) map[string]string {
+	items := splitList(value)
+	if len(items) == 0 {
+		return nil
+	}
+
+	values := make(map[string]string, len(items))
+	for _, item := range items {
+		name, itemValue, found := strings.Cut(item, "=")
+		if !found {
+			log.Fatal(fmt.Errorf("invalid name=value pair %s", item))
+		}
+		values[strings.TrimSpace(name)] = strings.TrimSpace(itemValue)
+	}
+
+	return values
+}
+
 var (
 	topicHandlerOnce sync.Once
 	topicConsumer    consumer.Consumer
@@ -19,24 +522,22 @@ var (
 
 func ProvideQueueConsumer() consumer.Consumer {
 	topicHandlerOnce.Do(func() {
-		rbPusher := config.ProvideRestClients().Get("target-client")
-		pusherClient := client.NewHTTPPusherClient(rbPusher, config.String("pusher.target-endpoint"))
-		httpPusher := pusher.NewHTTPPusher(pusherClient)
-
-		queueClient, err := queue.NewClient(queue.Config{
-			Name:     config.String("queues.orders.name"),
-			URL:      config.String("queues.orders.url"),
-			Parallel: config.TryInt("queues.orders.parallel", 10),
-			Timeout:  config.TryInt("queues.orders.timeout", 1000),
-		}, ProvideAWSConfig())
+		queueClient := ProvideQueueService("orders")
 
-		if err != nil {
-			log.Fatal(err)
+		// queues.<name> block of the dead letter queue for the messages rejected by the target
+		var deadLetterQueue queue.Service
+		if name := config.TryString("consumers.orders.dlq", ""); name != "" {
+			deadLetterQueue = ProvideQueueSender(name)
 		}
 
 		topicConsumer = consumer.NewConsumer(consumer.Config{
 			QueueService:     queueClient,
-			Pusher:           httpPusher,
+			DeadLetterQueue:  deadLetterQueue,
+			RetryPolicy:      retryPolicy("orders"),
+			Filter:           filter("orders"),
+			Validator:        validator("orders"),
+			Pusher:           consumerPusher("orders"),
+			Batch:            batch("orders"),
 			Workers:          config.TryInt("consumers.orders.workers", runtime.NumCPU()-1),
 			TaskResolverType: consumer.Async,
 		}, ProvideConsumerService())
@@ -44,3 +545,129 @@ func ProvideQueueConsumer() consumer.Consumer {
 
 	return topicConsumer
 }
+
+func statusCodes(value string) []int {
+	var codes []int
+	for _, item := range splitList(value) {
+		code, err := strconv.Atoi(item)
+		if err != nil {
+			log.Fatal(fmt.Errorf("invalid status code %s: %w", item, err))
+		}
+		codes = append(codes, code)
+	}
+
+	return codes
+}
+
+// filter reads the consumers.<name>.filter cel expression, see consumer.Filter.
+func filter(name string) *consumer.Filter {
+	expression := config.TryString(fmt.Sprintf("consumers.%s.filter", name), "")
+	if expression == "" {
+		return nil
+	}
+
+	consumerFilter, err := consumer.NewFilter(expression)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return consumerFilter
+}
+
+// batch reads consumers.<name>.batch, the batches are posted to its endpoint with the transform template of the
+// consumer, the other push stages apply to single messages.
+func batch(name string) *consumer.Batch {
+	key := func(property string) string {
+		return fmt.Sprintf("consumers.%s.batch.%s", name, property)
+	}
+
+	size := config.TryInt(key("size"), 0)
+	if size == 0 {
+		return nil
+	}
+
+	for _, stage := range []string{"decode.format", "decode.registry.url", "decrypt.provider", "split.path", "enrich.lookups"} {
+		if config.TryString(fmt.Sprintf("consumers.%s.%s", name, stage), "") != "" {
+			log.Fatal(fmt.Errorf("consumer %s: batches can not be used with consumers.%s.%s", name, name, stage))
+		}
+	}
+
+	template := consumerTemplate(name)
+	rb := templateRequestBuilder(config.TryString(key("client"), "target-client"), template)
+	// a batch has the messages of several endpoints, only the startup placeholders are allowed
+	endpoint := parseEndpoint(key("endpoint"))
+	if !endpoint.Static() {
+		log.Fatal(fmt.Errorf("consumer %s: %s can not have message placeholders", name, key("endpoint")))
+	}
+	batchClient := client.NewHTTPPusherClient(rb, endpoint.String(), responseContract())
+
+	consumerBatch, err := consumer.NewBatch(
+		pusher.NewHTTPBatchPusher(batchClient, template),
+		size,
+		time.Millisecond*time.Duration(config.TryInt(key("window"), 1000)),
+	)
+	if err != nil {
+		log.Fatal(fmt.Errorf("consumer %s: %w", name, err))
+	}
+
+	return consumerBatch
+}
+
+// validator reads consumers.<name>.validate, schemas are type=location pairs of src/resources paths or urls.
+// The validation can not be combined with decode nor decrypt, the payloads are validated before them.
+func validator(name string) *consumer.Validator {
+	prefix := fmt.Sprintf("consumers.%s.validate", name)
+	validatorConfig := consumer.ValidatorConfig{
+		TypeAttribute: config.TryString(prefix+".type-attribute", ""),
+		TypeField:     config.TryString(prefix+".type-field", ""),
+		Schemas:       keyValues(config.TryString(prefix+".schemas", "")),
+		DefaultSchema: config.TryString(prefix+".default-schema", ""),
+	}
+
+	if len(validatorConfig.Schemas) == 0 && validatorConfig.DefaultSchema == "" {
+		return nil
+	}
+
+	// the validation runs before the push stages, on the encoded or encrypted payload
+	for _, stage := range []string{"decode.format", "decode.registry.url", "decrypt.provider"} {
+		if config.TryString(fmt.Sprintf("consumers.%s.%s", name, stage), "") != "" {
+			log.Fatal(fmt.Errorf("consumer %s: validation can not be used with consumers.%s.%s", name, name, stage))
+		}
+	}
+
+	consumerValidator, err := consumer.NewValidator(validatorConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return consumerValidator
+}
+
+// retryPolicy reads consumers.<name>.retry, delays in ms and max-attempts, without delays the backend redelivers.
+// The queues.<name> backend must support delayed sends, otherwise the retries would never be counted.
+func retryPolicy(name string) *consumer.RetryPolicy {
+	delays := config.TryString(fmt.Sprintf("consumers.%s.retry.delays", name), "")
+	if delays == "" {
+		return nil
+	}
+
+	if queueType := queue.Type(config.TryString(fmt.Sprintf("queues.%s.type", name), string(queue.SQS))); !queueType.Delays() {
+		log.Fatal(fmt.Errorf("consumer %s: %s queues do not support delayed retries", name, queueType))
+	}
+
+	var schedule []time.Duration
+	for _, item := range splitList(delays) {
+		delay, err := strconv.Atoi(item)
+		if err != nil {
+			log.Fatal(fmt.Errorf("invalid retry delay %s: %w", item, err))
+		}
+		schedule = append(schedule, time.Millisecond*time.Duration(delay))
+	}
+
+	policy, err := consumer.NewRetryPolicy(schedule, config.TryInt(fmt.Sprintf("consumers.%s.retry.max-attempts", name), 0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return policy
+}
@@ -32,3 +32,27 @@ func ProvideConsumerHandler() *handlers.ConsumerHandler {
 	})
 	return consumerHandler
 }
+
+var (
+	snsHandlerOnce sync.Once
+	snsHandler     *handlers.SNSHandler
+)
+
+func ProvideSNSHandler() *handlers.SNSHandler {
+	snsHandlerOnce.Do(func() {
+		snsHandler = handlers.NewSNSHandler(ProvideSNSService())
+	})
+	return snsHandler
+}
+
+var (
+	queueHandlerOnce sync.Once
+	queueHandler     *handlers.QueueHandler
+)
+
+func ProvideQueueHandler() *handlers.QueueHandler {
+	queueHandlerOnce.Do(func() {
+		queueHandler = handlers.NewQueueHandler(ProvideQueueAdminService())
+	})
+	return queueHandler
+}
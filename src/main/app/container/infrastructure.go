@@ -3,6 +3,7 @@ package container
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/alicebob/miniredis/v2"
@@ -11,8 +12,12 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/src/main/app/config"
 	"github.com/src/main/app/config/env"
+	"github.com/src/main/app/infrastructure/queue"
 	"github.com/src/main/app/infrastructure/secrets"
 	"github.com/src/main/app/log"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 var (
@@ -96,3 +101,162 @@ func ProvideAWSSecretStore() secrets.SecretStore {
 
 	return secretsStore
 }
+
+var (
+	memoryBrokerOnce sync.Once
+	memoryBroker     *queue.MemoryBroker
+)
+
+func ProvideMemoryBroker() *queue.MemoryBroker {
+	memoryBrokerOnce.Do(func() {
+		memoryBroker = queue.NewMemoryBroker()
+	})
+
+	return memoryBroker
+}
+
+var (
+	queueServicesMutex sync.Mutex
+	queueServices      = map[string]queue.Service{}
+	queueSenders       = map[string]queue.Service{}
+)
+
+// ProvideQueueService
+// * Get queue backend for queues.<name> block from AppConfig. Backend is selected by queues.<name>.type:
+// * 1. sqs (default), amazon sqs queue by url.
+// * 2. kafka, consumer group over a topic.
+// * 3. nats, jetstream durable pull consumer over a stream.
+// * 4. pubsub, google pub/sub subscription.
+// * 5. memory, in-memory queue with sqs semantics for local development and tests.
+func ProvideQueueService(name string) queue.Service {
+	queueService, err := GetQueueService(name)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return queueService
+}
+
+// GetQueueService as ProvideQueueService, but returns the config error instead of exiting,
+// for queues resolved at request time by the admin endpoints.
+func GetQueueService(name string) (queue.Service, error) {
+	queueServicesMutex.Lock()
+	defer queueServicesMutex.Unlock()
+
+	if queueService, found := queueServices[name]; found {
+		return queueService, nil
+	}
+
+	queueService, err := newQueueService(name, false)
+	if err != nil {
+		return nil, err
+	}
+
+	queueServices[name] = queueService
+	return queueService, nil
+}
+
+// ProvideQueueSender as ProvideQueueService, for the queues the app only sends to, i.e. a bridge, reply or dead
+// letter queue. Kafka queues are producers without consumer group and nats queues have no durable consumer.
+func ProvideQueueSender(name string) queue.Service {
+	queueServicesMutex.Lock()
+	defer queueServicesMutex.Unlock()
+
+	if queueService, found := queueSenders[name]; found {
+		return queueService
+	}
+
+	queueService, err := newQueueService(name, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	queueSenders[name] = queueService
+	return queueService
+}
+
+func newQueueService(name string, sendOnly bool) (queue.Service, error) {
+	prefix := fmt.Sprintf("queues.%s", name)
+	queueType := queue.Type(config.TryString(prefix+".type", string(queue.SQS)))
+
+	switch queueType {
+	case queue.SQS:
+		return queue.NewClient(queue.Config{
+			Name:     config.String(prefix + ".name"),
+			URL:      config.String(prefix + ".url"),
+			Parallel: config.TryInt(prefix+".parallel", 10),
+			Timeout:  config.TryInt(prefix+".timeout", 1000),
+			// extended client pointers, the payloads over the sqs size limit are read from s3
+			ExtendedPayloads: config.TryBool(prefix+".extended-payloads.enabled", false),
+			DeletePayloads:   config.TryBool(prefix+".extended-payloads.delete", false),
+			S3PathStyle:      config.TryBool(prefix+".extended-payloads.path-style", false),
+		}, ProvideAWSConfig())
+	case queue.Kafka:
+		return queue.NewKafkaClient(queue.KafkaConfig{
+			Brokers:  strings.Split(config.String(prefix+".brokers"), ","),
+			Topic:    config.String(prefix + ".topic"),
+			Group:    config.TryString(prefix+".group", config.String("app.name")),
+			Parallel: config.TryInt(prefix+".parallel", 10),
+			Timeout:  config.TryInt(prefix+".timeout", 1000),
+			SendOnly: sendOnly,
+		})
+	case queue.NATS:
+		return queue.NewNATSClient(queue.NATSConfig{
+			URL:            config.String(prefix + ".url"),
+			Stream:         config.String(prefix + ".stream"),
+			Consumer:       config.TryString(prefix+".consumer", config.String("app.name")),
+			Subject:        config.TryString(prefix+".subject", ""),
+			Parallel:       config.TryInt(prefix+".parallel", 10),
+			Timeout:        config.TryInt(prefix+".timeout", 1000),
+			AckWait:        config.TryInt(prefix+".ack-wait", 30000),
+			NakDelay:       config.TryInt(prefix+".nak-delay", 5000),
+			MaxInProgress:  config.TryInt(prefix+".max-in-progress", 0),
+			PublishSubject: config.TryString(prefix+".publish-subject", ""),
+			SendOnly:       sendOnly,
+		})
+	case queue.PubSub:
+		return newPubSubQueueService(prefix)
+	case queue.Memory:
+		return queue.NewMemoryClient(queue.MemoryConfig{
+			Name:              config.String(prefix + ".name"),
+			Parallel:          config.TryInt(prefix+".parallel", 10),
+			Timeout:           config.TryInt(prefix+".timeout", 1000),
+			VisibilityTimeout: config.TryInt(prefix+".visibility-timeout", 30000),
+			Delay:             config.TryInt(prefix+".delay", 0),
+			MaxReceiveCount:   config.TryInt(prefix+".max-receive-count", 0),
+			DeadLetterQueue:   config.TryString(prefix+".dlq", ""),
+		}, ProvideMemoryBroker())
+	default:
+		return nil, fmt.Errorf("invalid queue type %s for queue %s", queueType, name)
+	}
+}
+
+// newPubSubQueueService
+// * If queues.<name>.endpoint is set, use the pub/sub emulator without credentials and without backlog metrics.
+// * Otherwise, use google default credentials and read the backlog from cloud monitoring.
+func newPubSubQueueService(prefix string) (queue.Service, error) {
+	pubSubConfig := queue.PubSubConfig{
+		Project:      config.String(prefix + ".project"),
+		Subscription: config.String(prefix + ".subscription"),
+		Parallel:     config.TryInt(prefix+".parallel", 10),
+		Timeout:      config.TryInt(prefix+".timeout", 1000),
+		NackDelay:    config.TryInt(prefix+".nack-delay", 10000),
+		Topic:        config.TryString(prefix+".topic", ""),
+	}
+
+	endpoint := config.TryString(prefix+".endpoint", "")
+	if !env.IsEmpty(endpoint) {
+		return queue.NewPubSubClient(pubSubConfig,
+			option.WithEndpoint(endpoint),
+			option.WithoutAuthentication(),
+			option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	}
+
+	backlogReader, err := queue.NewMonitoringBacklogReader()
+	if err != nil {
+		return nil, err
+	}
+	pubSubConfig.Backlog = backlogReader
+
+	return queue.NewPubSubClient(pubSubConfig)
+}
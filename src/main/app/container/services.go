@@ -1,10 +1,18 @@
 package container
 
 import (
+	"net/http"
+	"strings"
 	"sync"
 
+	"github.com/gofiber/fiber/v2"
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/config/env"
 	"github.com/src/main/app/infrastructure/kvs"
+	"github.com/src/main/app/infrastructure/sns"
+	"github.com/src/main/app/log"
 	"github.com/src/main/app/model"
+	"github.com/src/main/app/server"
 	"github.com/src/main/app/services"
 )
 
@@ -19,3 +27,77 @@ func ProvideConsumerService() services.IConsumerService {
 	})
 	return consumerService
 }
+
+var (
+	snsServiceOnce sync.Once
+	snsService     services.ISNSService
+)
+
+func ProvideSNSService() services.ISNSService {
+	snsServiceOnce.Do(func() {
+		snsClient, err := sns.NewClient(sns.Config{
+			Timeout:         config.TryInt("sns.timeout", 5000),
+			CertHostPattern: config.TryString("sns.cert-host-pattern", sns.DefaultCertHostPattern),
+		}, http.DefaultClient)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		snsService = services.NewSNSService(services.SNSConfig{
+			TopicArns: splitList(config.TryString("sns.topics", "")),
+		}, snsClient, ProvideQueueConsumer(), ProvideConsumerService())
+	})
+	return snsService
+}
+
+var (
+	queueAdminServiceOnce sync.Once
+	queueAdminService     services.IQueueService
+)
+
+// ProvideQueueAdminService
+// * Only the queues listed in admin.queues (comma separated) are exposed by the admin endpoints.
+// * Fields listed in admin.redact (comma separated) are masked in the peeked messages.
+func ProvideQueueAdminService() services.IQueueService {
+	queueAdminServiceOnce.Do(func() {
+		queueAdminService = services.NewQueueService(services.QueueConfig{
+			Queues: splitList(config.TryString("admin.queues", "")),
+			Redact: splitList(config.TryString("admin.redact", "")),
+		}, GetQueueService)
+	})
+	return queueAdminService
+}
+
+var (
+	adminAuthOnce sync.Once
+	adminAuth     fiber.Handler
+)
+
+// ProvideAdminAuth
+// * 1. If local use admin.token from AppConfig.
+// * 2. Otherwise, use admin.token from secret store, without token the admin endpoints deny every request.
+func ProvideAdminAuth() fiber.Handler {
+	adminAuthOnce.Do(func() {
+		token := config.TryString("admin.token", "")
+		if !env.IsLocal() {
+			secret := ProvideAWSSecretStore().Get("admin.token")
+			if secret.Err != nil {
+				log.Errorf("admin token not available, admin endpoints disabled: %s", secret.Err)
+			}
+			token = secret.Value
+		}
+		adminAuth = server.BearerAuth(token)
+	})
+	return adminAuth
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+
+	return values
+}
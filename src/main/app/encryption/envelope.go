@@ -0,0 +1,106 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidEnvelope is an envelope that can not be opened whatever the retries: malformed, or a failed
+// authentication of the ciphertext.
+var ErrInvalidEnvelope = errors.New("encryption: invalid envelope")
+
+// Envelope is a payload encrypted with AES-GCM by a data key, the data key is encrypted by a KeyProvider. The
+// binary fields are base64, the ciphertext includes the gcm tag.
+type Envelope struct {
+	KeyID        string `json:"key_id,omitempty"`
+	EncryptedKey string `json:"encrypted_key"`
+	Nonce        string `json:"nonce"`
+	Ciphertext   string `json:"ciphertext"`
+}
+
+// KeyProvider decrypts the data keys of the envelopes.
+type KeyProvider interface {
+	DataKey(ctx context.Context, keyID string, encryptedKey []byte) ([]byte, error)
+}
+
+// Open decrypts the envelope, the errors never include the plaintext.
+func Open(ctx context.Context, provider KeyProvider, envelope Envelope) ([]byte, error) {
+	encryptedKey, err := base64.StdEncoding.DecodeString(envelope.EncryptedKey)
+	if err != nil || len(encryptedKey) == 0 {
+		return nil, fmt.Errorf("%w: encrypted key", ErrInvalidEnvelope)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(envelope.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: nonce", ErrInvalidEnvelope)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ciphertext", ErrInvalidEnvelope)
+	}
+
+	dataKey, err := provider.DataKey(ctx, envelope.KeyID, encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(dataKey, nonce, ciphertext)
+}
+
+// Seal encrypts a plaintext with a new data key, the producer side of Open.
+func Seal(keyID string, dataKey []byte, encryptedKey []byte, plaintext []byte) (Envelope, error) {
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return Envelope{}, fmt.Errorf("encryption: %w", err)
+	}
+
+	return Envelope{
+		KeyID:        keyID,
+		EncryptedKey: base64.StdEncoding.EncodeToString(encryptedKey),
+		Nonce:        base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext:   base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, nil)),
+	}, nil
+}
+
+func open(key []byte, nonce []byte, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: nonce size %d", ErrInvalidEnvelope, len(nonce))
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidEnvelope, err)
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,73 @@
+package encryption_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/src/main/app/encryption"
+	"github.com/stretchr/testify/assert"
+)
+
+var masterKey = bytes.Repeat([]byte{0x01}, 32)
+
+func newStaticKeyProvider(t *testing.T) *encryption.StaticKeyProvider {
+	provider, err := encryption.NewStaticKeyProvider(map[string][]byte{"": masterKey, "pii": bytes.Repeat([]byte{0x02}, 16)})
+	assert.NoError(t, err)
+	return provider
+}
+
+func TestOpen(t *testing.T) {
+	provider := newStaticKeyProvider(t)
+
+	for _, keyID := range []string{"", "pii"} {
+		envelope, err := provider.Seal(keyID, []byte(`{"email": "john@doe.com"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, keyID, envelope.KeyID)
+		assert.NotContains(t, envelope.Ciphertext, "john")
+
+		plaintext, err := encryption.Open(context.Background(), provider, envelope)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"email": "john@doe.com"}`, string(plaintext))
+	}
+}
+
+func TestOpenErr(t *testing.T) {
+	provider := newStaticKeyProvider(t)
+	envelope, err := provider.Seal("", []byte("john@doe.com"))
+	assert.NoError(t, err)
+
+	tampered := envelope
+	ciphertext, _ := base64.StdEncoding.DecodeString(envelope.Ciphertext)
+	ciphertext[0] ^= 0xff
+	tampered.Ciphertext = base64.StdEncoding.EncodeToString(ciphertext)
+
+	unknownKey := envelope
+	unknownKey.KeyID = "orders"
+
+	for _, invalid := range []encryption.Envelope{
+		tampered,
+		unknownKey,
+		{EncryptedKey: envelope.EncryptedKey, Nonce: "AAAA", Ciphertext: envelope.Ciphertext},
+		{EncryptedKey: "not base64", Nonce: envelope.Nonce, Ciphertext: envelope.Ciphertext},
+		{},
+	} {
+		_, err = encryption.Open(context.Background(), provider, invalid)
+		assert.ErrorIs(t, err, encryption.ErrInvalidEnvelope)
+		assert.NotContains(t, err.Error(), "john")
+	}
+}
+
+func TestNewStaticKeyProviderErr(t *testing.T) {
+	_, err := encryption.NewStaticKeyProvider(nil)
+	assert.Error(t, err)
+
+	_, err = encryption.NewStaticKeyProvider(map[string][]byte{"": []byte("short")})
+	assert.Error(t, err)
+}
+
+func TestStaticKeyProvider_SealErr(t *testing.T) {
+	_, err := newStaticKeyProvider(t).Seal("orders", []byte("john@doe.com"))
+	assert.Error(t, err)
+}
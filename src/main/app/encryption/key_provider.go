@@ -0,0 +1,146 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/src/main/app/helpers/caching"
+)
+
+type KMSClient interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+type KMSConfig struct {
+	// EncryptionContext must be the one of the encryption of the data keys.
+	EncryptionContext map[string]string
+	// CacheSize of the decrypted data keys, the producers usually reuse a data key for several messages.
+	CacheSize int
+	CacheTTL  time.Duration
+}
+
+// KMSKeyProvider decrypts the data keys with aws kms, the key id of an envelope is the kms key, optional for the
+// symmetric keys.
+type KMSKeyProvider struct {
+	client            KMSClient
+	encryptionContext map[string]string
+	cache             caching.ICache[string, []byte]
+}
+
+func NewKMSKeyProvider(config KMSConfig, client KMSClient) (*KMSKeyProvider, error) {
+	provider := &KMSKeyProvider{
+		client:            client,
+		encryptionContext: config.EncryptionContext,
+	}
+
+	if config.CacheSize > 0 {
+		cache, err := caching.NewBuilder[string, []byte]().
+			Size(config.CacheSize).
+			ExpireAfterWrite(config.CacheTTL).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("kms key provider: %w", err)
+		}
+		provider.cache = cache
+	}
+
+	return provider, nil
+}
+
+func (p KMSKeyProvider) DataKey(ctx context.Context, keyID string, encryptedKey []byte) ([]byte, error) {
+	cacheKey := keyID + ":" + base64.StdEncoding.EncodeToString(encryptedKey)
+	if p.cache != nil {
+		if dataKey, found := p.cache.GetIfPresent(cacheKey); found {
+			return dataKey, nil
+		}
+	}
+
+	input := &kms.DecryptInput{
+		CiphertextBlob:    encryptedKey,
+		EncryptionContext: p.encryptionContext,
+	}
+	if keyID != "" {
+		input.KeyId = aws.String(keyID)
+	}
+
+	output, err := p.client.Decrypt(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("kms decrypt: %w", err)
+	}
+
+	if p.cache != nil {
+		p.cache.Put(cacheKey, output.Plaintext)
+	}
+
+	return output.Plaintext, nil
+}
+
+// StaticKeyProvider wraps the data keys with AES-GCM by local master keys, for tests and local development only.
+// The encrypted key is the nonce followed by the ciphertext.
+type StaticKeyProvider struct {
+	keys map[string][]byte
+}
+
+// NewStaticKeyProvider gets the master keys by key id, 16, 24 or 32 bytes, the empty id is the default key.
+func NewStaticKeyProvider(keys map[string][]byte) (*StaticKeyProvider, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("static key provider: keys are required")
+	}
+
+	for keyID, key := range keys {
+		if _, err := newGCM(key); err != nil {
+			return nil, fmt.Errorf("static key provider: key %s: %w", keyID, err)
+		}
+	}
+
+	return &StaticKeyProvider{keys: keys}, nil
+}
+
+func (p StaticKeyProvider) DataKey(_ context.Context, keyID string, encryptedKey []byte) ([]byte, error) {
+	key, found := p.keys[keyID]
+	if !found {
+		return nil, fmt.Errorf("%w: unknown key %s", ErrInvalidEnvelope, keyID)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(encryptedKey) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: encrypted key", ErrInvalidEnvelope)
+	}
+
+	return open(key, encryptedKey[:gcm.NonceSize()], encryptedKey[gcm.NonceSize():])
+}
+
+// Seal encrypts the plaintext with a new data key wrapped by the key of keyID.
+func (p StaticKeyProvider) Seal(keyID string, plaintext []byte) (Envelope, error) {
+	key, found := p.keys[keyID]
+	if !found {
+		return Envelope{}, fmt.Errorf("static key provider: unknown key %s", keyID)
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Envelope{}, fmt.Errorf("static key provider: %w", err)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return Envelope{}, fmt.Errorf("static key provider: %w", err)
+	}
+
+	return Seal(keyID, dataKey, gcm.Seal(nonce, nonce, dataKey, nil), plaintext)
+}
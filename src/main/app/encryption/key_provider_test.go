@@ -0,0 +1,69 @@
+package encryption_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/src/main/app/encryption"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockKMSClient struct {
+	mock.Mock
+}
+
+func (m *MockKMSClient) Decrypt(_ context.Context, params *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	args := m.Called(aws.ToString(params.KeyId), params.EncryptionContext)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*kms.DecryptOutput), args.Error(1)
+}
+
+func TestKMSKeyProvider_DataKey(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{0x03}, 32)
+	kmsClient := new(MockKMSClient)
+	kmsClient.On("Decrypt", "alias/pii", map[string]string{"app": "orders"}).
+		Return(&kms.DecryptOutput{Plaintext: dataKey}, nil)
+
+	provider, err := encryption.NewKMSKeyProvider(encryption.KMSConfig{
+		EncryptionContext: map[string]string{"app": "orders"},
+		CacheSize:         10,
+		CacheTTL:          time.Minute,
+	}, kmsClient)
+	assert.NoError(t, err)
+
+	envelope, err := encryption.Seal("alias/pii", dataKey, []byte("wrapped"), []byte("john@doe.com"))
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		plaintext, openErr := encryption.Open(context.Background(), provider, envelope)
+		assert.NoError(t, openErr)
+		assert.Equal(t, "john@doe.com", string(plaintext))
+	}
+
+	kmsClient.AssertNumberOfCalls(t, "Decrypt", 1)
+}
+
+func TestKMSKeyProvider_DataKeyErr(t *testing.T) {
+	kmsClient := new(MockKMSClient)
+	kmsClient.On("Decrypt", "", map[string]string(nil)).Return(nil, errors.New("throttling"))
+
+	provider, err := encryption.NewKMSKeyProvider(encryption.KMSConfig{}, kmsClient)
+	assert.NoError(t, err)
+
+	_, err = provider.DataKey(context.Background(), "", []byte("wrapped"))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, encryption.ErrInvalidEnvelope)
+}
+
+func TestNewKMSKeyProviderErr(t *testing.T) {
+	_, err := encryption.NewKMSKeyProvider(encryption.KMSConfig{CacheSize: 10}, new(MockKMSClient))
+	assert.Error(t, err)
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/server"
+	"github.com/src/main/app/services"
+)
+
+type IQueueHandler interface {
+	SendMessages(ctx *fiber.Ctx) error
+	Redrive(ctx *fiber.Ctx) error
+	Peek(ctx *fiber.Ctx) error
+}
+
+type QueueHandler struct {
+	queueService services.IQueueService
+}
+
+func NewQueueHandler(queueService services.IQueueService) *QueueHandler {
+	return &QueueHandler{
+		queueService: queueService,
+	}
+}
+
+// SendMessages godoc
+//
+// @Summary		Send messages to a queue
+// @Description	Publishes messages to an allowed queue, the result of each message is returned in order
+// @Tags		Queues
+// @Security	BearerAuth
+// @Accept 		json
+// @Produce		json
+// @Param		name	path	string	true	"Queue name"
+// @Param		request	body	model.SendMessagesDTO	true	"Messages"
+// @Success     200 {object} model.SendResultsDTO
+// @Failure		400
+// @Failure		401
+// @Failure		404
+// @Router		/queues/{name}/messages [post].
+func (h QueueHandler) SendMessages(ctx *fiber.Ctx) error {
+	sendMessagesDTO := new(model.SendMessagesDTO)
+	if err := ctx.BodyParser(sendMessagesDTO); err != nil {
+		return server.NewError(http.StatusBadRequest, fmt.Sprintf("invalid body: %s", err))
+	}
+
+	result, err := h.queueService.Send(ctx.UserContext(), ctx.Params("name"), sendMessagesDTO)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(result)
+}
+
+// Redrive godoc
+//
+// @Summary		Redrive messages between queues
+// @Description	Moves the messages matching the filter, usually from a dead letter queue back to its source queue
+// @Tags		Queues
+// @Security	BearerAuth
+// @Accept 		json
+// @Produce		json
+// @Param		name	path	string	true	"Queue name"
+// @Param		request	body	model.RedriveDTO	true	"Redrive"
+// @Success     200 {object} model.RedriveResultDTO
+// @Failure		400
+// @Failure		401
+// @Failure		404
+// @Router		/queues/{name}/redrive [post].
+func (h QueueHandler) Redrive(ctx *fiber.Ctx) error {
+	redriveDTO := new(model.RedriveDTO)
+	if err := ctx.BodyParser(redriveDTO); err != nil {
+		return server.NewError(http.StatusBadRequest, fmt.Sprintf("invalid body: %s", err))
+	}
+
+	result, err := h.queueService.Redrive(ctx.UserContext(), ctx.Params("name"), redriveDTO)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(result)
+}
+
+// Peek godoc
+//
+// @Summary		Peek messages of a queue
+// @Description	Returns decoded and redacted messages left available to the consumers, with the queue depth
+// @Tags		Queues
+// @Security	BearerAuth
+// @Produce		json
+// @Param		name	path	string	true	"Queue name"
+// @Param		max		query	int		false	"Max messages, 1 to 10"
+// @Success     200 {object} model.PeekDTO
+// @Failure		400
+// @Failure		401
+// @Failure		404
+// @Failure		501
+// @Router		/queues/{name}/peek [get].
+func (h QueueHandler) Peek(ctx *fiber.Ctx) error {
+	result, err := h.queueService.Peek(ctx.UserContext(), ctx.Params("name"), ctx.QueryInt("max", services.MaxPeekMessages))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(result)
+}
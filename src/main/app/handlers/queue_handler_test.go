@@ -0,0 +1,170 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/src/main/app/handlers"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/server"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type QueueHandlerSuite struct {
+	suite.Suite
+	app          *server.App
+	queueService *MockQueueService
+	queueHandler handlers.IQueueHandler
+}
+
+func TestQueueSuite(t *testing.T) {
+	suite.Run(t, new(QueueHandlerSuite))
+}
+
+type MockQueueService struct {
+	mock.Mock
+}
+
+func (m *MockQueueService) Send(_ context.Context, name string, sendMessagesDTO *model.SendMessagesDTO) (*model.SendResultsDTO, error) {
+	args := m.Called(name, sendMessagesDTO)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SendResultsDTO), args.Error(1)
+}
+
+func (m *MockQueueService) Redrive(_ context.Context, name string, redriveDTO *model.RedriveDTO) (*model.RedriveResultDTO, error) {
+	args := m.Called(name, redriveDTO)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RedriveResultDTO), args.Error(1)
+}
+
+func (m *MockQueueService) Peek(_ context.Context, name string, maxMsg int) (*model.PeekDTO, error) {
+	args := m.Called(name, maxMsg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PeekDTO), args.Error(1)
+}
+
+func (suite *QueueHandlerSuite) SetupTest() {
+	suite.queueService = new(MockQueueService)
+	suite.queueHandler = handlers.NewQueueHandler(suite.queueService)
+	suite.app = server.New()
+	suite.app.Server.Add(http.MethodPost, "/queues/:name/messages", suite.queueHandler.SendMessages)
+	suite.app.Server.Add(http.MethodPost, "/queues/:name/redrive", suite.queueHandler.Redrive)
+	suite.app.Server.Add(http.MethodGet, "/queues/:name/peek", suite.queueHandler.Peek)
+}
+
+func (suite *QueueHandlerSuite) TestQueueHandler_SendMessages() {
+	suite.queueService.On("Send", "orders", &model.SendMessagesDTO{
+		Messages: []model.SendMessageDTO{{Body: "msg1", Attributes: map[string]string{"event": "created"}, DelaySeconds: 10}},
+	}).Return(&model.SendResultsDTO{Results: []model.SendResultDTO{{MessageID: "1"}}}, nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/queues/orders/messages",
+		strings.NewReader(`{"messages":[{"body":"msg1","attributes":{"event":"created"},"delay_seconds":10}]}`))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	suite.NoError(err)
+	suite.Equal("{\"results\":[{\"message_id\":\"1\"}]}", string(body))
+}
+
+func (suite *QueueHandlerSuite) TestQueueHandler_SendMessagesInvalidBody() {
+	request := httptest.NewRequest(http.MethodPost, "/queues/orders/messages", strings.NewReader(`{`))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (suite *QueueHandlerSuite) TestQueueHandler_SendMessagesErr() {
+	suite.queueService.On("Send", "users", mock.Anything).
+		Return(nil, server.NewError(http.StatusNotFound, "queue users not found"))
+
+	request := httptest.NewRequest(http.MethodPost, "/queues/users/messages", strings.NewReader(`{"messages":[]}`))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusNotFound, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	suite.NoError(err)
+	suite.Equal("{\"status_code\":404,\"message\":\"queue users not found\"}", string(body))
+}
+
+func (suite *QueueHandlerSuite) TestQueueHandler_Redrive() {
+	suite.queueService.On("Redrive", "orders-dlq", &model.RedriveDTO{
+		Destination: "orders",
+		MaxMessages: 10,
+		Rate:        5,
+		Filter:      &model.RedriveFilterDTO{BodyContains: "order_id"},
+		DryRun:      true,
+	}).Return(&model.RedriveResultDTO{Matched: 2, Skipped: 1, DryRun: true}, nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/queues/orders-dlq/redrive",
+		strings.NewReader(`{"destination":"orders","max_messages":10,"rate":5,"filter":{"body_contains":"order_id"},"dry_run":true}`))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	suite.NoError(err)
+	suite.Equal("{\"matched\":2,\"moved\":0,\"skipped\":1,\"failed\":0,\"dry_run\":true}", string(body))
+}
+
+func (suite *QueueHandlerSuite) TestQueueHandler_RedriveErr() {
+	suite.queueService.On("Redrive", "orders-dlq", mock.Anything).
+		Return(nil, server.NewError(http.StatusBadRequest, "destination must be another queue"))
+
+	request := httptest.NewRequest(http.MethodPost, "/queues/orders-dlq/redrive", strings.NewReader(`{}`))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusBadRequest, response.StatusCode)
+}
+
+func (suite *QueueHandlerSuite) TestQueueHandler_Peek() {
+	count := 1
+	suite.queueService.On("Peek", "orders", 5).Return(&model.PeekDTO{
+		Queue:    "orders",
+		Count:    &count,
+		Messages: []model.PeekMessageDTO{{MessageID: "1", Body: map[string]any{"order_id": 1}}},
+	}, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/queues/orders/peek?max=5", nil)
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	suite.NoError(err)
+	suite.Equal("{\"queue\":\"orders\",\"count\":1,\"messages\":[{\"message_id\":\"1\",\"body\":{\"order_id\":1}}]}", string(body))
+}
+
+func (suite *QueueHandlerSuite) TestQueueHandler_PeekDefaultMax() {
+	suite.queueService.On("Peek", "orders", 10).Return(&model.PeekDTO{Queue: "orders"}, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/queues/orders/peek", nil)
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/src/main/app/services"
+)
+
+type ISNSHandler interface {
+	Receive(ctx *fiber.Ctx) error
+}
+
+type SNSHandler struct {
+	snsService services.ISNSService
+}
+
+func NewSNSHandler(snsService services.ISNSService) *SNSHandler {
+	return &SNSHandler{
+		snsService: snsService,
+	}
+}
+
+// Receive godoc
+//
+// @Summary		SNS http/s subscription endpoint
+// @Description	Confirms subscriptions and pushes notifications to the target app, a non 2xx status makes sns retry
+// @Tags		SNS
+// @Accept 		plain
+// @Produce		json
+// @Success		200
+// @Failure		403
+// @Failure		503
+// @Router		/sns [post].
+func (h SNSHandler) Receive(ctx *fiber.Ctx) error {
+	err := h.snsService.Receive(ctx.UserContext(), ctx.Body())
+	if err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(http.StatusOK)
+}
@@ -0,0 +1,68 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/src/main/app/handlers"
+	"github.com/src/main/app/server"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type SNSHandlerSuite struct {
+	suite.Suite
+	app        *server.App
+	snsService *MockSNSService
+	snsHandler handlers.ISNSHandler
+}
+
+func TestSNSSuite(t *testing.T) {
+	suite.Run(t, new(SNSHandlerSuite))
+}
+
+type MockSNSService struct {
+	mock.Mock
+}
+
+func (m *MockSNSService) Receive(_ context.Context, body []byte) error {
+	args := m.Called(string(body))
+	return args.Error(0)
+}
+
+func (suite *SNSHandlerSuite) SetupTest() {
+	suite.snsService = new(MockSNSService)
+	suite.snsHandler = handlers.NewSNSHandler(suite.snsService)
+	suite.app = server.New()
+	suite.app.Server.Add(http.MethodPost, "/sns", suite.snsHandler.Receive)
+}
+
+func (suite *SNSHandlerSuite) TestSNSHandler_Receive() {
+	suite.snsService.On("Receive", `{"Type":"Notification"}`).Return(nil)
+
+	request := httptest.NewRequest(http.MethodPost, "/sns", strings.NewReader(`{"Type":"Notification"}`))
+	request.Header.Set("Content-Type", "text/plain; charset=UTF-8")
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusOK, response.StatusCode)
+}
+
+func (suite *SNSHandlerSuite) TestSNSHandler_ReceiveErr() {
+	suite.snsService.On("Receive", `{"Type":"Notification"}`).
+		Return(server.NewError(http.StatusServiceUnavailable, "consumer stopped"))
+
+	request := httptest.NewRequest(http.MethodPost, "/sns", strings.NewReader(`{"Type":"Notification"}`))
+	response, err := suite.app.Server.Test(request)
+	suite.NoError(err)
+	suite.NotNil(response)
+	suite.Equal(http.StatusServiceUnavailable, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	suite.NoError(err)
+	suite.Equal("{\"status_code\":503,\"message\":\"consumer stopped\"}", string(body))
+}
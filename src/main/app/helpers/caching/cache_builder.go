@@ -0,0 +1,111 @@
+package caching
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+)
+
+type CacheBuilder[TKey comparable, TValue any] struct {
+	size     int
+	duration time.Duration
+}
+
+func NewBuilder[TKey comparable, TValue any]() *CacheBuilder[TKey, TValue] {
+	return &CacheBuilder[TKey, TValue]{}
+}
+
+func (c *CacheBuilder[TKey, TValue]) Size(size int) *CacheBuilder[TKey, TValue] {
+	c.size = size
+	return c
+}
+
+func (c *CacheBuilder[TKey, TValue]) ExpireAfterWrite(duration time.Duration) *CacheBuilder[TKey, TValue] {
+	c.duration = duration
+	return c
+}
+
+func (c *CacheBuilder[TKey, TValue]) Build() (ICache[TKey, TValue], error) {
+	if c.size <= 0 {
+		return nil, errors.New("cache size must be greater than 0")
+	}
+
+	if c.duration <= 0 {
+		return nil, errors.New("cache expire after write must be greater than 0")
+	}
+
+	return &Cache[TKey, TValue]{
+		size:     c.size,
+		duration: c.duration,
+		entries:  make(map[TKey]*list.Element, c.size),
+		order:    list.New(),
+	}, nil
+}
+
+type ICache[TKey comparable, TValue any] interface {
+	GetIfPresent(key TKey) (TValue, bool)
+	Put(key TKey, value TValue)
+}
+
+// Cache is a lru cache, an entry expires after write and the least recently used one is evicted when it is full.
+type Cache[TKey comparable, TValue any] struct {
+	mutex    sync.Mutex
+	size     int
+	duration time.Duration
+	entries  map[TKey]*list.Element
+	order    *list.List
+}
+
+type cacheEntry[TKey comparable, TValue any] struct {
+	key       TKey
+	value     TValue
+	expiresAt time.Time
+}
+
+func (c *Cache[TKey, TValue]) GetIfPresent(key TKey) (TValue, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var nilValue TValue
+	element, found := c.entries[key]
+	if !found {
+		return nilValue, false
+	}
+
+	entry := element.Value.(*cacheEntry[TKey, TValue])
+	if time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		return nilValue, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (c *Cache[TKey, TValue]) Put(key TKey, value TValue) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := &cacheEntry[TKey, TValue]{
+		key:       key,
+		value:     value,
+		expiresAt: time.Now().Add(c.duration),
+	}
+
+	if element, found := c.entries[key]; found {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *Cache[TKey, TValue]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry[TKey, TValue]).key)
+}
@@ -0,0 +1,62 @@
+package caching_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/src/main/app/helpers/caching"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	cache, err := caching.NewBuilder[string, int]().
+		Size(2).
+		ExpireAfterWrite(time.Minute).
+		Build()
+	assert.NoError(t, err)
+
+	cache.Put("one", 1)
+	cache.Put("two", 2)
+
+	value, found := cache.GetIfPresent("one")
+	assert.True(t, found)
+	assert.Equal(t, 1, value)
+
+	_, found = cache.GetIfPresent("three")
+	assert.False(t, found)
+}
+
+func TestBuildErr(t *testing.T) {
+	_, err := caching.NewBuilder[string, int]().ExpireAfterWrite(time.Minute).Build()
+	assert.Error(t, err)
+
+	_, err = caching.NewBuilder[string, int]().Size(1).Build()
+	assert.Error(t, err)
+}
+
+func TestCache_Evict(t *testing.T) {
+	cache, err := caching.NewBuilder[string, int]().Size(2).ExpireAfterWrite(time.Minute).Build()
+	assert.NoError(t, err)
+
+	cache.Put("one", 1)
+	cache.Put("two", 2)
+	cache.GetIfPresent("one")
+	cache.Put("three", 3)
+
+	_, found := cache.GetIfPresent("two")
+	assert.False(t, found)
+
+	_, found = cache.GetIfPresent("one")
+	assert.True(t, found)
+}
+
+func TestCache_Expire(t *testing.T) {
+	cache, err := caching.NewBuilder[string, int]().Size(2).ExpireAfterWrite(time.Millisecond * 10).Build()
+	assert.NoError(t, err)
+
+	cache.Put("one", 1)
+	time.Sleep(time.Millisecond * 20)
+
+	_, found := cache.GetIfPresent("one")
+	assert.False(t, found)
+}
@@ -0,0 +1,92 @@
+package redact
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Mask replaces the value of a redacted field.
+const Mask = "[REDACTED]"
+
+// Redactor masks fields by name, at any depth and case-insensitively.
+type Redactor struct {
+	fields map[string]bool
+}
+
+func NewRedactor(fields []string) *Redactor {
+	redactor := &Redactor{fields: make(map[string]bool)}
+	for _, field := range fields {
+		redactor.fields[strings.ToLower(field)] = true
+	}
+
+	return redactor
+}
+
+// Body decodes a json body, json documents nested as strings included, as the sns envelope message,
+// and masks the redacted fields. A non json body is returned as it is.
+func (r Redactor) Body(body string) any {
+	value, ok := decode(body)
+	if !ok {
+		return body
+	}
+
+	return r.value(value)
+}
+
+// Attributes returns a copy of the attributes with the redacted ones masked.
+func (r Redactor) Attributes(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+
+	redacted := make(map[string]string, len(attributes))
+	for key, value := range attributes {
+		if r.fields[strings.ToLower(key)] {
+			value = Mask
+		}
+		redacted[key] = value
+	}
+
+	return redacted
+}
+
+func (r Redactor) value(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			if r.fields[strings.ToLower(key)] {
+				typed[key] = Mask
+				continue
+			}
+			typed[key] = r.value(item)
+		}
+		return typed
+	case []any:
+		for i, item := range typed {
+			typed[i] = r.value(item)
+		}
+		return typed
+	case string:
+		if nested, ok := decode(typed); ok {
+			return r.value(nested)
+		}
+		return typed
+	default:
+		return typed
+	}
+}
+
+// decode only json objects and arrays, so plain strings and numbers are kept as they are.
+func decode(raw string) (any, bool) {
+	trimmed := strings.TrimSpace(raw)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(trimmed), &value); err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
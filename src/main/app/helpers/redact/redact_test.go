@@ -0,0 +1,47 @@
+package redact_test
+
+import (
+	"testing"
+
+	"github.com/src/main/app/helpers/redact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Body(t *testing.T) {
+	redactor := redact.NewRedactor([]string{"password", "Card"})
+
+	actual := redactor.Body(`{"user":"john","password":"secret","items":[{"card":"4111"}]}`)
+
+	assert.Equal(t, map[string]any{
+		"user":     "john",
+		"password": redact.Mask,
+		"items":    []any{map[string]any{"card": redact.Mask}},
+	}, actual)
+}
+
+func TestRedactor_BodyNested(t *testing.T) {
+	redactor := redact.NewRedactor([]string{"password"})
+
+	actual := redactor.Body(`{"MessageId":"1","Message":"{\"user\":\"john\",\"password\":\"secret\"}"}`)
+
+	assert.Equal(t, map[string]any{
+		"MessageId": "1",
+		"Message":   map[string]any{"user": "john", "password": redact.Mask},
+	}, actual)
+}
+
+func TestRedactor_BodyNotJSON(t *testing.T) {
+	redactor := redact.NewRedactor([]string{"password"})
+
+	assert.Equal(t, "password=secret", redactor.Body("password=secret"))
+	assert.Equal(t, "{invalid", redactor.Body("{invalid"))
+}
+
+func TestRedactor_Attributes(t *testing.T) {
+	redactor := redact.NewRedactor([]string{"token"})
+
+	actual := redactor.Attributes(map[string]string{"event": "created", "Token": "secret"})
+
+	assert.Equal(t, map[string]string{"event": "created", "Token": redact.Mask}, actual)
+	assert.Nil(t, redactor.Attributes(nil))
+}
@@ -1,6 +1,11 @@
 package kvs
 
+import "time"
+
 type Client[TValue any] interface {
 	Get(key string) (*TValue, error)
 	Save(key string, value *TValue) error
+	// SaveWithTTL expires the key after ttl, a zero ttl never expires.
+	SaveWithTTL(key string, value *TValue, ttl time.Duration) error
+	Delete(key string) error
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/src/main/app/config/env"
@@ -44,6 +45,10 @@ func (e ElasticCacheClient[TValue]) Get(key string) (*TValue, error) {
 }
 
 func (e ElasticCacheClient[TValue]) Save(key string, value *TValue) error {
+	return e.SaveWithTTL(key, value, 0)
+}
+
+func (e ElasticCacheClient[TValue]) SaveWithTTL(key string, value *TValue, ttl time.Duration) error {
 	if env.IsEmpty(key) {
 		return errors.New("missing key")
 	}
@@ -53,7 +58,7 @@ func (e ElasticCacheClient[TValue]) Save(key string, value *TValue) error {
 	}
 
 	err := e.client.
-		Set(ctx, key, value, 0).
+		Set(ctx, key, value, ttl).
 		Err()
 
 	if err != nil {
@@ -62,3 +67,9 @@ func (e ElasticCacheClient[TValue]) Save(key string, value *TValue) error {
 
 	return nil
 }
+
+func (e ElasticCacheClient[TValue]) Delete(key string) error {
+	return e.client.
+		Del(ctx, key).
+		Err()
+}
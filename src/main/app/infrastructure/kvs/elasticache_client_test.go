@@ -3,6 +3,7 @@ package kvs_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/go-redis/redismock/v9"
@@ -86,3 +87,28 @@ func TestNilError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, value)
 }
+
+func TestElasticCacheClient_SaveWithTTL(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	kvsClient := kvs.NewElasticCacheClient[model.FanOutStateDTO](db)
+	state := &model.FanOutStateDTO{Delivered: []string{"primary"}}
+	mock.ExpectSet("key", state, time.Hour).SetVal("OK")
+
+	err := kvsClient.SaveWithTTL("key", state, time.Hour)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestElasticCacheClient_Delete(t *testing.T) {
+	kvsClient := kvs.NewElasticCacheClient[model.FanOutStateDTO](container.ProvideKVSClient())
+
+	err := kvsClient.Save("fan-out", &model.FanOutStateDTO{Delivered: []string{"primary"}})
+	assert.NoError(t, err)
+
+	err = kvsClient.Delete("fan-out")
+	assert.NoError(t, err)
+
+	actual, err := kvsClient.Get("fan-out")
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
@@ -0,0 +1,150 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/src/main/app/log"
+)
+
+// Extended client pointers, the producers publish the payloads over the sqs size limit to s3 and send a body
+// that points to the object: ["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"b","s3Key":"k"}].
+const (
+	payloadS3PointerClass = "software.amazon.payloadoffloading.PayloadS3Pointer"
+	messageS3PointerClass = "com.amazon.sqs.javamessaging.MessageS3Pointer"
+)
+
+// extendedPayloadAttributes are reserved by the extended client, they are not message attributes.
+var extendedPayloadAttributes = []string{"ExtendedPayloadSize", "SQSLargePayloadSize"}
+
+// The receipt handle of a pointer message carries its object, as the extended client, so it is deleted with it.
+const (
+	s3BucketMarker = "-..s3BucketName..-"
+	s3KeyMarker    = "-..s3Key..-"
+)
+
+type S3Client interface {
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+}
+
+type PayloadS3Pointer struct {
+	Bucket string `json:"s3BucketName"`
+	Key    string `json:"s3Key"`
+}
+
+// parsePayloadS3Pointer returns the pointer of a body, false when it is a plain payload.
+func parsePayloadS3Pointer(body string) (*PayloadS3Pointer, bool) {
+	if !strings.HasPrefix(body, "[") {
+		return nil, false
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal([]byte(body), &items); err != nil || len(items) != 2 {
+		return nil, false
+	}
+
+	var class string
+	if err := json.Unmarshal(items[0], &class); err != nil ||
+		(class != payloadS3PointerClass && class != messageS3PointerClass) {
+		return nil, false
+	}
+
+	pointer := new(PayloadS3Pointer)
+	if err := json.Unmarshal(items[1], pointer); err != nil || pointer.Bucket == "" || pointer.Key == "" {
+		return nil, false
+	}
+
+	return pointer, true
+}
+
+// resolvePayloads replaces the pointer bodies with their s3 objects. A message whose object can not be read is
+// left in the queue, it is received again after the visibility timeout.
+func (s AWSQueueService) resolvePayloads(ctx context.Context, messages []MessageDTO) []MessageDTO {
+	resolved := messages[:0]
+	for _, message := range messages {
+		pointer, ok := parsePayloadS3Pointer(message.Body)
+		if !ok {
+			resolved = append(resolved, message)
+			continue
+		}
+
+		payload, err := s.getPayload(ctx, pointer)
+		if err != nil {
+			log.Errorf("extended payload of message %s: %s", message.MessageID, err)
+			continue
+		}
+
+		message.Body = payload
+		for _, name := range extendedPayloadAttributes {
+			delete(message.Attributes, name)
+		}
+		if s.DeletePayloads {
+			message.ReceiptHandle = withPayloadS3Pointer(message.ReceiptHandle, pointer)
+		}
+		resolved = append(resolved, message)
+	}
+
+	return resolved
+}
+
+func (s AWSQueueService) getPayload(ctx context.Context, pointer *PayloadS3Pointer) (string, error) {
+	output, err := s.S3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(pointer.Bucket),
+		Key:    aws.String(pointer.Key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("get s3://%s/%s: %w", pointer.Bucket, pointer.Key, err)
+	}
+	defer output.Body.Close()
+
+	payload, err := io.ReadAll(output.Body)
+	if err != nil {
+		return "", fmt.Errorf("read s3://%s/%s: %w", pointer.Bucket, pointer.Key, err)
+	}
+
+	return string(payload), nil
+}
+
+// deletePayload deletes the object of an acked message, a failure is logged since the message is already deleted.
+func (s AWSQueueService) deletePayload(ctx context.Context, pointer *PayloadS3Pointer) {
+	if _, err := s.S3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(pointer.Bucket),
+		Key:    aws.String(pointer.Key),
+	}); err != nil {
+		log.Errorf("delete extended payload s3://%s/%s: %s", pointer.Bucket, pointer.Key, err)
+	}
+}
+
+func withPayloadS3Pointer(receiptHandle string, pointer *PayloadS3Pointer) string {
+	return s3BucketMarker + pointer.Bucket + s3BucketMarker + s3KeyMarker + pointer.Key + s3KeyMarker + receiptHandle
+}
+
+// splitReceiptHandle returns the sqs receipt handle and the pointer it carries, nil for the plain ones.
+func splitReceiptHandle(receiptHandle string) (string, *PayloadS3Pointer) {
+	bucket, rest, found := cutMarker(receiptHandle, s3BucketMarker)
+	if !found {
+		return receiptHandle, nil
+	}
+
+	key, rest, found := cutMarker(rest, s3KeyMarker)
+	if !found {
+		return receiptHandle, nil
+	}
+
+	return rest, &PayloadS3Pointer{Bucket: bucket, Key: key}
+}
+
+func cutMarker(value string, marker string) (string, string, bool) {
+	rest, found := strings.CutPrefix(value, marker)
+	if !found {
+		return "", value, false
+	}
+
+	return strings.Cut(rest, marker)
+}
@@ -0,0 +1,126 @@
+package queue_test
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugurcsen/gods-generic/maps/hashmap"
+)
+
+const payloadS3Pointer = `["software.amazon.payloadoffloading.PayloadS3Pointer",` +
+	`{"s3BucketName":"orders-payloads","s3Key":"7c0e3f1a"}]`
+
+type MockS3Client struct {
+	objects map[string]string
+}
+
+func (m *MockS3Client) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	object, found := m.objects[aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key)]
+	if !found {
+		return nil, errors.New("NoSuchKey")
+	}
+
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewBufferString(object))}, nil
+}
+
+func (m *MockS3Client) DeleteObject(_ context.Context, params *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(m.objects, aws.ToString(params.Bucket)+"/"+aws.ToString(params.Key))
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func newExtendedClient(messages ...types.Message) (queue.AWSQueueService, *MockS3Client) {
+	queueURL := "https://queues.com/my-queue"
+	l := new(list.List)
+	for _, message := range messages {
+		l.PushBack(message)
+	}
+	queues := hashmap.New[string, *list.List]()
+	queues.Put(queueURL, l)
+
+	s3Client := &MockS3Client{objects: map[string]string{"orders-payloads/7c0e3f1a": `{"order_id": 1}`}}
+	queueClient := queue.NewMockClient(queue.MockConfig{
+		QueueURL: queueURL,
+		MaxMsg:   10,
+		Queues:   queues,
+	})
+	queueClient.S3Client = s3Client
+	queueClient.DeletePayloads = true
+
+	return queueClient, s3Client
+}
+
+func TestAWSQueueService_ReceiveExtendedPayload(t *testing.T) {
+	queueClient, s3Client := newExtendedClient(
+		types.Message{
+			MessageId:     aws.String("1"),
+			Body:          aws.String(payloadS3Pointer),
+			ReceiptHandle: aws.String("rpt1"),
+			MessageAttributes: map[string]types.MessageAttributeValue{
+				"ExtendedPayloadSize": {DataType: aws.String("Number"), StringValue: aws.String("300000")},
+				"event":               {DataType: aws.String("String"), StringValue: aws.String("order.created")},
+			},
+		},
+		types.Message{
+			MessageId:     aws.String("2"),
+			Body:          aws.String(`["order", {"s3BucketName": "orders-payloads"}]`),
+			ReceiptHandle: aws.String("rpt2"),
+		},
+	)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+
+	assert.Equal(t, `{"order_id": 1}`, messages[0].Body)
+	assert.Equal(t, map[string]string{"event": "order.created"}, messages[0].Attributes)
+	assert.NotEqual(t, "rpt1", messages[0].ReceiptHandle)
+	assert.Equal(t, `["order", {"s3BucketName": "orders-payloads"}]`, messages[1].Body)
+	assert.Equal(t, "rpt2", messages[1].ReceiptHandle)
+
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[0].ReceiptHandle))
+	assert.Empty(t, s3Client.objects)
+
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[1].ReceiptHandle))
+}
+
+func TestAWSQueueService_ReceiveExtendedPayloadMissing(t *testing.T) {
+	queueClient, s3Client := newExtendedClient(types.Message{
+		MessageId:     aws.String("1"),
+		Body:          aws.String(payloadS3Pointer),
+		ReceiptHandle: aws.String("rpt1"),
+	})
+	delete(s3Client.objects, "orders-payloads/7c0e3f1a")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, aws.ToInt(count))
+}
+
+func TestAWSQueueService_NackExtendedPayload(t *testing.T) {
+	queueClient, s3Client := newExtendedClient(types.Message{
+		MessageId:     aws.String("1"),
+		Body:          aws.String(payloadS3Pointer),
+		ReceiptHandle: aws.String("rpt1"),
+	})
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	assert.NoError(t, queueClient.Nack(context.Background(), messages[0].ReceiptHandle, time.Minute))
+	assert.Len(t, s3Client.objects, 1)
+}
@@ -0,0 +1,515 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/src/main/app/log"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kerr"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+)
+
+// KafkaReceiveCountHeader is the delivery count of a record re-produced by Nack, it is not an attribute.
+const KafkaReceiveCountHeader = "kafka-receive-count"
+
+type KafkaQueueService struct {
+	Timeout  time.Duration
+	Topic    string
+	Group    string
+	MaxMsg   int
+	brokers  []string
+	client   *kgo.Client
+	admin    *kadm.Client
+	pollLock *sync.Mutex
+	offsets  *offsetTracker
+	sendOnly bool
+}
+
+type KafkaConfig struct {
+	Brokers  []string
+	Topic    string
+	Group    string
+	Parallel int
+	Timeout  int
+	// SendOnly is a producer without consumer group, for the topics the app only sends to.
+	SendOnly bool
+}
+
+func NewKafkaClient(config KafkaConfig) (*KafkaQueueService, error) {
+	if config.Parallel < 1 {
+		log.Errorf("receive argument: parallel must be greater than 0: given %d", config.Parallel)
+		return nil, errors.New("invalidad parallel value")
+	}
+
+	offsets := newOffsetTracker()
+	opts := []kgo.Opt{kgo.SeedBrokers(config.Brokers...)}
+	if !config.SendOnly {
+		opts = append(opts,
+			kgo.ConsumerGroup(config.Group),
+			kgo.ConsumeTopics(config.Topic),
+			kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+			kgo.DisableAutoCommit(),
+			kgo.OnPartitionsRevoked(offsets.revoke),
+			kgo.OnPartitionsLost(offsets.revoke),
+		)
+	}
+
+	client, err := kgo.NewClient(opts...)
+
+	if err != nil {
+		return nil, fmt.Errorf("kafka client: %w", err)
+	}
+
+	return &KafkaQueueService{
+		Timeout:  time.Millisecond * time.Duration(config.Timeout),
+		Topic:    config.Topic,
+		Group:    config.Group,
+		MaxMsg:   config.Parallel,
+		brokers:  config.Brokers,
+		client:   client,
+		admin:    kadm.NewClient(client),
+		pollLock: new(sync.Mutex),
+		offsets:  offsets,
+		sendOnly: config.SendOnly,
+	}, nil
+}
+
+func (s KafkaQueueService) Receive(ctx context.Context) ([]MessageDTO, error) {
+	if s.sendOnly {
+		return nil, fmt.Errorf("receive: %w", ErrSendOnly)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	s.pollLock.Lock()
+	fetches := s.client.PollRecords(ctx, s.MaxMsg)
+	s.pollLock.Unlock()
+
+	if fetches.IsClientClosed() {
+		return nil, errors.New("receive: kafka client closed")
+	}
+
+	var fetchErr error
+	fetches.EachError(func(topic string, partition int32, err error) {
+		if !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+			fetchErr = fmt.Errorf("receive: topic %s, partition %d: %w", topic, partition, err)
+		}
+	})
+
+	if fetchErr != nil {
+		return nil, fetchErr
+	}
+
+	records := fetches.Records()
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	messages := make([]MessageDTO, len(records))
+	for i, record := range records {
+		s.offsets.track(record)
+		messageDTO := new(MessageDTO)
+		messageDTO.MessageID = kafkaMessageID(record)
+		messageDTO.Body = string(record.Value)
+		messageDTO.ReceiptHandle = newKafkaReceiptHandle(record)
+		messageDTO.ReceiveCount = receiveCount(record) + 1
+		messageDTO.Attributes = fromKafkaHeaders(record.Headers)
+		messageDTO.Raw = true
+		messages[i] = *messageDTO
+	}
+
+	return messages, nil
+}
+
+func (s KafkaQueueService) Send(ctx context.Context, message SendMessageDTO) (string, error) {
+	results, err := s.SendBatch(ctx, []SendMessageDTO{message})
+	if err != nil {
+		return "", err
+	}
+
+	return results[0].MessageID, results[0].Err
+}
+
+// SendBatch produces the messages to the topic, attributes are sent as record headers.
+func (s KafkaQueueService) SendBatch(ctx context.Context, messages []SendMessageDTO) ([]SendResultDTO, error) {
+	records := make([]*kgo.Record, len(messages))
+	for i, message := range messages {
+		if message.Delay > 0 {
+			return nil, fmt.Errorf("send: %w", ErrDelayNotSupported)
+		}
+		records[i] = &kgo.Record{
+			Topic:   s.Topic,
+			Value:   []byte(message.Body),
+			Headers: toKafkaHeaders(message.Attributes),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	indexes := make(map[*kgo.Record]int, len(records))
+	for i, record := range records {
+		indexes[record] = i
+	}
+
+	// produce results are in completion order, not in records order
+	results := make([]SendResultDTO, len(messages))
+	for _, produceResult := range s.client.ProduceSync(ctx, records...) {
+		i := indexes[produceResult.Record]
+		if produceResult.Err != nil {
+			results[i].Err = fmt.Errorf("send: %w", produceResult.Err)
+			continue
+		}
+		record := produceResult.Record
+		results[i].MessageID = fmt.Sprintf("%s-%d-%d", record.Topic, record.Partition, record.Offset)
+	}
+
+	return results, nil
+}
+
+// Delete marks the record as done and commits the highest contiguous completed offset of its partition,
+// so an out of order ack never commits past a record that is still in flight.
+func (s KafkaQueueService) Delete(ctx context.Context, receiptHandle string) error {
+	handle, err := parseKafkaReceiptHandle(receiptHandle)
+	if err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	committable, found := s.offsets.done(handle)
+	if !found {
+		return fmt.Errorf("delete: partition %d of topic %s is no longer assigned", handle.partition, handle.topic)
+	}
+
+	if committable == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	var commitErr error
+	s.client.CommitOffsetsSync(ctx, map[string]map[int32]kgo.EpochOffset{
+		handle.topic: {handle.partition: *committable},
+	}, func(_ *kgo.Client, _ *kmsg.OffsetCommitRequest, response *kmsg.OffsetCommitResponse, err error) {
+		if err != nil {
+			commitErr = err
+			return
+		}
+		for _, topic := range response.Topics {
+			for _, partition := range topic.Partitions {
+				if partitionErr := kerr.ErrorForCode(partition.ErrorCode); partitionErr != nil {
+					commitErr = partitionErr
+				}
+			}
+		}
+	})
+
+	if commitErr != nil {
+		return fmt.Errorf("delete: commit error: %w", commitErr)
+	}
+
+	return nil
+}
+
+// Nack re-produces the record to its topic with its receive count and commits past it, as kafka can not redeliver
+// a single record and a record never deleted would stop the commits of its partition. The keyed records keep their
+// partition. The delay is not supported, the record is redelivered when the consumers reach it.
+func (s KafkaQueueService) Nack(ctx context.Context, receiptHandle string, _ time.Duration) error {
+	handle, err := parseKafkaReceiptHandle(receiptHandle)
+	if err != nil {
+		return fmt.Errorf("nack: %w", err)
+	}
+
+	record, found := s.offsets.record(handle)
+	if !found {
+		return fmt.Errorf("nack: record %s is no longer in flight", receiptHandle)
+	}
+
+	// the re-produced record has a new offset, the header keeps the id of the first delivery
+	originalID := kafkaMessageID(record)
+	headers := make([]kgo.RecordHeader, 0, len(record.Headers)+2)
+	for _, header := range record.Headers {
+		switch header.Key {
+		case KafkaReceiveCountHeader:
+		case OriginalMessageIDAttribute:
+			originalID = string(header.Value)
+		default:
+			headers = append(headers, header)
+		}
+	}
+	headers = append(headers,
+		kgo.RecordHeader{Key: KafkaReceiveCountHeader, Value: []byte(strconv.Itoa(receiveCount(record) + 1))},
+		kgo.RecordHeader{Key: OriginalMessageIDAttribute, Value: []byte(originalID)},
+	)
+
+	produceCtx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	if err = s.client.ProduceSync(produceCtx, &kgo.Record{
+		Topic:   record.Topic,
+		Key:     record.Key,
+		Value:   record.Value,
+		Headers: headers,
+	}).FirstErr(); err != nil {
+		return fmt.Errorf("nack: %w", err)
+	}
+
+	if err = s.Delete(ctx, receiptHandle); err != nil {
+		return fmt.Errorf("nack: %w", err)
+	}
+
+	return nil
+}
+
+// Count reports the consumer group lag, the kafka equivalent of the messages waiting in the queue.
+func (s KafkaQueueService) Count(ctx context.Context) (*int, error) {
+	if s.sendOnly {
+		return nil, fmt.Errorf("consumer lag error: %w", ErrSendOnly)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	lags, err := s.admin.Lag(ctx, s.Group)
+	if err != nil {
+		return nil, fmt.Errorf("consumer lag error: %w", err)
+	}
+
+	groupLag, found := lags[s.Group]
+	if !found {
+		return nil, fmt.Errorf("consumer lag error: missing group %s", s.Group)
+	}
+
+	if groupLag.Error() != nil {
+		return nil, fmt.Errorf("consumer lag error: %w", groupLag.Error())
+	}
+
+	return aws.Int(int(groupLag.Lag.Total())), nil
+}
+
+// Peek reads the records after the committed offsets of the group with a client out of the group,
+// so the group offsets and the records being consumed are not changed.
+func (s KafkaQueueService) Peek(ctx context.Context, maxMsg int) ([]MessageDTO, error) {
+	if s.sendOnly {
+		return nil, fmt.Errorf("peek: %w", ErrSendOnly)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	lags, err := s.admin.Lag(ctx, s.Group)
+	if err != nil {
+		return nil, fmt.Errorf("peek: consumer lag: %w", err)
+	}
+
+	partitions := make(map[int32]kgo.Offset)
+	pending := int64(0)
+	for _, memberLag := range lags[s.Group].Lag[s.Topic] {
+		if memberLag.Err != nil || memberLag.Lag <= 0 {
+			continue
+		}
+
+		offset := memberLag.Commit.At
+		if offset < 0 {
+			offset = memberLag.Start.Offset
+		}
+		partitions[memberLag.Partition] = kgo.NewOffset().At(offset)
+		pending += memberLag.Lag
+	}
+
+	if len(partitions) == 0 {
+		return nil, nil
+	}
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(s.brokers...),
+		kgo.ConsumePartitions(map[string]map[int32]kgo.Offset{s.Topic: partitions}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("peek: kafka client: %w", err)
+	}
+	defer client.Close()
+
+	maxMsg = int(min(int64(maxMsg), pending))
+	var messages []MessageDTO
+	for len(messages) < maxMsg && ctx.Err() == nil {
+		fetches := client.PollRecords(ctx, maxMsg-len(messages))
+		for _, record := range fetches.Records() {
+			messages = append(messages, MessageDTO{
+				MessageID:  fmt.Sprintf("%s-%d-%d", record.Topic, record.Partition, record.Offset),
+				Body:       string(record.Value),
+				Attributes: fromKafkaHeaders(record.Headers),
+			})
+		}
+	}
+
+	return messages, nil
+}
+
+func (s KafkaQueueService) Close() {
+	s.client.Close()
+}
+
+type kafkaReceiptHandle struct {
+	topic     string
+	partition int32
+	offset    int64
+}
+
+func newKafkaReceiptHandle(record *kgo.Record) string {
+	return fmt.Sprintf("%s:%d:%d", record.Topic, record.Partition, record.Offset)
+}
+
+func parseKafkaReceiptHandle(receiptHandle string) (*kafkaReceiptHandle, error) {
+	fields := strings.Split(receiptHandle, ":")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid receipt handle: %s", receiptHandle)
+	}
+
+	partition, partitionErr := strconv.ParseInt(fields[1], 10, 32)
+	offset, offsetErr := strconv.ParseInt(fields[2], 10, 64)
+	if err := errors.Join(partitionErr, offsetErr); err != nil {
+		return nil, fmt.Errorf("invalid receipt handle: %s: %w", receiptHandle, err)
+	}
+
+	return &kafkaReceiptHandle{
+		topic:     fields[0],
+		partition: int32(partition),
+		offset:    offset,
+	}, nil
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// partitionOffsets keeps the in-flight offsets of a partition in fetch order, and its records for a nack.
+type partitionOffsets struct {
+	inFlight []kgo.EpochOffset
+	acked    map[int64]bool
+	records  map[int64]*kgo.Record
+}
+
+type offsetTracker struct {
+	mutex      sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{
+		partitions: make(map[topicPartition]*partitionOffsets),
+	}
+}
+
+func (t *offsetTracker) track(record *kgo.Record) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := topicPartition{topic: record.Topic, partition: record.Partition}
+	offsets, found := t.partitions[key]
+	if !found {
+		offsets = &partitionOffsets{acked: make(map[int64]bool), records: make(map[int64]*kgo.Record)}
+		t.partitions[key] = offsets
+	}
+	offsets.inFlight = append(offsets.inFlight, kgo.EpochOffset{Epoch: record.LeaderEpoch, Offset: record.Offset})
+	offsets.records[record.Offset] = record
+}
+
+// record returns an in-flight record, not found once it is done or its partition is revoked.
+func (t *offsetTracker) record(handle *kafkaReceiptHandle) (*kgo.Record, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	offsets, found := t.partitions[topicPartition{topic: handle.topic, partition: handle.partition}]
+	if !found {
+		return nil, false
+	}
+
+	record, found := offsets.records[handle.offset]
+	return record, found
+}
+
+// done acks an offset and returns the next offset to commit when the contiguous completed prefix advanced.
+func (t *offsetTracker) done(handle *kafkaReceiptHandle) (*kgo.EpochOffset, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	offsets, found := t.partitions[topicPartition{topic: handle.topic, partition: handle.partition}]
+	if !found {
+		return nil, false
+	}
+
+	offsets.acked[handle.offset] = true
+	delete(offsets.records, handle.offset)
+
+	var committable *kgo.EpochOffset
+	for len(offsets.inFlight) > 0 && offsets.acked[offsets.inFlight[0].Offset] {
+		completed := offsets.inFlight[0]
+		delete(offsets.acked, completed.Offset)
+		offsets.inFlight = offsets.inFlight[1:]
+		committable = &kgo.EpochOffset{Epoch: completed.Epoch, Offset: completed.Offset + 1}
+	}
+
+	return committable, true
+}
+
+func (t *offsetTracker) revoke(_ context.Context, _ *kgo.Client, revoked map[string][]int32) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for topic, partitions := range revoked {
+		for _, partition := range partitions {
+			delete(t.partitions, topicPartition{topic: topic, partition: partition})
+		}
+	}
+}
+
+func toKafkaHeaders(attributes map[string]string) []kgo.RecordHeader {
+	headers := make([]kgo.RecordHeader, 0, len(attributes))
+	for name, value := range attributes {
+		headers = append(headers, kgo.RecordHeader{Key: name, Value: []byte(value)})
+	}
+
+	return headers
+}
+
+func kafkaMessageID(record *kgo.Record) string {
+	return fmt.Sprintf("%s-%d-%d", record.Topic, record.Partition, record.Offset)
+}
+
+func fromKafkaHeaders(headers []kgo.RecordHeader) map[string]string {
+	var attributes map[string]string
+	for _, header := range headers {
+		if header.Key == KafkaReceiveCountHeader {
+			continue
+		}
+		if attributes == nil {
+			attributes = make(map[string]string, len(headers))
+		}
+		attributes[header.Key] = string(header.Value)
+	}
+
+	return attributes
+}
+
+// receiveCount is the number of previous deliveries of a re-produced record, 0 for a new record.
+func receiveCount(record *kgo.Record) int {
+	for _, header := range record.Headers {
+		if header.Key == KafkaReceiveCountHeader {
+			count, _ := strconv.Atoi(string(header.Value))
+			return count
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,275 @@
+package queue_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+func newKafkaCluster(t *testing.T, topic string, messages ...string) []string {
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, topic))
+	assert.NoError(t, err)
+	t.Cleanup(cluster.Close)
+
+	producer, err := kgo.NewClient(kgo.SeedBrokers(cluster.ListenAddrs()...))
+	assert.NoError(t, err)
+	defer producer.Close()
+
+	for _, message := range messages {
+		result := producer.ProduceSync(context.Background(), &kgo.Record{Topic: topic, Value: []byte(message)})
+		assert.NoError(t, result.FirstErr())
+	}
+
+	return cluster.ListenAddrs()
+}
+
+func newKafkaQueueService(t *testing.T, brokers []string, topic string) *queue.KafkaQueueService {
+	queueClient, err := queue.NewKafkaClient(queue.KafkaConfig{
+		Brokers:  brokers,
+		Topic:    topic,
+		Group:    "go-consumer-app",
+		Parallel: 10,
+		Timeout:  2000,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(queueClient.Close)
+
+	return queueClient
+}
+
+func committedOffset(t *testing.T, brokers []string, topic string) int64 {
+	admin, err := kgo.NewClient(kgo.SeedBrokers(brokers...))
+	assert.NoError(t, err)
+	defer admin.Close()
+
+	offsets, err := kadm.NewClient(admin).FetchOffsets(context.Background(), "go-consumer-app")
+	assert.NoError(t, err)
+
+	offset, found := offsets.Lookup(topic, 0)
+	if !found {
+		return -1
+	}
+
+	return offset.At
+}
+
+func TestNewKafkaClientErr(t *testing.T) {
+	queueClient, err := queue.NewKafkaClient(queue.KafkaConfig{
+		Brokers:  []string{"localhost:9092"},
+		Topic:    "orders",
+		Group:    "go-consumer-app",
+		Parallel: 0,
+		Timeout:  1000,
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, queueClient)
+}
+
+func TestKafkaQueueService_Receive(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders", "msg1", "msg2")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	actual, err := queueClient.Receive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "msg1", actual[0].String())
+	assert.Equal(t, "orders:0:0", actual[0].ReceiptHandle)
+	assert.True(t, actual[0].Raw)
+	assert.Equal(t, "msg2", actual[1].String())
+	assert.Equal(t, "orders:0:1", actual[1].ReceiptHandle)
+}
+
+func TestKafkaQueueService_ReceiveEmpty(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	actual, err := queueClient.Receive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestKafkaQueueService_DeleteOutOfOrder(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders", "msg1", "msg2", "msg3")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+
+	// offset 0 still in flight, nothing can be committed
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[2].ReceiptHandle))
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[1].ReceiptHandle))
+	assert.Equal(t, int64(-1), committedOffset(t, brokers, "orders"))
+
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[0].ReceiptHandle))
+	assert.Equal(t, int64(3), committedOffset(t, brokers, "orders"))
+}
+
+func TestKafkaQueueService_DeleteGap(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders", "msg1", "msg2", "msg3")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[0].ReceiptHandle))
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[2].ReceiptHandle))
+	assert.Equal(t, int64(1), committedOffset(t, brokers, "orders"))
+}
+
+func TestKafkaQueueService_Nack(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders", "msg1", "msg2")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, 1, messages[0].ReceiveCount)
+
+	// the failed record does not stop the commits of the partition
+	assert.NoError(t, queueClient.Nack(context.Background(), messages[0].ReceiptHandle, time.Minute))
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[1].ReceiptHandle))
+	assert.Equal(t, int64(2), committedOffset(t, brokers, "orders"))
+
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, redelivered, 1)
+	assert.Equal(t, "msg1", redelivered[0].Body)
+	assert.Equal(t, "orders:0:2", redelivered[0].ReceiptHandle)
+	assert.Equal(t, 2, redelivered[0].ReceiveCount)
+	assert.Equal(t, "orders-0-2", redelivered[0].MessageID)
+	assert.Equal(t, map[string]string{queue.OriginalMessageIDAttribute: messages[0].MessageID}, redelivered[0].Attributes)
+	assert.Equal(t, messages[0].MessageID, redelivered[0].OriginalID())
+
+	assert.NoError(t, queueClient.Delete(context.Background(), redelivered[0].ReceiptHandle))
+	assert.Equal(t, int64(3), committedOffset(t, brokers, "orders"))
+}
+
+func TestKafkaQueueService_NackErr(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders", "msg1")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[0].ReceiptHandle))
+
+	assert.Error(t, queueClient.Nack(context.Background(), messages[0].ReceiptHandle, 0))
+	assert.Error(t, queueClient.Nack(context.Background(), "invalid receipt", 0))
+}
+
+func TestKafkaQueueService_DeleteErr(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	err := queueClient.Delete(context.Background(), "invalid receipt")
+	assert.Error(t, err)
+
+	err = queueClient.Delete(context.Background(), "orders:a:b")
+	assert.Error(t, err)
+
+	err = queueClient.Delete(context.Background(), "orders:3:0")
+	assert.Error(t, err)
+}
+
+func TestKafkaQueueService_Count(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders", "msg1", "msg2", "msg3")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[0].ReceiptHandle))
+
+	assert.Eventually(t, func() bool {
+		count, countErr := queueClient.Count(context.Background())
+		return countErr == nil && aws.ToInt(count) == 2
+	}, time.Second*5, time.Millisecond*100, fmt.Sprintf("lag for %s", "orders"))
+}
+
+func TestKafkaQueueService_SendBatch(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	results, err := queueClient.SendBatch(context.Background(), []queue.SendMessageDTO{
+		{Body: "msg1", Attributes: map[string]string{"event": "created"}},
+		{Body: "msg2"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []queue.SendResultDTO{{MessageID: "orders-0-0"}, {MessageID: "orders-0-1"}}, results)
+
+	actual, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "msg1", actual[0].String())
+	assert.Equal(t, "created", actual[0].Attributes["event"])
+}
+
+func TestKafkaQueueService_SendOnly(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders")
+	sender, err := queue.NewKafkaClient(queue.KafkaConfig{
+		Brokers:  brokers,
+		Topic:    "orders",
+		Group:    "go-consumer-app",
+		Parallel: 10,
+		Timeout:  2000,
+		SendOnly: true,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(sender.Close)
+
+	messageID, err := sender.Send(context.Background(), queue.SendMessageDTO{Body: "msg1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "orders-0-0", messageID)
+
+	_, err = sender.Receive(context.Background())
+	assert.ErrorIs(t, err, queue.ErrSendOnly)
+
+	// the producer does not join the consumer group
+	admin, err := kgo.NewClient(kgo.SeedBrokers(brokers...))
+	assert.NoError(t, err)
+	defer admin.Close()
+
+	groups, err := kadm.NewClient(admin).ListGroups(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, groups.Groups())
+}
+
+func TestKafkaQueueService_SendDelayErr(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	_, err := queueClient.Send(context.Background(), queue.SendMessageDTO{Body: "msg1", Delay: time.Second})
+
+	assert.ErrorIs(t, err, queue.ErrDelayNotSupported)
+}
+
+func TestKafkaQueueService_Peek(t *testing.T) {
+	brokers := newKafkaCluster(t, "orders", "msg1", "msg2", "msg3")
+	queueClient := newKafkaQueueService(t, brokers, "orders")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[0].ReceiptHandle))
+
+	actual, err := queueClient.Peek(context.Background(), 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "orders-0-1", actual[0].MessageID)
+	assert.Equal(t, "msg2", actual[0].String())
+	assert.Empty(t, actual[0].ReceiptHandle)
+	assert.Equal(t, int64(1), committedOffset(t, brokers, "orders"))
+}
@@ -0,0 +1,391 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/google/uuid"
+	"github.com/src/main/app/log"
+)
+
+// MemoryBroker holds the in-memory queues by name, so a consumer and its dead letter queue share state.
+type MemoryBroker struct {
+	mutex  sync.Mutex
+	queues map[string]*MemoryQueue
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		queues: make(map[string]*MemoryQueue),
+	}
+}
+
+// GetOrCreate returns the queue by name, it is created with attributes only the first time.
+func (b *MemoryBroker) GetOrCreate(name string, attributes MemoryQueueAttributes) *MemoryQueue {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if memoryQueue, found := b.queues[name]; found {
+		return memoryQueue
+	}
+
+	memoryQueue := &MemoryQueue{
+		Name:       name,
+		attributes: attributes,
+	}
+	b.queues[name] = memoryQueue
+
+	return memoryQueue
+}
+
+// Configure creates the queue, or updates its attributes when it was created first as another queue's dead letter
+// queue, so the configured attributes do not depend on the initialization order.
+func (b *MemoryBroker) Configure(name string, attributes MemoryQueueAttributes) *MemoryQueue {
+	memoryQueue := b.GetOrCreate(name, attributes)
+
+	memoryQueue.mutex.Lock()
+	defer memoryQueue.mutex.Unlock()
+	memoryQueue.attributes = attributes
+
+	return memoryQueue
+}
+
+func (b *MemoryBroker) Get(name string) (*MemoryQueue, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	memoryQueue, found := b.queues[name]
+	return memoryQueue, found
+}
+
+// Names returns the queue names in alphabetical order.
+func (b *MemoryBroker) Names() []string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	names := make([]string, 0, len(b.queues))
+	for name := range b.queues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+type MemoryQueueAttributes struct {
+	VisibilityTimeout time.Duration
+	Delay             time.Duration
+	MaxReceiveCount   int
+	DeadLetterQueue   *MemoryQueue
+}
+
+// MemoryQueueStats counts the messages by state, as the sqs approximate number attributes.
+type MemoryQueueStats struct {
+	Visible  int
+	InFlight int
+	Delayed  int
+}
+
+type memoryMessage struct {
+	id            string
+	body          string
+	attributes    map[string]string
+	receiptHandle string
+	receiveCount  int
+	visibleAt     time.Time
+}
+
+// MemoryQueue mimics sqs semantics: visibility timeout, receive count, delay and redrive to a dead letter queue.
+type MemoryQueue struct {
+	Name       string
+	mutex      sync.Mutex
+	attributes MemoryQueueAttributes
+	messages   []*memoryMessage
+}
+
+func (q *MemoryQueue) Send(message SendMessageDTO) string {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	delay := q.attributes.Delay
+	if message.Delay > 0 {
+		delay = message.Delay
+	}
+
+	memoryMessage := &memoryMessage{
+		id:         uuid.NewString(),
+		body:       message.Body,
+		attributes: message.Attributes,
+		visibleAt:  time.Now().Add(delay),
+	}
+	q.messages = append(q.messages, memoryMessage)
+
+	return memoryMessage.id
+}
+
+// ReceiveWait long polls the queue until a message is visible, the wait expires or ctx is done.
+func (q *MemoryQueue) ReceiveWait(ctx context.Context, maxMsg int, visibilityTimeout time.Duration, wait time.Duration) []MessageDTO {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	for {
+		messages := q.Receive(maxMsg, visibilityTimeout)
+		if len(messages) > 0 {
+			return messages
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Millisecond * 20):
+		}
+	}
+}
+
+// Receive returns up to maxMsg visible messages, the ones over the max receive count are moved to the dead letter queue.
+func (q *MemoryQueue) Receive(maxMsg int, visibilityTimeout time.Duration) []MessageDTO {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := time.Now()
+	var messages []MessageDTO
+	var redrive []*memoryMessage
+
+	for _, memoryMessage := range q.messages {
+		if len(messages) == maxMsg {
+			break
+		}
+
+		if now.Before(memoryMessage.visibleAt) {
+			continue
+		}
+
+		if q.attributes.DeadLetterQueue != nil && q.attributes.MaxReceiveCount > 0 &&
+			memoryMessage.receiveCount >= q.attributes.MaxReceiveCount {
+			redrive = append(redrive, memoryMessage)
+			continue
+		}
+
+		memoryMessage.receiveCount++
+		memoryMessage.receiptHandle = uuid.NewString()
+		memoryMessage.visibleAt = now.Add(visibilityTimeout)
+
+		messages = append(messages, MessageDTO{
+			MessageID:     memoryMessage.id,
+			Body:          memoryMessage.body,
+			ReceiptHandle: memoryMessage.receiptHandle,
+			ReceiveCount:  memoryMessage.receiveCount,
+			Attributes:    memoryMessage.attributes,
+		})
+	}
+
+	for _, memoryMessage := range redrive {
+		q.remove(memoryMessage)
+		q.attributes.DeadLetterQueue.Send(SendMessageDTO{Body: memoryMessage.body, Attributes: memoryMessage.attributes})
+		log.Warnf("message %s moved to dead letter queue %s after %d receives",
+			memoryMessage.id, q.attributes.DeadLetterQueue.Name, memoryMessage.receiveCount)
+	}
+
+	return messages
+}
+
+// Peek returns up to maxMsg visible messages without receiving them.
+func (q *MemoryQueue) Peek(maxMsg int) []MessageDTO {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := time.Now()
+	var messages []MessageDTO
+	for _, memoryMessage := range q.messages {
+		if len(messages) == maxMsg {
+			break
+		}
+
+		if now.Before(memoryMessage.visibleAt) {
+			continue
+		}
+
+		messages = append(messages, MessageDTO{
+			MessageID:    memoryMessage.id,
+			Body:         memoryMessage.body,
+			ReceiveCount: memoryMessage.receiveCount,
+			Attributes:   memoryMessage.attributes,
+		})
+	}
+
+	return messages
+}
+
+func (q *MemoryQueue) Delete(receiptHandle string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	memoryMessage := q.find(receiptHandle)
+	if memoryMessage == nil {
+		return fmt.Errorf("not found %s", receiptHandle)
+	}
+
+	q.remove(memoryMessage)
+	return nil
+}
+
+// ChangeVisibility makes an in flight message visible again after timeout.
+func (q *MemoryQueue) ChangeVisibility(receiptHandle string, timeout time.Duration) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	memoryMessage := q.find(receiptHandle)
+	if memoryMessage == nil {
+		return fmt.Errorf("not found %s", receiptHandle)
+	}
+
+	memoryMessage.visibleAt = time.Now().Add(timeout)
+	return nil
+}
+
+func (q *MemoryQueue) Len() int {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return len(q.messages)
+}
+
+func (q *MemoryQueue) Stats() MemoryQueueStats {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := time.Now()
+	stats := MemoryQueueStats{}
+	for _, memoryMessage := range q.messages {
+		switch {
+		case !now.Before(memoryMessage.visibleAt):
+			stats.Visible++
+		case memoryMessage.receiptHandle != "":
+			stats.InFlight++
+		default:
+			stats.Delayed++
+		}
+	}
+
+	return stats
+}
+
+func (q *MemoryQueue) Attributes() MemoryQueueAttributes {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.attributes
+}
+
+func (q *MemoryQueue) Purge() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.messages = nil
+}
+
+func (q *MemoryQueue) find(receiptHandle string) *memoryMessage {
+	for _, memoryMessage := range q.messages {
+		if memoryMessage.receiptHandle != "" && memoryMessage.receiptHandle == receiptHandle {
+			return memoryMessage
+		}
+	}
+	return nil
+}
+
+func (q *MemoryQueue) remove(target *memoryMessage) {
+	for i, memoryMessage := range q.messages {
+		if memoryMessage == target {
+			q.messages = append(q.messages[:i], q.messages[i+1:]...)
+			return
+		}
+	}
+}
+
+type MemoryQueueService struct {
+	Timeout time.Duration
+	MaxMsg  int
+	queue   *MemoryQueue
+}
+
+type MemoryConfig struct {
+	Name              string
+	Parallel          int
+	Timeout           int
+	VisibilityTimeout int
+	Delay             int
+	MaxReceiveCount   int
+	DeadLetterQueue   string
+}
+
+func NewMemoryClient(config MemoryConfig, broker *MemoryBroker) (*MemoryQueueService, error) {
+	if config.Parallel < 1 || config.Parallel > 10 {
+		log.Errorf("receive argument: parallel valid values: 1 to 10: given %d", config.Parallel)
+		return nil, errors.New("invalidad parallel value")
+	}
+
+	attributes := MemoryQueueAttributes{
+		VisibilityTimeout: time.Millisecond * time.Duration(config.VisibilityTimeout),
+		Delay:             time.Millisecond * time.Duration(config.Delay),
+		MaxReceiveCount:   config.MaxReceiveCount,
+	}
+
+	if config.DeadLetterQueue != "" {
+		attributes.DeadLetterQueue = broker.GetOrCreate(config.DeadLetterQueue, MemoryQueueAttributes{})
+	}
+
+	return &MemoryQueueService{
+		Timeout: time.Millisecond * time.Duration(config.Timeout),
+		MaxMsg:  config.Parallel,
+		queue:   broker.Configure(config.Name, attributes),
+	}, nil
+}
+
+// Receive long polls the queue until a message is visible or the timeout expires.
+func (s MemoryQueueService) Receive(ctx context.Context) ([]MessageDTO, error) {
+	return s.queue.ReceiveWait(ctx, s.MaxMsg, s.queue.Attributes().VisibilityTimeout, s.Timeout), nil
+}
+
+// Peek has no visibility impact, the receive count of the messages is not increased.
+func (s MemoryQueueService) Peek(_ context.Context, maxMsg int) ([]MessageDTO, error) {
+	return s.queue.Peek(maxMsg), nil
+}
+
+func (s MemoryQueueService) Delete(_ context.Context, receiptHandle string) error {
+	if err := s.queue.Delete(receiptHandle); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Nack changes the visibility of the message, so it is received again after delay.
+// A zero delay keeps the visibility timeout, as a failed sqs message.
+func (s MemoryQueueService) Nack(_ context.Context, receiptHandle string, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	if err := s.queue.ChangeVisibility(receiptHandle, delay); err != nil {
+		return fmt.Errorf("nack: %w", err)
+	}
+
+	return nil
+}
+
+func (s MemoryQueueService) Count(_ context.Context) (*int, error) {
+	return aws.Int(s.queue.Len()), nil
+}
+
+func (s MemoryQueueService) Send(_ context.Context, message SendMessageDTO) (string, error) {
+	return s.queue.Send(message), nil
+}
+
+func (s MemoryQueueService) SendBatch(ctx context.Context, messages []SendMessageDTO) ([]SendResultDTO, error) {
+	return sendEach(ctx, s.Send, messages), nil
+}
@@ -0,0 +1,259 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMemoryQueueService(t *testing.T, broker *queue.MemoryBroker, config queue.MemoryConfig) *queue.MemoryQueueService {
+	config.Name = "orders-consumer"
+	config.Parallel = 10
+	config.Timeout = 100
+
+	queueClient, err := queue.NewMemoryClient(config, broker)
+	assert.NoError(t, err)
+
+	return queueClient
+}
+
+func sendMemory(t *testing.T, queueClient *queue.MemoryQueueService, messages ...string) {
+	for _, message := range messages {
+		_, err := queueClient.Send(context.Background(), queue.SendMessageDTO{Body: message})
+		assert.NoError(t, err)
+	}
+}
+
+func TestNewMemoryClientErr(t *testing.T) {
+	queueClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name:     "orders-consumer",
+		Parallel: 11,
+	}, queue.NewMemoryBroker())
+
+	assert.Error(t, err)
+	assert.Nil(t, queueClient)
+}
+
+func TestMemoryQueueService_Receive(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{VisibilityTimeout: 30000})
+	sendMemory(t, queueClient, "msg1", "msg2")
+
+	actual, err := queueClient.Receive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "msg1", actual[0].String())
+	assert.Equal(t, "msg2", actual[1].String())
+	assert.NotEmpty(t, actual[0].MessageID)
+	assert.NotEmpty(t, actual[0].ReceiptHandle)
+	assert.Equal(t, 1, actual[0].ReceiveCount)
+}
+
+func TestMemoryQueueService_ReceiveEmpty(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{})
+
+	actual, err := queueClient.Receive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestMemoryQueueService_VisibilityTimeout(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{VisibilityTimeout: 200})
+	sendMemory(t, queueClient, "msg1")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	invisible, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, invisible)
+
+	time.Sleep(time.Millisecond * 200)
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, redelivered, 1)
+	assert.Equal(t, 2, redelivered[0].ReceiveCount)
+	assert.NotEqual(t, messages[0].ReceiptHandle, redelivered[0].ReceiptHandle)
+}
+
+func TestMemoryQueueService_Delay(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{Delay: 300})
+	sendMemory(t, queueClient, "msg1")
+
+	delayed, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, delayed)
+
+	time.Sleep(time.Millisecond * 200)
+	actual, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+}
+
+func TestMemoryQueueService_Delete(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{VisibilityTimeout: 30000})
+	sendMemory(t, queueClient, "msg1")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	err = queueClient.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.NoError(t, err)
+
+	err = queueClient.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.Error(t, err)
+}
+
+func TestMemoryQueueService_DeleteStaleReceiptHandle(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{VisibilityTimeout: 50})
+	sendMemory(t, queueClient, "msg1")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	time.Sleep(time.Millisecond * 50)
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, redelivered, 1)
+
+	err = queueClient.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.Error(t, err)
+}
+
+func TestMemoryQueueService_Nack(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{VisibilityTimeout: 30000})
+	sendMemory(t, queueClient, "msg1")
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	err = queueClient.Nack(context.Background(), messages[0].ReceiptHandle, time.Millisecond)
+	assert.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 10)
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, redelivered, 1)
+
+	err = queueClient.Nack(context.Background(), messages[0].ReceiptHandle, time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestMemoryQueueService_DeadLetterQueue(t *testing.T) {
+	broker := queue.NewMemoryBroker()
+	queueClient := newMemoryQueueService(t, broker, queue.MemoryConfig{
+		VisibilityTimeout: 10,
+		MaxReceiveCount:   2,
+		DeadLetterQueue:   "orders-consumer-dlq",
+	})
+	sendMemory(t, queueClient, "msg1")
+
+	for i := 1; i <= 2; i++ {
+		messages, err := queueClient.Receive(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, i, messages[0].ReceiveCount)
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	actual, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+
+	dlq, found := broker.Get("orders-consumer-dlq")
+	assert.True(t, found)
+	assert.Equal(t, 1, dlq.Len())
+
+	dlqMessages := dlq.Receive(10, time.Second)
+	assert.Len(t, dlqMessages, 1)
+	assert.Equal(t, "msg1", dlqMessages[0].String())
+}
+
+func TestMemoryQueueService_DeadLetterQueueConfiguredLater(t *testing.T) {
+	broker := queue.NewMemoryBroker()
+	newMemoryQueueService(t, broker, queue.MemoryConfig{
+		MaxReceiveCount: 2,
+		DeadLetterQueue: "orders-consumer-dlq",
+	})
+
+	dlqClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+		Name:              "orders-consumer-dlq",
+		Parallel:          10,
+		VisibilityTimeout: 5000,
+		Delay:             100,
+	}, broker)
+	assert.NoError(t, err)
+	sendMemory(t, dlqClient, "msg1")
+
+	dlq, found := broker.Get("orders-consumer-dlq")
+	assert.True(t, found)
+	assert.Equal(t, time.Second*5, dlq.Attributes().VisibilityTimeout)
+	assert.Equal(t, 1, dlq.Stats().Delayed)
+}
+
+func TestMemoryQueueService_SharedByName(t *testing.T) {
+	broker := queue.NewMemoryBroker()
+	producer := newMemoryQueueService(t, broker, queue.MemoryConfig{})
+	consumer := newMemoryQueueService(t, broker, queue.MemoryConfig{})
+	sendMemory(t, producer, "msg1")
+
+	actual, err := consumer.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+}
+
+func TestMemoryQueueService_Count(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{})
+	sendMemory(t, queueClient, "msg1", "msg2", "msg3")
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, aws.ToInt(count))
+}
+
+func TestMemoryQueueService_SendBatch(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{Name: "orders"})
+
+	results, err := queueClient.SendBatch(context.Background(), []queue.SendMessageDTO{
+		{Body: "msg1", Attributes: map[string]string{"event": "created"}},
+		{Body: "msg2", Delay: time.Hour},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.NotEmpty(t, results[0].MessageID)
+	assert.NoError(t, results[1].Err)
+
+	actual, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, results[0].MessageID, actual[0].MessageID)
+	assert.Equal(t, map[string]string{"event": "created"}, actual[0].Attributes)
+}
+
+func TestMemoryQueueService_Peek(t *testing.T) {
+	queueClient := newMemoryQueueService(t, queue.NewMemoryBroker(), queue.MemoryConfig{VisibilityTimeout: 30000})
+	sendMemory(t, queueClient, "msg1", "msg2", "msg3")
+
+	actual, err := queueClient.Peek(context.Background(), 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "msg1", actual[0].String())
+	assert.Empty(t, actual[0].ReceiptHandle)
+	assert.Equal(t, 0, actual[0].ReceiveCount)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+	assert.Equal(t, 1, messages[0].ReceiveCount)
+}
@@ -0,0 +1,351 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/src/main/app/log"
+)
+
+type NATSQueueService struct {
+	Timeout  time.Duration
+	AckWait  time.Duration
+	NakDelay time.Duration
+	// MaxInProgress stops the in-progress acks of a message, so a stuck push is redelivered after an ack wait.
+	MaxInProgress time.Duration
+	MaxMsg        int
+	// PublishSubject is the subject of the sent messages, it can not have wildcards.
+	PublishSubject string
+	conn           *nats.Conn
+	js             jetstream.JetStream
+	consumer       jetstream.Consumer
+	inFlight       *sync.Map
+}
+
+type NATSConfig struct {
+	URL            string
+	Stream         string
+	Consumer       string
+	Subject        string
+	PublishSubject string
+	Parallel       int
+	Timeout        int
+	AckWait        int
+	NakDelay       int
+	// MaxInProgress in ms, 10 ack waits by default.
+	MaxInProgress int
+	// SendOnly publishes without durable consumer, for the streams the app only sends to.
+	SendOnly bool
+}
+
+// natsInFlight is a received message waiting for ack or nak, done stops its in-progress heartbeat.
+type natsInFlight struct {
+	msg  jetstream.Msg
+	done chan struct{}
+}
+
+func NewNATSClient(config NATSConfig) (*NATSQueueService, error) {
+	if config.Parallel < 1 {
+		log.Errorf("receive argument: parallel must be greater than 0: given %d", config.Parallel)
+		return nil, errors.New("invalidad parallel value")
+	}
+
+	conn, err := nats.Connect(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("nats connect: %w", err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("jetstream: %w", err)
+	}
+
+	ackWait := time.Millisecond * time.Duration(config.AckWait)
+	maxInProgress := time.Millisecond * time.Duration(config.MaxInProgress)
+	if maxInProgress <= 0 {
+		maxInProgress = ackWait * 10
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*time.Duration(config.Timeout))
+	defer cancel()
+
+	var consumer jetstream.Consumer
+	if !config.SendOnly {
+		consumer, err = js.CreateOrUpdateConsumer(ctx, config.Stream, jetstream.ConsumerConfig{
+			Durable:       config.Consumer,
+			FilterSubject: config.Subject,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       ackWait,
+		})
+	}
+
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("jetstream consumer %s on stream %s: %w", config.Consumer, config.Stream, err)
+	}
+
+	publishSubject := config.PublishSubject
+	if publishSubject == "" {
+		publishSubject = config.Subject
+	}
+
+	return &NATSQueueService{
+		Timeout:        time.Millisecond * time.Duration(config.Timeout),
+		AckWait:        ackWait,
+		NakDelay:       time.Millisecond * time.Duration(config.NakDelay),
+		MaxInProgress:  maxInProgress,
+		MaxMsg:         config.Parallel,
+		PublishSubject: publishSubject,
+		conn:           conn,
+		js:             js,
+		consumer:       consumer,
+		inFlight:       new(sync.Map),
+	}, nil
+}
+
+func (s NATSQueueService) Receive(_ context.Context) ([]MessageDTO, error) {
+	if s.consumer == nil {
+		return nil, fmt.Errorf("receive: %w", ErrSendOnly)
+	}
+
+	batch, err := s.consumer.Fetch(s.MaxMsg, jetstream.FetchMaxWait(s.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("receive: %w", err)
+	}
+
+	var messages []MessageDTO
+	for msg := range batch.Messages() {
+		metadata, metadataErr := msg.Metadata()
+		if metadataErr != nil {
+			log.Warnf("receive: invalid jetstream message on subject %s: %s", msg.Subject(), metadataErr)
+			continue
+		}
+
+		messageDTO := new(MessageDTO)
+		messageDTO.MessageID = fmt.Sprintf("%s-%d", metadata.Stream, metadata.Sequence.Stream)
+		messageDTO.Body = string(msg.Data())
+		messageDTO.ReceiptHandle = msg.Reply()
+		messageDTO.ReceiveCount = int(metadata.NumDelivered)
+		messageDTO.Attributes = fromNATSHeaders(msg.Headers())
+		messages = append(messages, *messageDTO)
+
+		s.track(msg)
+	}
+
+	// the fetched messages are in flight, they are processed and the error of the rest of the batch only logged
+	if batch.Error() != nil && !errors.Is(batch.Error(), nats.ErrTimeout) {
+		if len(messages) == 0 {
+			return nil, fmt.Errorf("receive: %w", batch.Error())
+		}
+		log.Warnf("receive: %d messages fetched: %s", len(messages), batch.Error())
+	}
+
+	return messages, nil
+}
+
+// Send publishes the message to the publish subject, attributes are sent as headers.
+func (s NATSQueueService) Send(ctx context.Context, message SendMessageDTO) (string, error) {
+	if message.Delay > 0 {
+		return "", fmt.Errorf("send: %w", ErrDelayNotSupported)
+	}
+
+	if s.PublishSubject == "" || strings.ContainsAny(s.PublishSubject, "*>") {
+		return "", fmt.Errorf("send: invalid publish subject %q", s.PublishSubject)
+	}
+
+	msg := nats.NewMsg(s.PublishSubject)
+	msg.Data = []byte(message.Body)
+	for name, value := range message.Attributes {
+		msg.Header.Set(name, value)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	pubAck, err := s.js.PublishMsg(ctx, msg)
+	if err != nil {
+		return "", fmt.Errorf("send: %w", err)
+	}
+
+	return fmt.Sprintf("%s-%d", pubAck.Stream, pubAck.Sequence), nil
+}
+
+func (s NATSQueueService) SendBatch(ctx context.Context, messages []SendMessageDTO) ([]SendResultDTO, error) {
+	return sendEach(ctx, s.Send, messages), nil
+}
+
+func (s NATSQueueService) Delete(_ context.Context, receiptHandle string) error {
+	msg, err := s.untrack(receiptHandle)
+	if err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	if err = msg.Ack(); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Nack hands the message back to the stream, the redelivery waits for delay or the configured nak delay.
+func (s NATSQueueService) Nack(_ context.Context, receiptHandle string, delay time.Duration) error {
+	msg, err := s.untrack(receiptHandle)
+	if err != nil {
+		return fmt.Errorf("nack: %w", err)
+	}
+
+	if delay <= 0 {
+		delay = s.NakDelay
+	}
+
+	if err = msg.NakWithDelay(delay); err != nil {
+		return fmt.Errorf("nack: %w", err)
+	}
+
+	return nil
+}
+
+// Peek reads the messages after the ack floor of the consumer with an ordered consumer, the state of the
+// durable consumer is not changed.
+func (s NATSQueueService) Peek(ctx context.Context, maxMsg int) ([]MessageDTO, error) {
+	if s.consumer == nil {
+		return nil, fmt.Errorf("peek: %w", ErrSendOnly)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	info, err := s.consumer.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("peek: consumer info: %w", err)
+	}
+
+	if info.NumPending == 0 && info.NumAckPending == 0 {
+		return nil, nil
+	}
+
+	var filterSubjects []string
+	if info.Config.FilterSubject != "" {
+		filterSubjects = []string{info.Config.FilterSubject}
+	}
+
+	consumer, err := s.js.OrderedConsumer(ctx, info.Stream, jetstream.OrderedConsumerConfig{
+		FilterSubjects: filterSubjects,
+		DeliverPolicy:  jetstream.DeliverByStartSequencePolicy,
+		OptStartSeq:    info.AckFloor.Stream + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("peek: ordered consumer: %w", err)
+	}
+
+	batch, err := consumer.Fetch(maxMsg, jetstream.FetchMaxWait(s.Timeout))
+	if err != nil {
+		return nil, fmt.Errorf("peek: %w", err)
+	}
+
+	var messages []MessageDTO
+	for msg := range batch.Messages() {
+		messageDTO := MessageDTO{
+			Body:       string(msg.Data()),
+			Attributes: fromNATSHeaders(msg.Headers()),
+		}
+		if metadata, metadataErr := msg.Metadata(); metadataErr == nil {
+			messageDTO.MessageID = fmt.Sprintf("%s-%d", metadata.Stream, metadata.Sequence.Stream)
+		}
+		messages = append(messages, messageDTO)
+	}
+
+	return messages, nil
+}
+
+// Count reports the messages of the stream not yet delivered to the consumer.
+func (s NATSQueueService) Count(ctx context.Context) (*int, error) {
+	if s.consumer == nil {
+		return nil, fmt.Errorf("consumer info error: %w", ErrSendOnly)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	info, err := s.consumer.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("consumer info error: %w", err)
+	}
+
+	return aws.Int(int(info.NumPending)), nil
+}
+
+// Close stops the in-progress acks of the messages in flight before closing the connection.
+func (s NATSQueueService) Close() {
+	s.inFlight.Range(func(receiptHandle, _ any) bool {
+		_, _ = s.untrack(receiptHandle.(string))
+		return true
+	})
+	s.conn.Close()
+}
+
+// track keeps the message in flight, sending in-progress acks each half ack wait so a long push is not redelivered.
+// After MaxInProgress the message is dropped from the in flight ones and the stream redelivers it.
+func (s NATSQueueService) track(msg jetstream.Msg) {
+	inFlight := &natsInFlight{msg: msg, done: make(chan struct{})}
+	s.inFlight.Store(msg.Reply(), inFlight)
+
+	if s.AckWait <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.AckWait / 2)
+		defer ticker.Stop()
+		expired := time.NewTimer(s.MaxInProgress)
+		defer expired.Stop()
+		for {
+			select {
+			case <-inFlight.done:
+				return
+			case <-expired.C:
+				if s.inFlight.CompareAndDelete(msg.Reply(), inFlight) {
+					log.Warnf("message on subject %s in progress for more than %s, it will be redelivered",
+						msg.Subject(), s.MaxInProgress)
+				}
+				return
+			case <-ticker.C:
+				if err := msg.InProgress(); err != nil {
+					log.Warnf("in progress ack error: %s", err)
+				}
+			}
+		}
+	}()
+}
+
+func (s NATSQueueService) untrack(receiptHandle string) (jetstream.Msg, error) {
+	value, found := s.inFlight.LoadAndDelete(receiptHandle)
+	if !found {
+		return nil, fmt.Errorf("not found %s", receiptHandle)
+	}
+
+	inFlight := value.(*natsInFlight)
+	close(inFlight.done)
+
+	return inFlight.msg, nil
+}
+
+func fromNATSHeaders(headers nats.Header) map[string]string {
+	if len(headers) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(headers))
+	for name := range headers {
+		attributes[name] = headers.Get(name)
+	}
+
+	return attributes
+}
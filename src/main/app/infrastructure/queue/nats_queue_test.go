@@ -0,0 +1,306 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func newNATSServer(t *testing.T, messages ...string) string {
+	natsServer, err := server.NewServer(&server.Options{
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	assert.NoError(t, err)
+
+	go natsServer.Start()
+	t.Cleanup(natsServer.Shutdown)
+	assert.True(t, natsServer.ReadyForConnections(time.Second*5))
+
+	conn, err := nats.Connect(natsServer.ClientURL())
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	assert.NoError(t, err)
+
+	_, err = js.CreateStream(context.Background(), jetstream.StreamConfig{
+		Name:     "ORDERS",
+		Subjects: []string{"orders.>"},
+	})
+	assert.NoError(t, err)
+
+	for _, message := range messages {
+		_, err = js.Publish(context.Background(), "orders.created", []byte(message))
+		assert.NoError(t, err)
+	}
+
+	return natsServer.ClientURL()
+}
+
+func newNATSQueueService(t *testing.T, url string, ackWait int) *queue.NATSQueueService {
+	queueClient, err := queue.NewNATSClient(queue.NATSConfig{
+		URL:      url,
+		Stream:   "ORDERS",
+		Consumer: "go-consumer-app",
+		Parallel: 10,
+		Timeout:  500,
+		AckWait:  ackWait,
+		NakDelay: 100,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(queueClient.Close)
+
+	return queueClient
+}
+
+func TestNewNATSClientErr(t *testing.T) {
+	queueClient, err := queue.NewNATSClient(queue.NATSConfig{
+		URL:      nats.DefaultURL,
+		Parallel: 0,
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, queueClient)
+}
+
+func TestNewNATSClientMissingStream(t *testing.T) {
+	url := newNATSServer(t)
+	queueClient, err := queue.NewNATSClient(queue.NATSConfig{
+		URL:      url,
+		Stream:   "MISSING",
+		Consumer: "go-consumer-app",
+		Parallel: 10,
+		Timeout:  500,
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, queueClient)
+}
+
+func TestNATSQueueService_Receive(t *testing.T) {
+	url := newNATSServer(t, "msg1", "msg2")
+	queueClient := newNATSQueueService(t, url, 30000)
+
+	actual, err := queueClient.Receive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "msg1", actual[0].String())
+	assert.Equal(t, "ORDERS-1", actual[0].MessageID)
+	assert.Equal(t, "msg2", actual[1].String())
+	assert.Equal(t, "ORDERS-2", actual[1].MessageID)
+}
+
+func TestNATSQueueService_ReceiveEmpty(t *testing.T) {
+	url := newNATSServer(t)
+	queueClient := newNATSQueueService(t, url, 30000)
+
+	actual, err := queueClient.Receive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestNATSQueueService_Delete(t *testing.T) {
+	url := newNATSServer(t, "msg1")
+	queueClient := newNATSQueueService(t, url, 30000)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	err = queueClient.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.NoError(t, err)
+
+	err = queueClient.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.Error(t, err)
+}
+
+func TestNATSQueueService_Nack(t *testing.T) {
+	url := newNATSServer(t, "msg1")
+	queueClient := newNATSQueueService(t, url, 30000)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	err = queueClient.Nack(context.Background(), messages[0].ReceiptHandle, 0)
+	assert.NoError(t, err)
+
+	err = queueClient.Nack(context.Background(), messages[0].ReceiptHandle, 0)
+	assert.Error(t, err)
+
+	time.Sleep(time.Millisecond * 200)
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, redelivered, 1)
+	assert.Equal(t, "msg1", redelivered[0].String())
+}
+
+func TestNATSQueueService_InProgress(t *testing.T) {
+	url := newNATSServer(t, "msg1")
+	queueClient := newNATSQueueService(t, url, 200)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	// a long push keeps the message in progress beyond the ack wait
+	time.Sleep(time.Millisecond * 500)
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, redelivered)
+
+	err = queueClient.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.NoError(t, err)
+}
+
+func TestNATSQueueService_MaxInProgress(t *testing.T) {
+	url := newNATSServer(t, "msg1")
+	queueClient, err := queue.NewNATSClient(queue.NATSConfig{
+		URL:           url,
+		Stream:        "ORDERS",
+		Consumer:      "go-consumer-app",
+		Parallel:      10,
+		Timeout:       500,
+		AckWait:       200,
+		MaxInProgress: 300,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(queueClient.Close)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	// a stuck push stops the in-progress acks, the message is redelivered after an ack wait
+	time.Sleep(time.Millisecond * 700)
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, redelivered, 1)
+	assert.Equal(t, 2, redelivered[0].ReceiveCount)
+
+	err = queueClient.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.Error(t, err)
+}
+
+func TestNATSQueueService_Count(t *testing.T) {
+	url := newNATSServer(t, "msg1", "msg2", "msg3")
+	queueClient := newNATSQueueService(t, url, 30000)
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, aws.ToInt(count))
+}
+
+func TestNATSQueueService_Send(t *testing.T) {
+	queueClient, err := queue.NewNATSClient(queue.NATSConfig{
+		URL:            newNATSServer(t),
+		Stream:         "ORDERS",
+		Consumer:       "go-consumer-app",
+		Parallel:       10,
+		Timeout:        500,
+		AckWait:        30000,
+		PublishSubject: "orders.created",
+	})
+	assert.NoError(t, err)
+	t.Cleanup(queueClient.Close)
+
+	results, err := queueClient.SendBatch(context.Background(), []queue.SendMessageDTO{
+		{Body: "msg1", Attributes: map[string]string{"event": "created"}},
+		{Body: "msg2", Delay: time.Second},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDERS-1", results[0].MessageID)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, queue.ErrDelayNotSupported)
+
+	actual, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, "msg1", actual[0].String())
+	assert.Equal(t, "created", actual[0].Attributes["event"])
+}
+
+func TestNATSQueueService_SendOnly(t *testing.T) {
+	url := newNATSServer(t)
+	sender, err := queue.NewNATSClient(queue.NATSConfig{
+		URL:            url,
+		Stream:         "ORDERS",
+		Consumer:       "go-consumer-app",
+		Parallel:       10,
+		Timeout:        500,
+		PublishSubject: "orders.created",
+		SendOnly:       true,
+	})
+	assert.NoError(t, err)
+	t.Cleanup(sender.Close)
+
+	messageID, err := sender.Send(context.Background(), queue.SendMessageDTO{Body: "msg1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDERS-1", messageID)
+
+	_, err = sender.Receive(context.Background())
+	assert.ErrorIs(t, err, queue.ErrSendOnly)
+
+	// no durable consumer is created on the stream
+	conn, err := nats.Connect(url)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	assert.NoError(t, err)
+
+	_, err = js.Consumer(context.Background(), "ORDERS", "go-consumer-app")
+	assert.ErrorIs(t, err, jetstream.ErrConsumerNotFound)
+}
+
+func TestNATSQueueService_SendWildcardErr(t *testing.T) {
+	queueClient := newNATSQueueService(t, newNATSServer(t), 30000)
+
+	_, err := queueClient.Send(context.Background(), queue.SendMessageDTO{Body: "msg1"})
+
+	assert.Error(t, err)
+}
+
+func TestNATSQueueService_Peek(t *testing.T) {
+	url := newNATSServer(t, "msg1", "msg2", "msg3")
+	queueClient := newNATSQueueService(t, url, 30000)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[0].ReceiptHandle))
+
+	// the ack is async, peek starts after the ack floor once it is processed
+	var actual []queue.MessageDTO
+	assert.Eventually(t, func() bool {
+		actual, err = queueClient.Peek(context.Background(), 10)
+		return err == nil && len(actual) == 2
+	}, time.Second*5, time.Millisecond*50)
+
+	assert.Equal(t, "ORDERS-2", actual[0].MessageID)
+	assert.Equal(t, "msg2", actual[0].String())
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, aws.ToInt(count))
+}
+
+func TestNATSQueueService_PeekEmpty(t *testing.T) {
+	queueClient := newNATSQueueService(t, newNATSServer(t), 30000)
+
+	actual, err := queueClient.Peek(context.Background(), 10)
+
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
@@ -0,0 +1,321 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	pubsub "cloud.google.com/go/pubsub/apiv1"
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/src/main/app/log"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// pubSubMaxAckDeadline is the max ack deadline of a pub/sub message.
+const pubSubMaxAckDeadline = time.Second * 600
+
+// BacklogReader reads the number of undelivered messages of a subscription, pub/sub itself does not expose it.
+type BacklogReader interface {
+	Backlog(ctx context.Context, project string, subscription string) (int, error)
+}
+
+type PubSubQueueService struct {
+	Timeout      time.Duration
+	Subscription string
+	// SubscriptionID is the short subscription name, Subscription is its full resource name.
+	SubscriptionID string
+	Project        string
+	MaxMsg         int
+	NackDelay      time.Duration
+	// Topic is the full resource name of the topic of the sent messages, empty when it is not configured.
+	Topic     string
+	client    *pubsub.SubscriberClient
+	publisher *pubsub.PublisherClient
+	backlog   BacklogReader
+}
+
+type PubSubConfig struct {
+	Project      string
+	Subscription string
+	Topic        string
+	Parallel     int
+	Timeout      int
+	NackDelay    int
+	Backlog      BacklogReader
+}
+
+func NewPubSubClient(config PubSubConfig, opts ...option.ClientOption) (*PubSubQueueService, error) {
+	if config.Parallel < 1 {
+		log.Errorf("receive argument: parallel must be greater than 0: given %d", config.Parallel)
+		return nil, errors.New("invalidad parallel value")
+	}
+
+	client, err := pubsub.NewSubscriberClient(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("pubsub client: %w", err)
+	}
+
+	publisher, err := pubsub.NewPublisherClient(context.Background(), opts...)
+	if err != nil {
+		if closeErr := client.Close(); closeErr != nil {
+			log.Warnf("pubsub client close error: %s", closeErr)
+		}
+		return nil, fmt.Errorf("pubsub publisher client: %w", err)
+	}
+
+	var topic string
+	if config.Topic != "" {
+		topic = fmt.Sprintf("projects/%s/topics/%s", config.Project, config.Topic)
+	}
+
+	return &PubSubQueueService{
+		Timeout:        time.Millisecond * time.Duration(config.Timeout),
+		Subscription:   fmt.Sprintf("projects/%s/subscriptions/%s", config.Project, config.Subscription),
+		SubscriptionID: config.Subscription,
+		Project:        config.Project,
+		MaxMsg:         config.Parallel,
+		NackDelay:      time.Millisecond * time.Duration(config.NackDelay),
+		Topic:          topic,
+		client:         client,
+		publisher:      publisher,
+		backlog:        config.Backlog,
+	}, nil
+}
+
+func (s PubSubQueueService) Receive(ctx context.Context) ([]MessageDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	response, err := s.client.Pull(ctx, &pubsubpb.PullRequest{
+		Subscription: s.Subscription,
+		MaxMessages:  int32(s.MaxMsg),
+	})
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("receive: %w", err)
+	}
+
+	if len(response.ReceivedMessages) == 0 {
+		return nil, nil
+	}
+
+	messages := make([]MessageDTO, len(response.ReceivedMessages))
+	for i, receivedMessage := range response.ReceivedMessages {
+		messageDTO := new(MessageDTO)
+		messageDTO.MessageID = receivedMessage.Message.MessageId
+		messageDTO.Body = string(receivedMessage.Message.Data)
+		messageDTO.ReceiptHandle = receivedMessage.AckId
+		messageDTO.ReceiveCount = int(receivedMessage.DeliveryAttempt)
+		messageDTO.Attributes = receivedMessage.Message.Attributes
+		messages[i] = *messageDTO
+	}
+
+	return messages, nil
+}
+
+// Peek pulls up to maxMsg messages and sets their ack deadline to zero, so they are redelivered right away.
+// The delivery attempt of the messages is increased.
+func (s PubSubQueueService) Peek(ctx context.Context, maxMsg int) ([]MessageDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	response, err := s.client.Pull(ctx, &pubsubpb.PullRequest{
+		Subscription: s.Subscription,
+		MaxMessages:  int32(maxMsg),
+	})
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("peek: %w", err)
+	}
+
+	if len(response.ReceivedMessages) == 0 {
+		return nil, nil
+	}
+
+	ackIDs := make([]string, len(response.ReceivedMessages))
+	messages := make([]MessageDTO, len(response.ReceivedMessages))
+	for i, receivedMessage := range response.ReceivedMessages {
+		ackIDs[i] = receivedMessage.AckId
+		messages[i] = MessageDTO{
+			MessageID:    receivedMessage.Message.MessageId,
+			Body:         string(receivedMessage.Message.Data),
+			ReceiveCount: int(receivedMessage.DeliveryAttempt),
+			Attributes:   receivedMessage.Message.Attributes,
+		}
+	}
+
+	if err = s.client.ModifyAckDeadline(ctx, &pubsubpb.ModifyAckDeadlineRequest{
+		Subscription:       s.Subscription,
+		AckIds:             ackIDs,
+		AckDeadlineSeconds: 0,
+	}); err != nil {
+		return nil, fmt.Errorf("peek: restore ack deadline: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (s PubSubQueueService) Send(ctx context.Context, message SendMessageDTO) (string, error) {
+	results, err := s.SendBatch(ctx, []SendMessageDTO{message})
+	if err != nil {
+		return "", err
+	}
+
+	return results[0].MessageID, results[0].Err
+}
+
+// SendBatch publishes the messages to the topic in one request.
+func (s PubSubQueueService) SendBatch(ctx context.Context, messages []SendMessageDTO) ([]SendResultDTO, error) {
+	if s.Topic == "" {
+		return nil, errors.New("send: topic not configured")
+	}
+
+	pubsubMessages := make([]*pubsubpb.PubsubMessage, len(messages))
+	for i, message := range messages {
+		if message.Delay > 0 {
+			return nil, fmt.Errorf("send: %w", ErrDelayNotSupported)
+		}
+		pubsubMessages[i] = &pubsubpb.PubsubMessage{
+			Data:       []byte(message.Body),
+			Attributes: message.Attributes,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	response, err := s.publisher.Publish(ctx, &pubsubpb.PublishRequest{
+		Topic:    s.Topic,
+		Messages: pubsubMessages,
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("send: %w", err)
+	}
+
+	results := make([]SendResultDTO, len(messages))
+	for i, messageID := range response.MessageIds {
+		results[i].MessageID = messageID
+	}
+
+	return results, nil
+}
+
+func (s PubSubQueueService) Delete(ctx context.Context, receiptHandle string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	if err := s.client.Acknowledge(ctx, &pubsubpb.AcknowledgeRequest{
+		Subscription: s.Subscription,
+		AckIds:       []string{receiptHandle},
+	}); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Nack modifies the ack deadline of the message to delay or the configured nack delay, clamped to 0 to 600 seconds.
+func (s PubSubQueueService) Nack(ctx context.Context, receiptHandle string, delay time.Duration) error {
+	if delay <= 0 {
+		delay = s.NackDelay
+	}
+	delay = min(max(delay, 0), pubSubMaxAckDeadline)
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	if err := s.client.ModifyAckDeadline(ctx, &pubsubpb.ModifyAckDeadlineRequest{
+		Subscription:       s.Subscription,
+		AckIds:             []string{receiptHandle},
+		AckDeadlineSeconds: int32(delay.Seconds()),
+	}); err != nil {
+		return fmt.Errorf("nack: %w", err)
+	}
+
+	return nil
+}
+
+func (s PubSubQueueService) Count(ctx context.Context) (*int, error) {
+	if s.backlog == nil {
+		return nil, errors.New("backlog reader not configured")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	count, err := s.backlog.Backlog(ctx, s.Project, s.SubscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("subscription backlog error: %w", err)
+	}
+
+	return aws.Int(count), nil
+}
+
+func (s PubSubQueueService) Close() {
+	if err := s.client.Close(); err != nil {
+		log.Warnf("pubsub client close error: %s", err)
+	}
+
+	if err := s.publisher.Close(); err != nil {
+		log.Warnf("pubsub publisher client close error: %s", err)
+	}
+}
+
+// MonitoringBacklogReader reads the subscription/num_undelivered_messages metric from cloud monitoring.
+type MonitoringBacklogReader struct {
+	client *monitoring.MetricClient
+}
+
+func NewMonitoringBacklogReader(opts ...option.ClientOption) (*MonitoringBacklogReader, error) {
+	client, err := monitoring.NewMetricClient(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("monitoring client: %w", err)
+	}
+
+	return &MonitoringBacklogReader{client: client}, nil
+}
+
+func (r MonitoringBacklogReader) Backlog(ctx context.Context, project string, subscription string) (int, error) {
+	now := time.Now()
+	timeSeries := r.client.ListTimeSeries(ctx, &monitoringpb.ListTimeSeriesRequest{
+		Name: fmt.Sprintf("projects/%s", project),
+		Filter: fmt.Sprintf(`metric.type = "pubsub.googleapis.com/subscription/num_undelivered_messages" `+
+			`AND resource.labels.subscription_id = "%s"`, subscription),
+		Interval: &monitoringpb.TimeInterval{
+			StartTime: timestamppb.New(now.Add(-5 * time.Minute)),
+			EndTime:   timestamppb.New(now),
+		},
+		View: monitoringpb.ListTimeSeriesRequest_FULL,
+	})
+
+	series, err := timeSeries.Next()
+	if errors.Is(err, iterator.Done) {
+		return 0, nil
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	if len(series.Points) == 0 {
+		return 0, nil
+	}
+
+	// points are returned in reverse time order
+	return int(series.Points[0].GetValue().GetInt64Value()), nil
+}
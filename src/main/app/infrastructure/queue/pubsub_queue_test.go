@@ -0,0 +1,256 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+type MockBacklogReader struct {
+	mock.Mock
+}
+
+func (m *MockBacklogReader) Backlog(_ context.Context, project string, subscription string) (int, error) {
+	args := m.Called(project, subscription)
+	return args.Int(0), args.Error(1)
+}
+
+func newPubSubServer(t *testing.T, messages ...string) *pstest.Server {
+	srv := pstest.NewServer()
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Close())
+	})
+
+	_, err := srv.GServer.CreateTopic(context.Background(), &pubsubpb.Topic{
+		Name: "projects/my-project/topics/orders",
+	})
+	assert.NoError(t, err)
+
+	_, err = srv.GServer.CreateSubscription(context.Background(), &pubsubpb.Subscription{
+		Name:               "projects/my-project/subscriptions/orders-consumer",
+		Topic:              "projects/my-project/topics/orders",
+		AckDeadlineSeconds: 10,
+	})
+	assert.NoError(t, err)
+
+	for _, message := range messages {
+		srv.Publish("projects/my-project/topics/orders", []byte(message), nil)
+	}
+
+	return srv
+}
+
+func newPubSubQueueService(t *testing.T, srv *pstest.Server, backlog queue.BacklogReader) *queue.PubSubQueueService {
+	queueClient, err := queue.NewPubSubClient(queue.PubSubConfig{
+		Project:      "my-project",
+		Subscription: "orders-consumer",
+		Parallel:     10,
+		Timeout:      1000,
+		Topic:        "orders",
+		Backlog:      backlog,
+	},
+		option.WithEndpoint(srv.Addr),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	assert.NoError(t, err)
+	t.Cleanup(queueClient.Close)
+
+	return queueClient
+}
+
+func TestNewPubSubClientErr(t *testing.T) {
+	queueClient, err := queue.NewPubSubClient(queue.PubSubConfig{
+		Project:      "my-project",
+		Subscription: "orders-consumer",
+		Parallel:     0,
+	})
+
+	assert.Error(t, err)
+	assert.Nil(t, queueClient)
+}
+
+func TestPubSubQueueService_Receive(t *testing.T) {
+	srv := newPubSubServer(t, "msg1", "msg2")
+	queueClient := newPubSubQueueService(t, srv, nil)
+
+	actual, err := queueClient.Receive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	assert.ElementsMatch(t, []string{"msg1", "msg2"}, []string{actual[0].String(), actual[1].String()})
+	assert.NotEmpty(t, actual[0].MessageID)
+	assert.NotEmpty(t, actual[0].ReceiptHandle)
+}
+
+func TestPubSubQueueService_ReceiveEmpty(t *testing.T) {
+	srv := newPubSubServer(t)
+	queueClient := newPubSubQueueService(t, srv, nil)
+
+	actual, err := queueClient.Receive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Nil(t, actual)
+}
+
+func TestPubSubQueueService_Delete(t *testing.T) {
+	srv := newPubSubServer(t, "msg1")
+	queueClient := newPubSubQueueService(t, srv, nil)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	err = queueClient.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return srv.Messages()[0].Acks == 1
+	}, time.Second, time.Millisecond*50)
+}
+
+func TestPubSubQueueService_Nack(t *testing.T) {
+	srv := newPubSubServer(t, "msg1")
+	queueClient := newPubSubQueueService(t, srv, nil)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	err = queueClient.Nack(context.Background(), messages[0].ReceiptHandle, 0)
+	assert.NoError(t, err)
+
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, redelivered, 1)
+	assert.Equal(t, "msg1", redelivered[0].String())
+}
+
+func TestPubSubQueueService_NackDelay(t *testing.T) {
+	srv := newPubSubServer(t, "msg1")
+	queueClient, err := queue.NewPubSubClient(queue.PubSubConfig{
+		Project:      "my-project",
+		Subscription: "orders-consumer",
+		Parallel:     10,
+		Timeout:      500,
+		NackDelay:    60000,
+	},
+		option.WithEndpoint(srv.Addr),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
+	assert.NoError(t, err)
+	t.Cleanup(queueClient.Close)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	// a zero delay is the configured nack delay
+	assert.NoError(t, queueClient.Nack(context.Background(), messages[0].ReceiptHandle, 0))
+
+	redelivered, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, redelivered)
+}
+
+func TestPubSubQueueService_NackMaxDelay(t *testing.T) {
+	srv := newPubSubServer(t, "msg1")
+	queueClient := newPubSubQueueService(t, srv, nil)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	err = queueClient.Nack(context.Background(), messages[0].ReceiptHandle, time.Minute*20)
+	assert.NoError(t, err)
+
+	modacks := srv.Messages()[0].Modacks
+	assert.Len(t, modacks, 1)
+	assert.Equal(t, int32(600), modacks[0].AckDeadline)
+}
+
+func TestPubSubQueueService_Count(t *testing.T) {
+	srv := newPubSubServer(t)
+	backlog := new(MockBacklogReader)
+	backlog.On("Backlog", "my-project", "orders-consumer").Return(5, nil)
+	queueClient := newPubSubQueueService(t, srv, backlog)
+
+	count, err := queueClient.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, aws.ToInt(count))
+}
+
+func TestPubSubQueueService_CountErr(t *testing.T) {
+	srv := newPubSubServer(t)
+	backlog := new(MockBacklogReader)
+	backlog.On("Backlog", "my-project", "orders-consumer").Return(0, errors.New("permission denied"))
+	queueClient := newPubSubQueueService(t, srv, backlog)
+
+	count, err := queueClient.Count(context.Background())
+	assert.Error(t, err)
+	assert.Nil(t, count)
+
+	queueClient = newPubSubQueueService(t, srv, nil)
+	count, err = queueClient.Count(context.Background())
+	assert.Error(t, err)
+	assert.Nil(t, count)
+}
+
+func TestPubSubQueueService_SendBatch(t *testing.T) {
+	srv := newPubSubServer(t)
+	queueClient := newPubSubQueueService(t, srv, nil)
+
+	results, err := queueClient.SendBatch(context.Background(), []queue.SendMessageDTO{
+		{Body: "msg1", Attributes: map[string]string{"event": "created"}},
+		{Body: "msg2"},
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.NotEmpty(t, results[0].MessageID)
+	assert.NoError(t, results[0].Err)
+
+	actual, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, actual, 2)
+	for _, message := range actual {
+		if message.Body == "msg1" {
+			assert.Equal(t, "created", message.Attributes["event"])
+		}
+	}
+}
+
+func TestPubSubQueueService_SendDelayErr(t *testing.T) {
+	srv := newPubSubServer(t)
+	queueClient := newPubSubQueueService(t, srv, nil)
+
+	_, err := queueClient.Send(context.Background(), queue.SendMessageDTO{Body: "msg1", Delay: time.Second})
+
+	assert.ErrorIs(t, err, queue.ErrDelayNotSupported)
+}
+
+func TestPubSubQueueService_Peek(t *testing.T) {
+	srv := newPubSubServer(t, "msg1")
+	queueClient := newPubSubQueueService(t, srv, nil)
+
+	actual, err := queueClient.Peek(context.Background(), 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, "msg1", actual[0].String())
+	assert.Empty(t, actual[0].ReceiptHandle)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+}
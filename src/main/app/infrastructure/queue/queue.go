@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/src/main/app/log"
@@ -17,13 +18,29 @@ type AWSClient interface {
 	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
 	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
 	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput,
+		optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+	ChangeMessageVisibilityBatch(ctx context.Context, params *sqs.ChangeMessageVisibilityBatchInput,
+		optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityBatchOutput, error)
 }
 
+// maxSQSBatch is the max number of entries of a sqs batch request.
+const maxSQSBatch = 10
+
+// maxSQSVisibilityTimeout is the max visibility timeout of a sqs message.
+const maxSQSVisibilityTimeout = time.Hour * 12
+
 type AWSQueueService struct {
 	Timeout  time.Duration
 	QueueURL string
 	MaxMsg   int
 	AWSClient
+	// S3Client resolves the extended client pointers, without it the pointer bodies are received as they are.
+	S3Client S3Client
+	// DeletePayloads deletes the object of a pointer with its message.
+	DeletePayloads bool
 }
 
 type Config struct {
@@ -31,6 +48,11 @@ type Config struct {
 	URL      string
 	Parallel int
 	Timeout  int
+	// ExtendedPayloads receives the bodies of the extended client pointers from s3.
+	ExtendedPayloads bool
+	DeletePayloads   bool
+	// S3PathStyle addresses the buckets in the path, i.e. localstack or sqs-local on aws.url.
+	S3PathStyle bool
 }
 
 func NewClient(config Config, awsConfig aws.Config) (*AWSQueueService, error) {
@@ -39,12 +61,21 @@ func NewClient(config Config, awsConfig aws.Config) (*AWSQueueService, error) {
 		return nil, errors.New("invalidad parallel value")
 	}
 
-	return &AWSQueueService{
+	awsQueueService := &AWSQueueService{
 		Timeout:   time.Millisecond * time.Duration(config.Timeout),
 		AWSClient: sqs.NewFromConfig(awsConfig),
 		QueueURL:  config.URL,
 		MaxMsg:    config.Parallel,
-	}, nil
+	}
+
+	if config.ExtendedPayloads {
+		awsQueueService.S3Client = s3.NewFromConfig(awsConfig, func(options *s3.Options) {
+			options.UsePathStyle = config.S3PathStyle
+		})
+		awsQueueService.DeletePayloads = config.DeletePayloads
+	}
+
+	return awsQueueService, nil
 }
 
 func (s AWSQueueService) Receive(ctx context.Context) ([]MessageDTO, error) {
@@ -67,21 +98,130 @@ func (s AWSQueueService) Receive(ctx context.Context) ([]MessageDTO, error) {
 		return nil, nil
 	}
 
-	messages := make([]MessageDTO, len(receiveMessageOutput.Messages))
+	messages := toMessageDTOs(receiveMessageOutput.Messages)
+	if s.S3Client != nil {
+		messages = s.resolvePayloads(ctx, messages)
+	}
+
+	return messages, nil
+}
+
+// Peek receives up to 10 messages and makes them visible again right away, as sqs has no peek.
+// The receive count of the messages is increased.
+func (s AWSQueueService) Peek(ctx context.Context, maxMsg int) ([]MessageDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	receiveMessageOutput, err := s.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+		QueueUrl:              aws.String(s.QueueURL),
+		MaxNumberOfMessages:   int32(min(maxMsg, maxSQSBatch)),
+		AttributeNames:        []types.QueueAttributeName{"ApproximateReceiveCount"},
+		MessageAttributeNames: []string{"All"},
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("peek: %w", err)
+	}
+
+	if len(receiveMessageOutput.Messages) == 0 {
+		return nil, nil
+	}
+
+	entries := make([]types.ChangeMessageVisibilityBatchRequestEntry, len(receiveMessageOutput.Messages))
 	for i, message := range receiveMessageOutput.Messages {
-		messageDTO := new(MessageDTO)
-		messageDTO.Body = aws.ToString(message.Body)
-		messageDTO.ReceiptHandle = aws.ToString(message.ReceiptHandle)
-		messages[i] = *messageDTO
+		entries[i] = types.ChangeMessageVisibilityBatchRequestEntry{
+			Id:                aws.String(strconv.Itoa(i)),
+			ReceiptHandle:     message.ReceiptHandle,
+			VisibilityTimeout: 0,
+		}
+	}
+
+	changeOutput, err := s.ChangeMessageVisibilityBatch(ctx, &sqs.ChangeMessageVisibilityBatchInput{
+		QueueUrl: aws.String(s.QueueURL),
+		Entries:  entries,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("peek: restore visibility: %w", err)
+	}
+
+	for _, failed := range changeOutput.Failed {
+		log.Warnf("peek: restore visibility of entry %s: %s", aws.ToString(failed.Id), aws.ToString(failed.Message))
+	}
+
+	messages := toMessageDTOs(receiveMessageOutput.Messages)
+	for i, message := range receiveMessageOutput.Messages {
+		messages[i].ReceiptHandle = ""
+		messages[i].ReceiveCount, _ = strconv.Atoi(message.Attributes["ApproximateReceiveCount"])
 	}
 
 	return messages, nil
 }
 
+func (s AWSQueueService) Send(ctx context.Context, message SendMessageDTO) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	output, err := s.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:          aws.String(s.QueueURL),
+		MessageBody:       aws.String(message.Body),
+		DelaySeconds:      int32(message.Delay.Seconds()),
+		MessageAttributes: toMessageAttributes(message.Attributes),
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("send: %w", err)
+	}
+
+	return aws.ToString(output.MessageId), nil
+}
+
+// SendBatch sends the messages in sqs batches of 10 entries, the entry id is the message index.
+func (s AWSQueueService) SendBatch(ctx context.Context, messages []SendMessageDTO) ([]SendResultDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	results := make([]SendResultDTO, len(messages))
+	for start := 0; start < len(messages); start += maxSQSBatch {
+		end := min(start+maxSQSBatch, len(messages))
+
+		entries := make([]types.SendMessageBatchRequestEntry, 0, end-start)
+		for i := start; i < end; i++ {
+			entries = append(entries, types.SendMessageBatchRequestEntry{
+				Id:                aws.String(strconv.Itoa(i)),
+				MessageBody:       aws.String(messages[i].Body),
+				DelaySeconds:      int32(messages[i].Delay.Seconds()),
+				MessageAttributes: toMessageAttributes(messages[i].Attributes),
+			})
+		}
+
+		output, err := s.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(s.QueueURL),
+			Entries:  entries,
+		})
+
+		if err != nil {
+			return nil, fmt.Errorf("send batch: %w", err)
+		}
+
+		for _, entry := range output.Successful {
+			i, _ := strconv.Atoi(aws.ToString(entry.Id))
+			results[i].MessageID = aws.ToString(entry.MessageId)
+		}
+
+		for _, entry := range output.Failed {
+			i, _ := strconv.Atoi(aws.ToString(entry.Id))
+			results[i].Err = fmt.Errorf("send batch: %s: %s", aws.ToString(entry.Code), aws.ToString(entry.Message))
+		}
+	}
+
+	return results, nil
+}
+
 func (s AWSQueueService) Delete(ctx context.Context, receiptHandle string) error {
 	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
 	defer cancel()
 
+	receiptHandle, pointer := splitReceiptHandle(receiptHandle)
 	if _, err := s.DeleteMessage(ctx, &sqs.DeleteMessageInput{
 		QueueUrl:      aws.String(s.QueueURL),
 		ReceiptHandle: aws.String(receiptHandle),
@@ -89,6 +229,32 @@ func (s AWSQueueService) Delete(ctx context.Context, receiptHandle string) error
 		return fmt.Errorf("delete: %w", err)
 	}
 
+	if pointer != nil && s.S3Client != nil {
+		s.deletePayload(ctx, pointer)
+	}
+
+	return nil
+}
+
+// Nack changes the visibility of the message, so it is received again after delay.
+// A zero delay keeps the visibility timeout of the queue.
+func (s AWSQueueService) Nack(ctx context.Context, receiptHandle string, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	receiptHandle, _ = splitReceiptHandle(receiptHandle)
+	if _, err := s.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(s.QueueURL),
+		ReceiptHandle:     aws.String(receiptHandle),
+		VisibilityTimeout: int32(min(delay, maxSQSVisibilityTimeout).Seconds()),
+	}); err != nil {
+		return fmt.Errorf("nack: %w", err)
+	}
+
 	return nil
 }
 
@@ -114,3 +280,46 @@ func (s AWSQueueService) Count(ctx context.Context) (*int, error) {
 
 	return aws.Int(count), nil
 }
+
+func toMessageDTOs(sqsMessages []types.Message) []MessageDTO {
+	messages := make([]MessageDTO, len(sqsMessages))
+	for i, message := range sqsMessages {
+		messageDTO := new(MessageDTO)
+		messageDTO.MessageID = aws.ToString(message.MessageId)
+		messageDTO.Body = aws.ToString(message.Body)
+		messageDTO.ReceiptHandle = aws.ToString(message.ReceiptHandle)
+		messageDTO.Attributes = fromMessageAttributes(message.MessageAttributes)
+		messages[i] = *messageDTO
+	}
+
+	return messages
+}
+
+func toMessageAttributes(attributes map[string]string) map[string]types.MessageAttributeValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	messageAttributes := make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		messageAttributes[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+
+	return messageAttributes
+}
+
+func fromMessageAttributes(messageAttributes map[string]types.MessageAttributeValue) map[string]string {
+	if len(messageAttributes) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(messageAttributes))
+	for name, value := range messageAttributes {
+		attributes[name] = aws.ToString(value.StringValue)
+	}
+
+	return attributes
+}
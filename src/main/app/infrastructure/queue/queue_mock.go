@@ -9,6 +9,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/google/uuid"
 	"github.com/ugurcsen/gods-generic/maps/hashmap"
 )
 
@@ -95,6 +96,75 @@ func (m *MockClient) GetQueueAttributes(_ context.Context, params *sqs.GetQueueA
 	}, nil
 }
 
+func (m *MockClient) SendMessage(_ context.Context, params *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	queue, err := m.getQueue(aws.ToString(params.QueueUrl))
+	if err != nil {
+		return nil, err
+	}
+
+	messageID := uuid.NewString()
+	queue.PushBack(types.Message{
+		MessageId:         aws.String(messageID),
+		Body:              params.MessageBody,
+		ReceiptHandle:     aws.String(uuid.NewString()),
+		MessageAttributes: params.MessageAttributes,
+	})
+
+	return &sqs.SendMessageOutput{
+		MessageId: aws.String(messageID),
+	}, nil
+}
+
+func (m *MockClient) SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, _ ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	output := new(sqs.SendMessageBatchOutput)
+	for _, entry := range params.Entries {
+		sendMessageOutput, err := m.SendMessage(ctx, &sqs.SendMessageInput{
+			QueueUrl:          params.QueueUrl,
+			MessageBody:       entry.MessageBody,
+			MessageAttributes: entry.MessageAttributes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		output.Successful = append(output.Successful, types.SendMessageBatchResultEntry{
+			Id:        entry.Id,
+			MessageId: sendMessageOutput.MessageId,
+		})
+	}
+
+	return output, nil
+}
+
+func (m *MockClient) ChangeMessageVisibility(_ context.Context, params *sqs.ChangeMessageVisibilityInput,
+	_ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	queue, err := m.getQueue(aws.ToString(params.QueueUrl))
+	if err != nil {
+		return nil, err
+	}
+
+	for e := queue.Front(); e != nil; e = e.Next() {
+		if message, ok := e.Value.(types.Message); ok && aws.ToString(message.ReceiptHandle) == aws.ToString(params.ReceiptHandle) {
+			return new(sqs.ChangeMessageVisibilityOutput), nil
+		}
+	}
+
+	return nil, fmt.Errorf("change visibility error: %s", aws.ToString(params.ReceiptHandle))
+}
+
+func (m *MockClient) ChangeMessageVisibilityBatch(_ context.Context, params *sqs.ChangeMessageVisibilityBatchInput,
+	_ ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityBatchOutput, error) {
+	if _, err := m.getQueue(aws.ToString(params.QueueUrl)); err != nil {
+		return nil, err
+	}
+
+	output := new(sqs.ChangeMessageVisibilityBatchOutput)
+	for _, entry := range params.Entries {
+		output.Successful = append(output.Successful, types.ChangeMessageVisibilityBatchResultEntry{Id: entry.Id})
+	}
+
+	return output, nil
+}
+
 func (m *MockClient) getQueue(queueURL string) (*list.List, error) {
 	queue, found := m.queues.Get(queueURL)
 	if !found {
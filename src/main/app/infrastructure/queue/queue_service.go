@@ -1,16 +1,98 @@
 package queue
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 type Service interface {
 	Receive(ctx context.Context) ([]MessageDTO, error)
+	Send(ctx context.Context, message SendMessageDTO) (string, error)
+	// SendBatch returns a result by message in the same order, the error is only for a failure of the whole batch.
+	SendBatch(ctx context.Context, messages []SendMessageDTO) ([]SendResultDTO, error)
 	Delete(ctx context.Context, receiptHandle string) error
 	Count(ctx context.Context) (*int, error)
 }
 
+// ErrDelayNotSupported is returned by the backends without delayed delivery.
+var ErrDelayNotSupported = errors.New("delay not supported by queue backend")
+
+// ErrSendOnly is returned by the queues built only to send, i.e. a kafka producer without consumer group.
+var ErrSendOnly = errors.New("queue client is send only")
+
+// Nacker is implemented by backends able to hand a failed message back before its redelivery timeout.
+// A zero delay means the backend default.
+type Nacker interface {
+	Nack(ctx context.Context, receiptHandle string, delay time.Duration) error
+}
+
+// Peeker is implemented by backends able to read messages for inspection, the messages are left available
+// to the consumers right away.
+type Peeker interface {
+	Peek(ctx context.Context, maxMsg int) ([]MessageDTO, error)
+}
+
+type Type string
+
+const (
+	SQS    Type = "sqs"
+	Kafka  Type = "kafka"
+	NATS   Type = "nats"
+	PubSub Type = "pubsub"
+	Memory Type = "memory"
+)
+
+// Delays is a backend with delayed sends, the other ones return ErrDelayNotSupported.
+func (t Type) Delays() bool {
+	return t == SQS || t == Memory
+}
+
 type MessageDTO struct {
+	MessageID     string
 	Body          string
 	ReceiptHandle string
+	ReceiveCount  int
+	Attributes    map[string]string
+	// Sensitive bodies, i.e. decrypted payloads, must not be logged.
+	Sensitive bool
+	// Raw payloads are not sns notifications, i.e. kafka records, they are pushed as they are.
+	Raw bool
+}
+
+// OriginalMessageIDAttribute carries the message id of the first delivery through the re-publishes, i.e. the
+// retries and the kafka nacks, which change the transport message id.
+const OriginalMessageIDAttribute = "original-message-id"
+
+// OriginalID is the message id of the first delivery, stable across the re-publishes.
+func (m MessageDTO) OriginalID() string {
+	if id := m.Attributes[OriginalMessageIDAttribute]; id != "" {
+		return id
+	}
+
+	return m.MessageID
+}
+
+type SendMessageDTO struct {
+	Body       string
+	Attributes map[string]string
+	Delay      time.Duration
+}
+
+type SendResultDTO struct {
+	MessageID string
+	Err       error
+}
+
+// sendEach sends a batch one message at a time, for the backends without batch send.
+func sendEach(ctx context.Context, send func(ctx context.Context, message SendMessageDTO) (string, error),
+	messages []SendMessageDTO) []SendResultDTO {
+	results := make([]SendResultDTO, len(messages))
+	for i, message := range messages {
+		results[i].MessageID, results[i].Err = send(ctx, message)
+	}
+
+	return results
 }
 
 func (m *MessageDTO) String() string {
@@ -4,6 +4,7 @@ import (
 	"container/list"
 	"context"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
@@ -218,3 +219,80 @@ func TestNewClientDeleteNotFoundErr(t *testing.T) {
 	err := queueClient.Delete(context.Background(), "wait ...")
 	assert.Error(t, err)
 }
+
+func TestNewFakeClientSend(t *testing.T) {
+	queueURL := "https://queues.com/my-queue"
+	queues := hashmap.New[string, *list.List]()
+	queues.Put(queueURL, new(list.List))
+
+	queueClient := queue.NewMockClient(queue.MockConfig{
+		QueueURL: queueURL,
+		MaxMsg:   10,
+		Queues:   queues,
+	})
+
+	messageID, err := queueClient.Send(context.Background(), queue.SendMessageDTO{Body: "msg1"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, messageID)
+
+	results, err := queueClient.SendBatch(context.Background(), []queue.SendMessageDTO{{Body: "msg2"}, {Body: "msg3"}})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	actual, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, actual, 3)
+	assert.Equal(t, messageID, actual[0].MessageID)
+	assert.Equal(t, "msg3", actual[2].String())
+}
+
+func TestNewFakeClientPeek(t *testing.T) {
+	queueURL := "https://queues.com/my-queue"
+	queues := hashmap.New[string, *list.List]()
+	queues.Put(queueURL, new(list.List))
+
+	queueClient := queue.NewMockClient(queue.MockConfig{
+		QueueURL: queueURL,
+		MaxMsg:   10,
+		Queues:   queues,
+	})
+
+	_, err := queueClient.SendBatch(context.Background(), []queue.SendMessageDTO{{Body: "msg1"}, {Body: "msg2"}})
+	assert.NoError(t, err)
+
+	actual, err := queueClient.Peek(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, "msg1", actual[0].String())
+	assert.Empty(t, actual[0].ReceiptHandle)
+}
+
+func TestNewFakeClientNack(t *testing.T) {
+	queueURL := "https://queues.com/my-queue"
+	queues := hashmap.New[string, *list.List]()
+	queues.Put(queueURL, new(list.List))
+
+	queueClient := queue.NewMockClient(queue.MockConfig{
+		QueueURL: queueURL,
+		MaxMsg:   10,
+		Queues:   queues,
+	})
+
+	_, err := queueClient.Send(context.Background(), queue.SendMessageDTO{Body: "msg1"})
+	assert.NoError(t, err)
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+
+	assert.NoError(t, queueClient.Nack(context.Background(), messages[0].ReceiptHandle, time.Minute))
+	assert.NoError(t, queueClient.Nack(context.Background(), "unknown", 0))
+	assert.Error(t, queueClient.Nack(context.Background(), "unknown", time.Minute))
+}
+
+func TestType_Delays(t *testing.T) {
+	assert.True(t, queue.SQS.Delays())
+	assert.True(t, queue.Memory.Delays())
+	assert.False(t, queue.Kafka.Delays())
+	assert.False(t, queue.NATS.Delays())
+	assert.False(t, queue.PubSub.Delays())
+}
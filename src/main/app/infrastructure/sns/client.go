@@ -0,0 +1,252 @@
+package sns
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // sns signature version 1 is sha1 with rsa
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Notification types of the sns http/s protocol.
+const (
+	NotificationType             = "Notification"
+	SubscriptionConfirmationType = "SubscriptionConfirmation"
+	UnsubscribeConfirmationType  = "UnsubscribeConfirmation"
+)
+
+// DefaultCertHostPattern matches the sns signing certificate and subscribe url hosts.
+const DefaultCertHostPattern = `^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`
+
+type Notification struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token,omitempty"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject,omitempty"`
+	Message          string `json:"Message"`
+	SubscribeURL     string `json:"SubscribeURL,omitempty"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+	UnsubscribeURL   string `json:"UnsubscribeURL,omitempty"`
+	// MessageAttributes are not part of the signature.
+	MessageAttributes map[string]MessageAttribute `json:"MessageAttributes,omitempty"`
+}
+
+// MessageAttribute of a notification, the binary values are base64 strings.
+type MessageAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// Attributes are the message attribute values by name, nil without attributes.
+func (n Notification) Attributes() map[string]string {
+	if len(n.MessageAttributes) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(n.MessageAttributes))
+	for name, attribute := range n.MessageAttributes {
+		attributes[name] = attribute.Value
+	}
+
+	return attributes
+}
+
+type IClient interface {
+	Verify(ctx context.Context, notification *Notification) error
+	ConfirmSubscription(ctx context.Context, notification *Notification) error
+}
+
+// Client verifies sns http notifications and confirms subscriptions, signing certificates are cached by url.
+type Client struct {
+	Timeout    time.Duration
+	hostRegex  *regexp.Regexp
+	httpClient *http.Client
+	certs      *sync.Map
+}
+
+type Config struct {
+	Timeout         int
+	CertHostPattern string
+}
+
+func NewClient(config Config, httpClient *http.Client) (*Client, error) {
+	if config.CertHostPattern == "" {
+		config.CertHostPattern = DefaultCertHostPattern
+	}
+
+	hostRegex, err := regexp.Compile(config.CertHostPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cert host pattern: %w", err)
+	}
+
+	return &Client{
+		Timeout:    time.Millisecond * time.Duration(config.Timeout),
+		hostRegex:  hostRegex,
+		httpClient: httpClient,
+		certs:      new(sync.Map),
+	}, nil
+}
+
+// Verify checks the notification signature against the sns signing certificate.
+func (c Client) Verify(ctx context.Context, notification *Notification) error {
+	var hash crypto.Hash
+	switch notification.SignatureVersion {
+	case "1":
+		hash = crypto.SHA1
+	case "2":
+		hash = crypto.SHA256
+	default:
+		return fmt.Errorf("unsupported signature version %s", notification.SignatureVersion)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(notification.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	publicKey, err := c.publicKey(ctx, notification.SigningCertURL)
+	if err != nil {
+		return err
+	}
+
+	stringToSign, err := StringToSign(notification)
+	if err != nil {
+		return err
+	}
+
+	if err = rsa.VerifyPKCS1v15(publicKey, hash, digest(hash, stringToSign), signature); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	return nil
+}
+
+// ConfirmSubscription visits the subscribe url of a subscription confirmation.
+func (c Client) ConfirmSubscription(ctx context.Context, notification *Notification) error {
+	if _, err := c.get(ctx, notification.SubscribeURL); err != nil {
+		return fmt.Errorf("confirm subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (c Client) publicKey(ctx context.Context, certURL string) (*rsa.PublicKey, error) {
+	if value, found := c.certs.Load(certURL); found {
+		return value.(*rsa.PublicKey), nil
+	}
+
+	body, err := c.get(ctx, certURL)
+	if err != nil {
+		return nil, fmt.Errorf("signing cert: %w", err)
+	}
+
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, errors.New("signing cert: invalid pem")
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("signing cert: %w", err)
+	}
+
+	publicKey, ok := certificate.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("signing cert: not a rsa public key")
+	}
+
+	c.certs.Store(certURL, publicKey)
+	return publicKey, nil
+}
+
+// get only follows https urls of the sns hosts, so a forged notification can not make the app call any url.
+func (c Client) get(ctx context.Context, rawURL string) ([]byte, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsedURL.Scheme != "https" || !c.hostRegex.MatchString(parsedURL.Hostname()) {
+		return nil, fmt.Errorf("untrusted url %s", rawURL)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, parsedURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: status code %d", rawURL, response.StatusCode)
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+// StringToSign builds the canonical string signed by sns, the keys depend on the notification type.
+func StringToSign(notification *Notification) (string, error) {
+	var fields [][2]string
+	switch notification.Type {
+	case NotificationType:
+		fields = append(fields, [2]string{"Message", notification.Message}, [2]string{"MessageId", notification.MessageID})
+		if notification.Subject != "" {
+			fields = append(fields, [2]string{"Subject", notification.Subject})
+		}
+		fields = append(fields,
+			[2]string{"Timestamp", notification.Timestamp},
+			[2]string{"TopicArn", notification.TopicArn},
+			[2]string{"Type", notification.Type})
+	case SubscriptionConfirmationType, UnsubscribeConfirmationType:
+		fields = append(fields,
+			[2]string{"Message", notification.Message},
+			[2]string{"MessageId", notification.MessageID},
+			[2]string{"SubscribeURL", notification.SubscribeURL},
+			[2]string{"Timestamp", notification.Timestamp},
+			[2]string{"Token", notification.Token},
+			[2]string{"TopicArn", notification.TopicArn},
+			[2]string{"Type", notification.Type})
+	default:
+		return "", fmt.Errorf("unsupported notification type %s", notification.Type)
+	}
+
+	builder := new(strings.Builder)
+	for _, field := range fields {
+		builder.WriteString(field[0] + "\n" + field[1] + "\n")
+	}
+
+	return builder.String(), nil
+}
+
+func digest(hash crypto.Hash, value string) []byte {
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum([]byte(value)) //nolint:gosec // sns signature version 1 is sha1 with rsa
+		return sum[:]
+	}
+
+	sum := sha256.Sum256([]byte(value))
+	return sum[:]
+}
@@ -0,0 +1,201 @@
+package sns_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // sns signature version 1 is sha1 with rsa
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/src/main/app/infrastructure/sns"
+	"github.com/stretchr/testify/assert"
+)
+
+type signer struct {
+	key       *rsa.PrivateKey
+	server    *httptest.Server
+	confirmed *atomic.Int32
+}
+
+func newSigner(t *testing.T) *signer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.us-east-1.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	confirmed := new(atomic.Int32)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cert.pem", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(certPEM)
+	})
+	mux.HandleFunc("/confirm", func(w http.ResponseWriter, _ *http.Request) {
+		confirmed.Add(1)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	return &signer{key: key, server: server, confirmed: confirmed}
+}
+
+func (s signer) client(t *testing.T) *sns.Client {
+	client, err := sns.NewClient(sns.Config{
+		Timeout:         1000,
+		CertHostPattern: `^127\.0\.0\.1$`,
+	}, s.server.Client())
+	assert.NoError(t, err)
+
+	return client
+}
+
+func (s signer) sign(t *testing.T, notification *sns.Notification) *sns.Notification {
+	notification.SigningCertURL = s.server.URL + "/cert.pem"
+
+	stringToSign, err := sns.StringToSign(notification)
+	assert.NoError(t, err)
+
+	hash, sum := crypto.SHA256, sha256.Sum256([]byte(stringToSign))
+	digest := sum[:]
+	if notification.SignatureVersion == "1" {
+		sha1Sum := sha1.Sum([]byte(stringToSign)) //nolint:gosec // sns signature version 1 is sha1 with rsa
+		hash, digest = crypto.SHA1, sha1Sum[:]
+	}
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, hash, digest)
+	assert.NoError(t, err)
+	notification.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	return notification
+}
+
+func newNotification(signatureVersion string) *sns.Notification {
+	return &sns.Notification{
+		Type:             sns.NotificationType,
+		MessageID:        "22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324",
+		TopicArn:         "arn:aws:sns:us-east-1:000000000000:orders-topic",
+		Message:          `{"order_id": 1}`,
+		Timestamp:        "2023-11-20T12:00:00.000Z",
+		SignatureVersion: signatureVersion,
+	}
+}
+
+func TestNewClientErr(t *testing.T) {
+	client, err := sns.NewClient(sns.Config{CertHostPattern: "["}, http.DefaultClient)
+
+	assert.Error(t, err)
+	assert.Nil(t, client)
+}
+
+func TestClient_Verify(t *testing.T) {
+	signer := newSigner(t)
+	client := signer.client(t)
+
+	err := client.Verify(context.Background(), signer.sign(t, newNotification("2")))
+	assert.NoError(t, err)
+
+	err = client.Verify(context.Background(), signer.sign(t, newNotification("1")))
+	assert.NoError(t, err)
+}
+
+func TestClient_VerifySubject(t *testing.T) {
+	signer := newSigner(t)
+	notification := newNotification("2")
+	notification.Subject = "orders"
+
+	err := signer.client(t).Verify(context.Background(), signer.sign(t, notification))
+	assert.NoError(t, err)
+}
+
+func TestClient_VerifyTampered(t *testing.T) {
+	signer := newSigner(t)
+	notification := signer.sign(t, newNotification("2"))
+	notification.Message = `{"order_id": 2}`
+
+	err := signer.client(t).Verify(context.Background(), notification)
+	assert.Error(t, err)
+}
+
+func TestClient_VerifyUntrustedCert(t *testing.T) {
+	signer := newSigner(t)
+	notification := signer.sign(t, newNotification("2"))
+	notification.SigningCertURL = "https://attacker.example.com/cert.pem"
+
+	err := signer.client(t).Verify(context.Background(), notification)
+	assert.Error(t, err)
+}
+
+func TestClient_VerifyUnsupportedVersion(t *testing.T) {
+	signer := newSigner(t)
+
+	err := signer.client(t).Verify(context.Background(), signer.sign(t, newNotification("3")))
+	assert.Error(t, err)
+}
+
+func TestClient_ConfirmSubscription(t *testing.T) {
+	signer := newSigner(t)
+	client := signer.client(t)
+	notification := newNotification("2")
+	notification.Type = sns.SubscriptionConfirmationType
+	notification.Token = "token"
+	notification.SubscribeURL = signer.server.URL + "/confirm"
+	signer.sign(t, notification)
+
+	err := client.Verify(context.Background(), notification)
+	assert.NoError(t, err)
+
+	err = client.ConfirmSubscription(context.Background(), notification)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), signer.confirmed.Load())
+}
+
+func TestClient_ConfirmSubscriptionUntrusted(t *testing.T) {
+	signer := newSigner(t)
+	notification := newNotification("2")
+	notification.Type = sns.SubscriptionConfirmationType
+	notification.SubscribeURL = "http://169.254.169.254/latest/meta-data"
+
+	err := signer.client(t).ConfirmSubscription(context.Background(), notification)
+	assert.Error(t, err)
+	assert.Equal(t, int32(0), signer.confirmed.Load())
+}
+
+func TestStringToSign(t *testing.T) {
+	notification := newNotification("2")
+	notification.Subject = "orders"
+
+	actual, err := sns.StringToSign(notification)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Message\n{\"order_id\": 1}\nMessageId\n22b80b92-fdea-4c2c-8f9d-bdfb0c7bf324\n"+
+		"Subject\norders\nTimestamp\n2023-11-20T12:00:00.000Z\n"+
+		"TopicArn\narn:aws:sns:us-east-1:000000000000:orders-topic\nType\nNotification\n", actual)
+}
+
+func TestStringToSignErr(t *testing.T) {
+	notification := newNotification("2")
+	notification.Type = "Unknown"
+
+	_, err := sns.StringToSign(notification)
+
+	assert.Error(t, err)
+}
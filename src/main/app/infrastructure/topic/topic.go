@@ -0,0 +1,65 @@
+package topic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"github.com/src/main/app/infrastructure/queue"
+)
+
+type AWSClient interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// AWSTopicService publishes to a sns topic, the subscribed queues receive the messages as notifications.
+type AWSTopicService struct {
+	Timeout  time.Duration
+	TopicARN string
+	AWSClient
+}
+
+type Config struct {
+	ARN     string
+	Timeout int
+}
+
+func NewClient(config Config, awsConfig aws.Config) *AWSTopicService {
+	return &AWSTopicService{
+		Timeout:   time.Millisecond * time.Duration(config.Timeout),
+		TopicARN:  config.ARN,
+		AWSClient: sns.NewFromConfig(awsConfig),
+	}
+}
+
+// Send publishes the message as the Send of a queue, sns has no delayed delivery.
+func (s AWSTopicService) Send(ctx context.Context, message queue.SendMessageDTO) (string, error) {
+	if message.Delay > 0 {
+		return "", queue.ErrDelayNotSupported
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.Timeout)
+	defer cancel()
+
+	attributes := make(map[string]types.MessageAttributeValue, len(message.Attributes))
+	for name, value := range message.Attributes {
+		attributes[name] = types.MessageAttributeValue{
+			DataType:    aws.String("String"),
+			StringValue: aws.String(value),
+		}
+	}
+
+	output, err := s.Publish(ctx, &sns.PublishInput{
+		TopicArn:          aws.String(s.TopicARN),
+		Message:           aws.String(message.Body),
+		MessageAttributes: attributes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("publish: %w", err)
+	}
+
+	return aws.ToString(output.MessageId), nil
+}
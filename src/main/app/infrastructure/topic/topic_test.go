@@ -0,0 +1,61 @@
+package topic_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/infrastructure/topic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSNSClient struct {
+	mock.Mock
+	input *sns.PublishInput
+}
+
+func (m *MockSNSClient) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	m.input = params
+	args := m.Called()
+	return args.Get(0).(*sns.PublishOutput), args.Error(1)
+}
+
+func newTopicService(snsClient *MockSNSClient) *topic.AWSTopicService {
+	return &topic.AWSTopicService{
+		Timeout:   time.Second,
+		TopicARN:  "arn:aws:sns:us-east-1:000000000000:replies",
+		AWSClient: snsClient,
+	}
+}
+
+func TestAWSTopicService_Send(t *testing.T) {
+	snsClient := new(MockSNSClient)
+	snsClient.On("Publish").Return(&sns.PublishOutput{MessageId: aws.String("sns-1")}, nil)
+
+	messageID, err := newTopicService(snsClient).Send(context.Background(), queue.SendMessageDTO{
+		Body:       `{"status": 200}`,
+		Attributes: map[string]string{"correlation-id": "1"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "sns-1", messageID)
+	assert.Equal(t, "arn:aws:sns:us-east-1:000000000000:replies", aws.ToString(snsClient.input.TopicArn))
+	assert.Equal(t, `{"status": 200}`, aws.ToString(snsClient.input.Message))
+	assert.Equal(t, "1", aws.ToString(snsClient.input.MessageAttributes["correlation-id"].StringValue))
+}
+
+func TestAWSTopicService_SendErr(t *testing.T) {
+	snsClient := new(MockSNSClient)
+	snsClient.On("Publish").Return((*sns.PublishOutput)(nil), errors.New("not found"))
+
+	_, err := newTopicService(snsClient).Send(context.Background(), queue.SendMessageDTO{Body: "{}"})
+	assert.Error(t, err)
+
+	_, err = newTopicService(snsClient).Send(context.Background(), queue.SendMessageDTO{Body: "{}", Delay: time.Minute})
+	assert.ErrorIs(t, err, queue.ErrDelayNotSupported)
+}
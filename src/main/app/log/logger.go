@@ -45,6 +45,10 @@ func Infof(format string, v ...any) {
 	logger.Infof(format, v...)
 }
 
+func Debugf(format string, v ...any) {
+	logger.Debugf(format, v...)
+}
+
 func Warnf(format string, v ...any) {
 	logger.Warnf(format, v...)
 }
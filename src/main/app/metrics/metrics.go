@@ -11,6 +11,7 @@ import (
 
 type IMetricCollector interface {
 	IncrementCounter(name Name)
+	IncrementLabeledCounter(name Name, label string)
 	Record(name Name, value int)
 	RecordExecutionTime(name Name, value time.Duration)
 }
@@ -26,6 +27,8 @@ const (
 	PusherStatus50x   Name = "app_pusher_http_5xx"
 	PusherHTTPTime    Name = "app_pusher_http_time"
 	PusherHTTPTimeout Name = "app_pusher_http_timeout"
+	PusherRetry       Name = "app_pusher_retry"
+	PusherReject      Name = "app_pusher_reject"
 	Generic           Name = "app_pusher_generic_counter"
 )
 
@@ -33,12 +36,83 @@ const (
 const (
 	ApproximateNumberOfMessages Name = "app_approximate_number_of_messages"
 	CurrentWorkers              Name = "app_current_workers"
+	Filtered                    Name = "app_filtered"
+	ValidationFailed            Name = "app_validation_failed"
+	DecodeErrors                Name = "app_decode_errors"
+	DecryptErrors               Name = "app_decrypt_errors"
+)
+
+// SNS push ingestion metrics.
+const (
+	SNSNotifications    Name = "app_sns_notifications"
+	SNSInvalidSignature Name = "app_sns_invalid_signature"
+)
+
+// Redrive metrics.
+const (
+	RedriveMoved   Name = "app_redrive_moved"
+	RedriveSkipped Name = "app_redrive_skipped"
+	RedriveFailed  Name = "app_redrive_failed"
+)
+
+// Delayed retry metrics.
+const (
+	RetryRepublished Name = "app_retry_republished"
+	RetryExhausted   Name = "app_retry_exhausted"
+)
+
+// Content-based routing metrics, labeled by route.
+const (
+	RouteMessages Name = "app_route_messages"
+	RouteErrors   Name = "app_route_errors"
+)
+
+// Fan-out metrics, labeled by target.
+const (
+	FanOutDelivered Name = "app_fan_out_delivered"
+	FanOutFailed    Name = "app_fan_out_failed"
+)
+
+// Batch push metrics.
+const (
+	BatchSize   Name = "app_batch_size"
+	BatchErrors Name = "app_batch_errors"
+)
+
+// Splitter metrics, by part.
+const (
+	SplitParts  Name = "app_split_parts"
+	SplitErrors Name = "app_split_errors"
+)
+
+// Request-reply metrics.
+const (
+	RepliesPublished Name = "app_replies_published"
+	ReplyErrors      Name = "app_reply_errors"
+)
+
+// Endpoint templating metrics, messages without the fields of the endpoint.
+const (
+	EndpointErrors Name = "app_endpoint_errors"
+)
+
+// Queue bridge metrics, labeled by bridge.
+const (
+	BridgePublished Name = "app_bridge_published"
+	BridgeErrors    Name = "app_bridge_errors"
+)
+
+// Enrichment metrics, labeled by lookup.
+const (
+	LookupCacheHits Name = "app_lookup_cache_hits"
+	LookupErrors    Name = "app_lookup_errors"
 )
 
 var (
 	Collector         = newMetricsCollector()
 	counters          = hashmap.New[Name, prometheus.Counter]()
 	summaries         = hashmap.New[Name, prometheus.Summary]()
+	labeledCounters   = hashmap.New[Name, *prometheus.CounterVec]()
 	genericCounter    *prometheus.CounterVec
 	namespace, labels = "consumers", prometheus.Labels{
 		"env":   config.String("app.env"),
@@ -101,6 +175,26 @@ func newMetricsCollector() *metricsCollector {
 	prometheus.MustRegister(pusher50x)
 	counters.Put(PusherStatus50x, pusher50x)
 
+	pusherRetry := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(PusherRetry),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(pusherRetry)
+	counters.Put(PusherRetry, pusherRetry)
+
+	pusherReject := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(PusherReject),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(pusherReject)
+	counters.Put(PusherReject, pusherReject)
+
 	approximateNumberOfMessages := prometheus.NewSummary(
 		prometheus.SummaryOpts{
 			Namespace:   namespace,
@@ -140,6 +234,274 @@ func newMetricsCollector() *metricsCollector {
 	prometheus.MustRegister(pusherTimeout)
 	counters.Put(PusherHTTPTimeout, pusherTimeout)
 
+	snsNotifications := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(SNSNotifications),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(snsNotifications)
+	counters.Put(SNSNotifications, snsNotifications)
+
+	snsInvalidSignature := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(SNSInvalidSignature),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(snsInvalidSignature)
+	counters.Put(SNSInvalidSignature, snsInvalidSignature)
+
+	redriveMoved := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(RedriveMoved),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(redriveMoved)
+	counters.Put(RedriveMoved, redriveMoved)
+
+	redriveSkipped := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(RedriveSkipped),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(redriveSkipped)
+	counters.Put(RedriveSkipped, redriveSkipped)
+
+	redriveFailed := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(RedriveFailed),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(redriveFailed)
+	counters.Put(RedriveFailed, redriveFailed)
+
+	filtered := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(Filtered),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(filtered)
+	counters.Put(Filtered, filtered)
+
+	validationFailed := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(ValidationFailed),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(validationFailed)
+	counters.Put(ValidationFailed, validationFailed)
+
+	decodeErrors := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(DecodeErrors),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(decodeErrors)
+	counters.Put(DecodeErrors, decodeErrors)
+
+	decryptErrors := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(DecryptErrors),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(decryptErrors)
+	counters.Put(DecryptErrors, decryptErrors)
+
+	retryRepublished := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(RetryRepublished),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(retryRepublished)
+	counters.Put(RetryRepublished, retryRepublished)
+
+	retryExhausted := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(RetryExhausted),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(retryExhausted)
+	counters.Put(RetryExhausted, retryExhausted)
+
+	routeMessages := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(RouteMessages),
+			ConstLabels: labels,
+		},
+		[]string{"route"},
+	)
+	prometheus.MustRegister(routeMessages)
+	labeledCounters.Put(RouteMessages, routeMessages)
+
+	routeErrors := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(RouteErrors),
+			ConstLabels: labels,
+		},
+		[]string{"route"},
+	)
+	prometheus.MustRegister(routeErrors)
+	labeledCounters.Put(RouteErrors, routeErrors)
+
+	fanOutDelivered := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(FanOutDelivered),
+			ConstLabels: labels,
+		},
+		[]string{"target"},
+	)
+	prometheus.MustRegister(fanOutDelivered)
+	labeledCounters.Put(FanOutDelivered, fanOutDelivered)
+
+	fanOutFailed := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(FanOutFailed),
+			ConstLabels: labels,
+		},
+		[]string{"target"},
+	)
+	prometheus.MustRegister(fanOutFailed)
+	labeledCounters.Put(FanOutFailed, fanOutFailed)
+
+	batchSize := prometheus.NewSummary(
+		prometheus.SummaryOpts{
+			Namespace:   namespace,
+			Name:        string(BatchSize),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(batchSize)
+	summaries.Put(BatchSize, batchSize)
+
+	batchErrors := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(BatchErrors),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(batchErrors)
+	counters.Put(BatchErrors, batchErrors)
+
+	splitParts := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(SplitParts),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(splitParts)
+	counters.Put(SplitParts, splitParts)
+
+	splitErrors := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(SplitErrors),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(splitErrors)
+	counters.Put(SplitErrors, splitErrors)
+
+	repliesPublished := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(RepliesPublished),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(repliesPublished)
+	counters.Put(RepliesPublished, repliesPublished)
+
+	replyErrors := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(ReplyErrors),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(replyErrors)
+	counters.Put(ReplyErrors, replyErrors)
+
+	endpointErrors := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(EndpointErrors),
+			ConstLabels: labels,
+		},
+	)
+	prometheus.MustRegister(endpointErrors)
+	counters.Put(EndpointErrors, endpointErrors)
+
+	bridgePublished := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(BridgePublished),
+			ConstLabels: labels,
+		},
+		[]string{"bridge"},
+	)
+	prometheus.MustRegister(bridgePublished)
+	labeledCounters.Put(BridgePublished, bridgePublished)
+
+	bridgeErrors := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(BridgeErrors),
+			ConstLabels: labels,
+		},
+		[]string{"bridge"},
+	)
+	prometheus.MustRegister(bridgeErrors)
+	labeledCounters.Put(BridgeErrors, bridgeErrors)
+
+	lookupCacheHits := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(LookupCacheHits),
+			ConstLabels: labels,
+		},
+		[]string{"lookup"},
+	)
+	prometheus.MustRegister(lookupCacheHits)
+	labeledCounters.Put(LookupCacheHits, lookupCacheHits)
+
+	lookupErrors := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace:   namespace,
+			Name:        string(LookupErrors),
+			ConstLabels: labels,
+		},
+		[]string{"lookup"},
+	)
+	prometheus.MustRegister(lookupErrors)
+	labeledCounters.Put(LookupErrors, lookupErrors)
+
 	generic := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name:        string(Generic),
@@ -163,6 +525,16 @@ func (m metricsCollector) IncrementCounter(name Name) {
 	}
 }
 
+// IncrementLabeledCounter increments the counter of the label, i.e. a route, target or lookup name.
+func (m metricsCollector) IncrementLabeledCounter(name Name, label string) {
+	if counter, ok := labeledCounters.Get(name); ok {
+		counter.WithLabelValues(label).Inc()
+	} else {
+		log.Warnf("missing labeled metric collector %s, fallback to generic metric collector", name)
+		genericCounter.WithLabelValues(string(name)).Inc()
+	}
+}
+
 func (m metricsCollector) Record(name Name, value int) {
 	if summary, ok := summaries.Get(name); ok {
 		summary.Observe(float64(value))
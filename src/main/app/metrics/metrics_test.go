@@ -12,6 +12,12 @@ func TestMetricsCollector_IncrementCounter(t *testing.T) {
 	t.Log("done")
 }
 
+func TestMetricsCollector_IncrementLabeledCounter(t *testing.T) {
+	metrics.Collector.IncrementLabeledCounter(metrics.RouteMessages, "orders")
+	metrics.Collector.IncrementLabeledCounter("fallback", "orders")
+	t.Log("done")
+}
+
 func TestMetricsCollector_RecordE(t *testing.T) {
 	metrics.Collector.Record(metrics.CurrentWorkers, 2000)
 	metrics.Collector.Record("fallback", 2000)
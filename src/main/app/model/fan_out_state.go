@@ -0,0 +1,12 @@
+package model
+
+import "encoding/json"
+
+// FanOutStateDTO is the delivery state of a message pushed to several targets.
+type FanOutStateDTO struct {
+	Delivered []string `json:"delivered,omitempty"`
+}
+
+func (f FanOutStateDTO) MarshalBinary() ([]byte, error) {
+	return json.Marshal(f)
+}
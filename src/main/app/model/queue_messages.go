@@ -0,0 +1,64 @@
+package model
+
+// SendMessagesDTO  Model
+// swagger:model SendMessagesDTO
+type SendMessagesDTO struct {
+	Messages []SendMessageDTO `json:"messages"`
+}
+
+type SendMessageDTO struct {
+	Body         string            `json:"body"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	DelaySeconds int               `json:"delay_seconds,omitempty"`
+}
+
+// SendResultsDTO  Model
+// swagger:model SendResultsDTO
+type SendResultsDTO struct {
+	Results []SendResultDTO `json:"results"`
+}
+
+type SendResultDTO struct {
+	MessageID string `json:"message_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// RedriveDTO  Model
+// swagger:model RedriveDTO
+type RedriveDTO struct {
+	Destination string            `json:"destination"`
+	MaxMessages int               `json:"max_messages,omitempty"`
+	Rate        float64           `json:"rate,omitempty"`
+	Filter      *RedriveFilterDTO `json:"filter,omitempty"`
+	DryRun      bool              `json:"dry_run,omitempty"`
+}
+
+type RedriveFilterDTO struct {
+	BodyContains string            `json:"body_contains,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+}
+
+// RedriveResultDTO  Model
+// swagger:model RedriveResultDTO
+type RedriveResultDTO struct {
+	Matched int  `json:"matched"`
+	Moved   int  `json:"moved"`
+	Skipped int  `json:"skipped"`
+	Failed  int  `json:"failed"`
+	DryRun  bool `json:"dry_run"`
+}
+
+// PeekDTO  Model
+// swagger:model PeekDTO
+type PeekDTO struct {
+	Queue    string           `json:"queue"`
+	Count    *int             `json:"count,omitempty"`
+	Messages []PeekMessageDTO `json:"messages"`
+}
+
+type PeekMessageDTO struct {
+	MessageID    string            `json:"message_id"`
+	ReceiveCount int               `json:"receive_count,omitempty"`
+	Attributes   map[string]string `json:"attributes,omitempty"`
+	Body         any               `json:"body"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "encoding/json"
+
+// SplitStateDTO is the delivery state of a message split into several parts, by part index.
+type SplitStateDTO struct {
+	Delivered []int `json:"delivered,omitempty"`
+}
+
+func (s SplitStateDTO) MarshalBinary() ([]byte, error) {
+	return json.Marshal(s)
+}
@@ -0,0 +1,92 @@
+package pusher
+
+import (
+	"encoding/json"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+)
+
+// BatchPusher pushes several messages at once, with an error by message, nil for the succeeded ones.
+type BatchPusher interface {
+	SendBatch(messages []*queue.MessageDTO) []error
+}
+
+// HTTPBatchPusher posts the messages as a json array of client.RequestBody, or of the json rendered by the template
+// when it is given, to a bulk endpoint.
+type HTTPBatchPusher struct {
+	batchClient client.BatchClient
+	template    *Template
+}
+
+func NewHTTPBatchPusher(batchClient client.BatchClient, template ...*Template) *HTTPBatchPusher {
+	batchPusher := &HTTPBatchPusher{
+		batchClient: batchClient,
+	}
+
+	if len(template) > 0 {
+		batchPusher.template = template[0]
+	}
+
+	return batchPusher
+}
+
+func (h HTTPBatchPusher) SendBatch(messages []*queue.MessageDTO) []error {
+	errs := make([]error, len(messages))
+
+	// the messages that can not be encoded are not posted
+	var positions []int
+	var ids []string
+	var items []json.RawMessage
+	for i, message := range messages {
+		id, item, err := h.item(message)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		positions = append(positions, i)
+		ids = append(ids, id)
+		items = append(items, item)
+	}
+
+	if len(items) > 0 {
+		metrics.Collector.Record(metrics.BatchSize, len(items))
+		log.Warnf("[pushing]: batch of %d messages", len(items))
+		for i, err := range h.batchClient.PostBatch(ids, items) {
+			errs[positions[i]] = err
+		}
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			log.Errorf("[nack]   : message id: %s, error: %s", messages[i].MessageID, err.Error())
+			countError(err)
+			continue
+		}
+		metrics.Collector.IncrementCounter(metrics.PusherSuccess)
+	}
+
+	return errs
+}
+
+// item encodes a message of the batch, a render error is a reject since a redelivery would fail again.
+func (h HTTPBatchPusher) item(message *queue.MessageDTO) (string, json.RawMessage, error) {
+	requestBody, err := newRequestBody(message)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if h.template == nil {
+		item, marshalErr := json.Marshal(requestBody)
+		return requestBody.ID, item, marshalErr
+	}
+
+	item, err := h.template.Execute(message)
+	if err != nil {
+		return "", nil, &client.RejectError{Err: err}
+	}
+
+	return requestBody.ID, item, nil
+}
@@ -0,0 +1,82 @@
+package pusher_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockBatchClient struct {
+	mock.Mock
+	ids   []string
+	items []json.RawMessage
+}
+
+func (m *MockBatchClient) PostBatch(ids []string, items []json.RawMessage) []error {
+	m.ids = ids
+	m.items = items
+	args := m.Called()
+	return args.Get(0).([]error)
+}
+
+func TestHTTPBatchPusher_SendBatch(t *testing.T) {
+	batchClient := new(MockBatchClient)
+	batchClient.On("PostBatch").Return([]error{nil, nil, errors.New("internal server error")})
+
+	errs := pusher.NewHTTPBatchPusher(batchClient).SendBatch([]*queue.MessageDTO{
+		{MessageID: "1", Body: `{"MessageId": "sns-1", "Message": "hello"}`},
+		{MessageID: "2", Body: "invalid json"},
+		{MessageID: "3", Body: "plain text", Raw: true},
+		{MessageID: "4", Body: `{"order_id": 4}`},
+	})
+
+	assert.Len(t, errs, 4)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+	assert.EqualError(t, errs[3], "internal server error")
+
+	assert.Equal(t, []string{"sns-1", "3", "4"}, batchClient.ids)
+	assert.JSONEq(t, `{"id": "sns-1", "msg": "hello"}`, string(batchClient.items[0]))
+	assert.JSONEq(t, `{"id": "3", "msg": "plain text"}`, string(batchClient.items[1]))
+	assert.JSONEq(t, `{"id": "4", "msg": "{\"order_id\": 4}"}`, string(batchClient.items[2]))
+}
+
+func TestHTTPBatchPusher_SendBatchTemplate(t *testing.T) {
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"order_id": {{json .Body.order_id}}}`})
+	assert.NoError(t, err)
+
+	batchClient := new(MockBatchClient)
+	batchClient.On("PostBatch").Return([]error{nil, nil})
+
+	errs := pusher.NewHTTPBatchPusher(batchClient, template).SendBatch([]*queue.MessageDTO{
+		{MessageID: "1", Body: `{"order_id": 1}`},
+		{MessageID: "2", Body: `{"order_id": 1, "email": "john@doe.com"}`},
+	})
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.JSONEq(t, `{"order_id": 1}`, string(batchClient.items[0]))
+	assert.JSONEq(t, `{"order_id": 1}`, string(batchClient.items[1]))
+}
+
+func TestHTTPBatchPusher_SendBatchRenderErr(t *testing.T) {
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"order_id": {{.Body.order_id}}}`})
+	assert.NoError(t, err)
+
+	batchClient := new(MockBatchClient)
+
+	errs := pusher.NewHTTPBatchPusher(batchClient, template).SendBatch([]*queue.MessageDTO{
+		{MessageID: "1", Body: `{"order_id": "order 1"}`},
+	})
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, errs[0], &rejectErr)
+	batchClient.AssertNotCalled(t, "PostBatch")
+}
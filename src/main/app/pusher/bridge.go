@@ -0,0 +1,96 @@
+package pusher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+)
+
+// Sender publishes messages, a queue.Service or a topic.
+type Sender interface {
+	Send(ctx context.Context, message queue.SendMessageDTO) (string, error)
+}
+
+type BridgeConfig struct {
+	Name   string
+	Sender Sender
+	// Template renders the published body instead of the sns Message, or of the raw payload.
+	Template *Template
+	// Attributes maps the message attributes to the published ones, the attributes not mapped are dropped.
+	// Without it every attribute is published as it is.
+	Attributes map[string]string
+}
+
+// Bridge publishes the messages to another queue or topic instead of a target app, i.e. from sqs to a kafka topic.
+type Bridge struct {
+	name       string
+	sender     Sender
+	template   *Template
+	attributes map[string]string
+}
+
+func NewBridge(config BridgeConfig) (*Bridge, error) {
+	if config.Name == "" || config.Sender == nil {
+		return nil, errors.New("bridge: name and sender are required")
+	}
+
+	return &Bridge{
+		name:       config.Name,
+		sender:     config.Sender,
+		template:   config.Template,
+		attributes: config.Attributes,
+	}, nil
+}
+
+// SendMessage publishes the sns Message, or the raw payload, the sns envelope is not bridged. A render error is a
+// reject since a redelivery would fail again.
+func (b Bridge) SendMessage(message *queue.MessageDTO) error {
+	body := newTemplateData(message).Message
+	if b.template != nil {
+		payload, err := b.template.Execute(message)
+		if err != nil {
+			err = &client.RejectError{Err: fmt.Errorf("bridge %s: %w", b.name, err)}
+			metrics.Collector.IncrementLabeledCounter(metrics.BridgeErrors, b.name)
+			countError(err)
+			return err
+		}
+		body = string(payload)
+	}
+
+	messageID, err := b.sender.Send(context.Background(), queue.SendMessageDTO{
+		Body:       body,
+		Attributes: b.mapAttributes(message.Attributes),
+	})
+	if err != nil {
+		log.Errorf("[nack]   : message id: %s, bridge: %s, error: %s", message.MessageID, b.name, err.Error())
+		metrics.Collector.IncrementLabeledCounter(metrics.BridgeErrors, b.name)
+		countError(err)
+		return fmt.Errorf("bridge %s: %w", b.name, err)
+	}
+
+	log.Infof("[ack]    : message id: %s, bridge: %s, published message id: %s", message.MessageID, b.name, messageID)
+	metrics.Collector.IncrementLabeledCounter(metrics.BridgePublished, b.name)
+	metrics.Collector.IncrementCounter(metrics.PusherSuccess)
+
+	return nil
+}
+
+func (b Bridge) mapAttributes(attributes map[string]string) map[string]string {
+	if b.attributes == nil {
+		return attributes
+	}
+
+	mapped := make(map[string]string, len(b.attributes))
+	for name, value := range attributes {
+		if mappedName, found := b.attributes[name]; found {
+			mapped[mappedName] = value
+		}
+	}
+
+	return mapped
+}
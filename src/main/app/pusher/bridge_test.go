@@ -0,0 +1,84 @@
+package pusher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBridge(t *testing.T, template *pusher.Template, attributes map[string]string) (*pusher.Bridge, *queue.MemoryQueueService) {
+	destination, err := queue.NewMemoryClient(queue.MemoryConfig{Name: "orders-bridged", Parallel: 10, Timeout: 50},
+		queue.NewMemoryBroker())
+	assert.NoError(t, err)
+
+	bridge, err := pusher.NewBridge(pusher.BridgeConfig{
+		Name:       "orders",
+		Sender:     destination,
+		Template:   template,
+		Attributes: attributes,
+	})
+	assert.NoError(t, err)
+
+	return bridge, destination
+}
+
+func TestNewBridgeErr(t *testing.T) {
+	_, err := pusher.NewBridge(pusher.BridgeConfig{Name: "orders"})
+	assert.Error(t, err)
+}
+
+func TestBridge_SendMessage(t *testing.T) {
+	bridge, destination := newBridge(t, nil, nil)
+
+	err := bridge.SendMessage(&queue.MessageDTO{
+		MessageID:  "1",
+		Body:       `{"MessageId": "sns-1", "Message": "{\"order_id\": 1}"}`,
+		Attributes: map[string]string{"event": "created"},
+	})
+	assert.NoError(t, err)
+
+	messages, err := destination.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.JSONEq(t, `{"order_id": 1}`, messages[0].Body)
+	assert.Equal(t, map[string]string{"event": "created"}, messages[0].Attributes)
+}
+
+func TestBridge_SendMessageAttributeMapping(t *testing.T) {
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"id": {{json .Body.order_id}}}`})
+	assert.NoError(t, err)
+
+	bridge, destination := newBridge(t, template, map[string]string{"event": "event-type"})
+
+	err = bridge.SendMessage(&queue.MessageDTO{
+		MessageID:  "1",
+		Body:       `{"order_id": 1}`,
+		Attributes: map[string]string{"event": "created", "retry-attempt": "2"},
+	})
+	assert.NoError(t, err)
+
+	messages, err := destination.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id": 1}`, messages[0].Body)
+	assert.Equal(t, map[string]string{"event-type": "created"}, messages[0].Attributes)
+}
+
+func TestBridge_SendMessageErr(t *testing.T) {
+	bridge, err := pusher.NewBridge(pusher.BridgeConfig{Name: "orders", Sender: failingSender{}})
+	assert.NoError(t, err)
+
+	err = bridge.SendMessage(&queue.MessageDTO{MessageID: "1", Body: `{"order_id": 1}`})
+	assert.Error(t, err)
+
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"id": {{.Body.order_id}}}`})
+	assert.NoError(t, err)
+	bridge, _ = newBridge(t, template, nil)
+
+	err = bridge.SendMessage(&queue.MessageDTO{MessageID: "1", Body: `{"order_id": "order 1"}`})
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+}
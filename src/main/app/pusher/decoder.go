@@ -0,0 +1,156 @@
+package pusher
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/codec"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+)
+
+type PayloadEncoding string
+
+const (
+	// Base64Encoding is a base64 binary payload, i.e. the Message of a sns notification.
+	Base64Encoding PayloadEncoding = "base64"
+	// RawEncoding is a binary payload as it is, i.e. the body of a raw sqs message.
+	RawEncoding PayloadEncoding = "raw"
+)
+
+type DecoderOutput string
+
+const (
+	// JSONOutput replaces the binary payload with its json.
+	JSONOutput DecoderOutput = "json"
+	// BinaryOutput keeps the original binary payload, the next pusher is a BinaryPusher.
+	BinaryOutput DecoderOutput = "binary"
+)
+
+type DecoderConfig struct {
+	// Resolver is a codec.Fixed of a local descriptor or a codec.Registry.
+	Resolver codec.Resolver
+	Encoding PayloadEncoding
+	Output   DecoderOutput
+}
+
+// Decoder decodes the avro or protobuf binary payload of the sns Message, or of the raw payload, before the push.
+// A payload that can not be decoded is rejected, a redelivery would fail again.
+type Decoder struct {
+	resolver codec.Resolver
+	encoding PayloadEncoding
+	output   DecoderOutput
+	next     Pusher
+}
+
+func NewDecoder(config DecoderConfig, next Pusher) (*Decoder, error) {
+	if config.Resolver == nil {
+		return nil, errors.New("decoder: resolver is required")
+	}
+
+	switch config.Encoding {
+	case "":
+		config.Encoding = Base64Encoding
+	case Base64Encoding, RawEncoding:
+	default:
+		return nil, fmt.Errorf("decoder: invalid encoding %s", config.Encoding)
+	}
+
+	switch config.Output {
+	case "":
+		config.Output = JSONOutput
+	case JSONOutput, BinaryOutput:
+	default:
+		return nil, fmt.Errorf("decoder: invalid output %s", config.Output)
+	}
+
+	return &Decoder{
+		resolver: config.Resolver,
+		encoding: config.Encoding,
+		output:   config.Output,
+		next:     next,
+	}, nil
+}
+
+func (d Decoder) SendMessage(message *queue.MessageDTO) error {
+	payload := message.Body
+
+	var messageDTO MessageDTO
+	notification := json.Unmarshal([]byte(message.Body), &messageDTO) == nil && messageDTO.Message != ""
+	if notification {
+		payload = messageDTO.Message
+	}
+
+	binaryPayload := []byte(payload)
+	if d.encoding == Base64Encoding {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return d.reject(message, err)
+		}
+		binaryPayload = decoded
+	}
+
+	payloadCodec, encoded, err := d.resolver.Resolve(binaryPayload)
+	if err != nil {
+		if errors.Is(err, codec.ErrWireFormat) || errors.Is(err, codec.ErrSchemaNotFound) {
+			return d.reject(message, err)
+		}
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	jsonPayload, err := payloadCodec.JSON(encoded)
+	if err != nil {
+		return d.reject(message, err)
+	}
+
+	decoded := *message
+	switch {
+	case d.output == BinaryOutput:
+		decoded.Body = string(binaryPayload)
+	case notification:
+		if decoded.Body, err = replacedBody(message.Body, jsonPayload); err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+	default:
+		decoded.Body = string(jsonPayload)
+	}
+
+	return d.next.SendMessage(&decoded)
+}
+
+func (d Decoder) reject(message *queue.MessageDTO, err error) error {
+	log.Warnf("decode: message %s: %s", message.MessageID, err)
+	metrics.Collector.IncrementCounter(metrics.DecodeErrors)
+
+	return &client.RejectError{Err: fmt.Errorf("decode: %w", err)}
+}
+
+// BinaryPusher pushes the body as it is, i.e. the original avro or protobuf payload of a Decoder.
+type BinaryPusher struct {
+	httpClient client.AppClient
+}
+
+func NewBinaryPusher(httpClient client.AppClient) *BinaryPusher {
+	return &BinaryPusher{
+		httpClient: httpClient,
+	}
+}
+
+func (b BinaryPusher) SendMessage(message *queue.MessageDTO) error {
+	log.Warnf("[pushing]: message id: %s, binary: %d bytes", message.MessageID, len(message.Body))
+
+	if err := b.httpClient.PostBinary(message.MessageID, []byte(message.Body)); err != nil {
+		log.Errorf("[nack]   : message id: %s, binary: %d bytes", message.MessageID, len(message.Body))
+		countError(err)
+		return err
+	}
+
+	log.Infof("[ack]    : message id: %s, binary: %d bytes", message.MessageID, len(message.Body))
+	metrics.Collector.IncrementCounter(metrics.PusherSuccess)
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package pusher_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/codec"
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/stretchr/testify/assert"
+)
+
+const orderJSON = `{"order_id": 1, "customer_id": 2, "status": "created"}`
+
+func newAvroResolver(t *testing.T) (codec.Resolver, []byte) {
+	schema, err := os.ReadFile(config.Resource("schemas/order_created.avsc"))
+	assert.NoError(t, err)
+
+	avroCodec, err := goavro.NewCodec(string(schema))
+	assert.NoError(t, err)
+	payload, err := avroCodec.BinaryFromNative(nil, map[string]any{
+		"order_id": int64(1), "customer_id": int64(2), "status": "created",
+	})
+	assert.NoError(t, err)
+
+	resolver, err := codec.NewAvro(schema)
+	assert.NoError(t, err)
+
+	return codec.Fixed(resolver), payload
+}
+
+func TestNewDecoderErr(t *testing.T) {
+	resolver, _ := newAvroResolver(t)
+
+	_, err := pusher.NewDecoder(pusher.DecoderConfig{}, new(MockMessagePusher))
+	assert.Error(t, err)
+
+	_, err = pusher.NewDecoder(pusher.DecoderConfig{Resolver: resolver, Encoding: "hex"}, new(MockMessagePusher))
+	assert.Error(t, err)
+
+	_, err = pusher.NewDecoder(pusher.DecoderConfig{Resolver: resolver, Output: "xml"}, new(MockMessagePusher))
+	assert.Error(t, err)
+}
+
+func TestDecoder_SendMessageNotification(t *testing.T) {
+	resolver, payload := newAvroResolver(t)
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	decoder, err := pusher.NewDecoder(pusher.DecoderConfig{Resolver: resolver}, next)
+	assert.NoError(t, err)
+
+	err = decoder.SendMessage(&queue.MessageDTO{
+		MessageID: "1",
+		Body:      fmt.Sprintf(`{"MessageId":"sns-1","Message":"%s"}`, base64.StdEncoding.EncodeToString(payload)),
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, next.messages, 1)
+	var messageDTO pusher.MessageDTO
+	assert.NoError(t, json.Unmarshal([]byte(next.messages[0].Body), &messageDTO))
+	assert.Equal(t, "sns-1", messageDTO.ID)
+	assert.JSONEq(t, orderJSON, messageDTO.Message)
+}
+
+func TestDecoder_SendMessageRaw(t *testing.T) {
+	resolver, payload := newAvroResolver(t)
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	decoder, err := pusher.NewDecoder(pusher.DecoderConfig{Resolver: resolver, Encoding: pusher.RawEncoding}, next)
+	assert.NoError(t, err)
+
+	err = decoder.SendMessage(&queue.MessageDTO{MessageID: "1", Body: string(payload)})
+	assert.NoError(t, err)
+
+	assert.Len(t, next.messages, 1)
+	assert.JSONEq(t, orderJSON, next.messages[0].Body)
+}
+
+func TestDecoder_SendMessageBinary(t *testing.T) {
+	resolver, payload := newAvroResolver(t)
+	httpClient := new(MockHTTPClient)
+	httpClient.On("PostBinary", "1", payload).Return(nil)
+
+	decoder, err := pusher.NewDecoder(pusher.DecoderConfig{
+		Resolver: resolver,
+		Output:   pusher.BinaryOutput,
+	}, pusher.NewBinaryPusher(httpClient))
+	assert.NoError(t, err)
+
+	err = decoder.SendMessage(&queue.MessageDTO{MessageID: "1", Body: base64.StdEncoding.EncodeToString(payload)})
+	assert.NoError(t, err)
+	httpClient.AssertCalled(t, "PostBinary", "1", payload)
+}
+
+func TestDecoder_SendMessageReject(t *testing.T) {
+	resolver, _ := newAvroResolver(t)
+	next := new(MockMessagePusher)
+
+	decoder, err := pusher.NewDecoder(pusher.DecoderConfig{Resolver: resolver}, next)
+	assert.NoError(t, err)
+
+	for _, body := range []string{"not base64", base64.StdEncoding.EncodeToString([]byte{0x02})} {
+		err = decoder.SendMessage(&queue.MessageDTO{MessageID: "1", Body: body})
+		var rejectErr *client.RejectError
+		assert.ErrorAs(t, err, &rejectErr)
+	}
+
+	next.AssertNotCalled(t, "SendMessage")
+}
+
+func TestBinaryPusher_SendMessageErr(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpClient.On("PostBinary", "1", []byte{0x01}).Return(errors.New("timeout"))
+
+	err := pusher.NewBinaryPusher(httpClient).SendMessage(&queue.MessageDTO{MessageID: "1", Body: string([]byte{0x01})})
+	assert.Error(t, err)
+}
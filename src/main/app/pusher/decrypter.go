@@ -0,0 +1,144 @@
+package pusher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/encryption"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+)
+
+// defaultDecryptTimeout bounds the key provider calls of a message without DecrypterConfig.Timeout.
+const defaultDecryptTimeout = time.Second * 5
+
+type DecrypterConfig struct {
+	KeyProvider encryption.KeyProvider
+	// Fields are dot paths of the encrypted fields of the json payload, without them the payload is an envelope.
+	Fields []string
+	// Timeout of the key provider calls of a message, i.e. aws kms, 5s by default.
+	Timeout time.Duration
+}
+
+// Decrypter opens the encryption.Envelope of the sns Message, or of the raw payload, or of some of its fields,
+// before the transformation and the push. The decrypted messages are Sensitive, the plaintext is never logged.
+type Decrypter struct {
+	keyProvider encryption.KeyProvider
+	fields      [][]string
+	timeout     time.Duration
+	next        Pusher
+}
+
+func NewDecrypter(config DecrypterConfig, next Pusher) (*Decrypter, error) {
+	if config.KeyProvider == nil {
+		return nil, errors.New("decrypter: key provider is required")
+	}
+
+	fields := make([][]string, len(config.Fields))
+	for i, field := range config.Fields {
+		if field == "" {
+			return nil, errors.New("decrypter: empty field")
+		}
+		fields[i] = strings.Split(field, ".")
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = defaultDecryptTimeout
+	}
+
+	return &Decrypter{
+		keyProvider: config.KeyProvider,
+		fields:      fields,
+		timeout:     timeout,
+		next:        next,
+	}, nil
+}
+
+func (d Decrypter) SendMessage(message *queue.MessageDTO) error {
+	data := newTemplateData(message)
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+
+	var payload []byte
+	var err error
+	if len(d.fields) == 0 {
+		payload, err = d.open(ctx, json.RawMessage(data.Message))
+	} else {
+		payload, err = d.openFields(ctx, data.Body)
+	}
+
+	if err != nil {
+		metrics.Collector.IncrementCounter(metrics.DecryptErrors)
+		if errors.Is(err, encryption.ErrInvalidEnvelope) {
+			log.Warnf("decrypt: message %s: %s", message.MessageID, err)
+			return &client.RejectError{Err: fmt.Errorf("decrypt: %w", err)}
+		}
+		return fmt.Errorf("decrypt: %w", err)
+	}
+
+	decrypted := *message
+	decrypted.Sensitive = true
+	if decrypted.Body, err = replacedBody(message.Body, payload); err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+
+	return d.next.SendMessage(&decrypted)
+}
+
+func (d Decrypter) open(ctx context.Context, value json.RawMessage) ([]byte, error) {
+	var envelope encryption.Envelope
+	if err := json.Unmarshal(value, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: not an envelope", encryption.ErrInvalidEnvelope)
+	}
+
+	return encryption.Open(ctx, d.keyProvider, envelope)
+}
+
+// openFields replaces the envelopes of the fields with their plaintext, as json when it is json, the missing
+// fields are skipped.
+func (d Decrypter) openFields(ctx context.Context, body any) ([]byte, error) {
+	payload, ok := body.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: payload is not a json object", encryption.ErrInvalidEnvelope)
+	}
+
+	for _, path := range d.fields {
+		parent := payload
+		for _, key := range path[:len(path)-1] {
+			if parent, ok = parent[key].(map[string]any); !ok {
+				break
+			}
+		}
+
+		field := path[len(path)-1]
+		if parent == nil || parent[field] == nil {
+			continue
+		}
+
+		value, err := json.Marshal(parent[field])
+		if err != nil {
+			return nil, err
+		}
+
+		plaintext, err := d.open(ctx, value)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", strings.Join(path, "."), err)
+		}
+
+		if json.Valid(plaintext) {
+			parent[field] = json.RawMessage(plaintext)
+		} else {
+			parent[field] = string(plaintext)
+		}
+	}
+
+	return json.Marshal(payload)
+}
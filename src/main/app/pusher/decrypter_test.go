@@ -0,0 +1,157 @@
+package pusher_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/encryption"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingKeyProvider struct{}
+
+func (failingKeyProvider) DataKey(context.Context, string, []byte) ([]byte, error) {
+	return nil, errors.New("throttling")
+}
+
+// blockingKeyProvider waits for the deadline of the call, as a kms request that does not answer.
+type blockingKeyProvider struct{}
+
+func (blockingKeyProvider) DataKey(ctx context.Context, _ string, _ []byte) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newKeyProvider(t *testing.T) *encryption.StaticKeyProvider {
+	provider, err := encryption.NewStaticKeyProvider(map[string][]byte{"": bytes.Repeat([]byte{0x01}, 32)})
+	assert.NoError(t, err)
+	return provider
+}
+
+func seal(t *testing.T, provider *encryption.StaticKeyProvider, plaintext string) string {
+	envelope, err := provider.Seal("", []byte(plaintext))
+	assert.NoError(t, err)
+	encoded, err := json.Marshal(envelope)
+	assert.NoError(t, err)
+	return string(encoded)
+}
+
+func TestNewDecrypterErr(t *testing.T) {
+	_, err := pusher.NewDecrypter(pusher.DecrypterConfig{}, new(MockMessagePusher))
+	assert.Error(t, err)
+
+	_, err = pusher.NewDecrypter(pusher.DecrypterConfig{KeyProvider: newKeyProvider(t), Fields: []string{""}},
+		new(MockMessagePusher))
+	assert.Error(t, err)
+}
+
+func TestDecrypter_SendMessageNotification(t *testing.T) {
+	provider := newKeyProvider(t)
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	decrypter, err := pusher.NewDecrypter(pusher.DecrypterConfig{KeyProvider: provider}, next)
+	assert.NoError(t, err)
+
+	body, err := json.Marshal(pusher.MessageDTO{ID: "sns-1", Message: seal(t, provider, `{"email":"john@doe.com"}`)})
+	assert.NoError(t, err)
+
+	message := &queue.MessageDTO{MessageID: "1", Body: string(body)}
+	assert.NoError(t, decrypter.SendMessage(message))
+
+	assert.False(t, message.Sensitive)
+	assert.Len(t, next.messages, 1)
+	assert.True(t, next.messages[0].Sensitive)
+
+	var messageDTO pusher.MessageDTO
+	assert.NoError(t, json.Unmarshal([]byte(next.messages[0].Body), &messageDTO))
+	assert.Equal(t, "sns-1", messageDTO.ID)
+	assert.JSONEq(t, `{"email":"john@doe.com"}`, messageDTO.Message)
+}
+
+func TestDecrypter_SendMessageRaw(t *testing.T) {
+	provider := newKeyProvider(t)
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	decrypter, err := pusher.NewDecrypter(pusher.DecrypterConfig{KeyProvider: provider}, next)
+	assert.NoError(t, err)
+
+	assert.NoError(t, decrypter.SendMessage(&queue.MessageDTO{MessageID: "1", Body: seal(t, provider, "john@doe.com")}))
+	assert.Equal(t, "john@doe.com", next.messages[0].Body)
+}
+
+func TestDecrypter_SendMessageFields(t *testing.T) {
+	provider := newKeyProvider(t)
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	decrypter, err := pusher.NewDecrypter(pusher.DecrypterConfig{
+		KeyProvider: provider,
+		Fields:      []string{"customer.email", "customer.address", "payment.card"},
+	}, next)
+	assert.NoError(t, err)
+
+	body := fmt.Sprintf(`{"order_id": 1, "customer": {"email": %s, "address": %s}}`,
+		seal(t, provider, "john@doe.com"), seal(t, provider, `{"city": "Madrid"}`))
+	assert.NoError(t, decrypter.SendMessage(&queue.MessageDTO{MessageID: "1", Body: body}))
+
+	assert.JSONEq(t, `{"order_id": 1, "customer": {"email": "john@doe.com", "address": {"city": "Madrid"}}}`,
+		next.messages[0].Body)
+}
+
+func TestDecrypter_SendMessageReject(t *testing.T) {
+	provider := newKeyProvider(t)
+	next := new(MockMessagePusher)
+
+	decrypter, err := pusher.NewDecrypter(pusher.DecrypterConfig{KeyProvider: provider}, next)
+	assert.NoError(t, err)
+
+	other, err := encryption.NewStaticKeyProvider(map[string][]byte{"": bytes.Repeat([]byte{0x02}, 32)})
+	assert.NoError(t, err)
+
+	for _, body := range []string{"john@doe.com", seal(t, other, "john@doe.com")} {
+		err = decrypter.SendMessage(&queue.MessageDTO{MessageID: "1", Body: body})
+		var rejectErr *client.RejectError
+		assert.ErrorAs(t, err, &rejectErr)
+		assert.NotContains(t, err.Error(), "doe.com")
+	}
+
+	next.AssertNotCalled(t, "SendMessage")
+}
+
+func TestDecrypter_SendMessageKeyProviderErr(t *testing.T) {
+	provider := newKeyProvider(t)
+	next := new(MockMessagePusher)
+
+	decrypter, err := pusher.NewDecrypter(pusher.DecrypterConfig{KeyProvider: failingKeyProvider{}}, next)
+	assert.NoError(t, err)
+
+	err = decrypter.SendMessage(&queue.MessageDTO{MessageID: "1", Body: seal(t, provider, "john@doe.com")})
+	assert.Error(t, err)
+	var rejectErr *client.RejectError
+	assert.False(t, errors.As(err, &rejectErr))
+}
+
+func TestDecrypter_SendMessageTimeout(t *testing.T) {
+	provider := newKeyProvider(t)
+	next := new(MockMessagePusher)
+
+	decrypter, err := pusher.NewDecrypter(pusher.DecrypterConfig{
+		KeyProvider: blockingKeyProvider{},
+		Timeout:     time.Millisecond * 10,
+	}, next)
+	assert.NoError(t, err)
+
+	err = decrypter.SendMessage(&queue.MessageDTO{MessageID: "1", Body: seal(t, provider, "john@doe.com")})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	next.AssertNotCalled(t, "SendMessage")
+}
@@ -0,0 +1,209 @@
+package pusher
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/config/env"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+)
+
+// Placeholder prefixes of an endpoint template.
+const (
+	configPlaceholder    = "config:"
+	attributePlaceholder = "attribute:"
+)
+
+// Endpoint is a target url template with placeholders:
+//   - {SCOPE} and {ENV}, the scope and environment of env.GetScope and env.GetEnv.
+//   - {config:<key>}, a config key, i.e. {config:app.name}.
+//   - {attribute:<name>}, a message attribute.
+//   - {<path>}, a field of the sns Message json, or of the raw payload, by dot path, i.e. /tenants/{tenant_id}/orders.
+//
+// The environment and config placeholders are resolved by ParseEndpoint, the message ones by Resolve, path escaped, or
+// query escaped after the ?.
+// The other upper case placeholders are unknown environment variables, i.e. {MY_APP}, and fail the parse.
+type Endpoint struct {
+	parts []endpointPart
+}
+
+// endpointPart is a resolved text, or a message attribute or field.
+type endpointPart struct {
+	text      string
+	attribute string
+	field     string
+}
+
+func ParseEndpoint(endpoint string) (*Endpoint, error) {
+	parsed := &Endpoint{}
+	var text strings.Builder
+
+	for rest := endpoint; rest != ""; {
+		start := strings.IndexAny(rest, "{}")
+		if start < 0 {
+			text.WriteString(rest)
+			break
+		}
+
+		if rest[start] == '}' {
+			return nil, fmt.Errorf("endpoint %s: unexpected }", endpoint)
+		}
+
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("endpoint %s: unclosed placeholder", endpoint)
+		}
+
+		text.WriteString(rest[:start])
+		name := rest[start+1 : start+end]
+		rest = rest[start+end+1:]
+
+		value, part, err := placeholder(name)
+		if err != nil {
+			return nil, fmt.Errorf("endpoint %s: %w", endpoint, err)
+		}
+
+		if part == nil {
+			text.WriteString(value)
+			continue
+		}
+
+		if text.Len() > 0 {
+			parsed.parts = append(parsed.parts, endpointPart{text: text.String()})
+			text.Reset()
+		}
+		parsed.parts = append(parsed.parts, *part)
+	}
+
+	if text.Len() > 0 {
+		parsed.parts = append(parsed.parts, endpointPart{text: text.String()})
+	}
+
+	return parsed, nil
+}
+
+// placeholder returns the value of a startup placeholder, or the message part to resolve per message.
+func placeholder(name string) (string, *endpointPart, error) {
+	switch {
+	case name == "":
+		return "", nil, errors.New("empty placeholder")
+	case name == "SCOPE", name == "ENV":
+		value := env.GetScope()
+		if name == "ENV" {
+			value = env.GetEnv()
+		}
+		if value == "" {
+			return "", nil, fmt.Errorf("placeholder %s is empty", name)
+		}
+		return value, nil, nil
+	case strings.HasPrefix(name, configPlaceholder):
+		key := strings.TrimPrefix(name, configPlaceholder)
+		value := config.TryString(key, "")
+		if value == "" {
+			return "", nil, fmt.Errorf("placeholder %s: config %s not found", name, key)
+		}
+		return value, nil, nil
+	case strings.HasPrefix(name, attributePlaceholder):
+		attribute := strings.TrimPrefix(name, attributePlaceholder)
+		if attribute == "" {
+			return "", nil, fmt.Errorf("placeholder %s: attribute name is required", name)
+		}
+		return "", &endpointPart{attribute: attribute}, nil
+	case strings.ToUpper(name) == name && !strings.Contains(name, "."):
+		return "", nil, fmt.Errorf("unknown placeholder %s", name)
+	default:
+		return "", &endpointPart{field: name}, nil
+	}
+}
+
+// Static is an endpoint without message placeholders.
+func (e Endpoint) Static() bool {
+	for _, part := range e.parts {
+		if part.attribute != "" || part.field != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String is the endpoint with the startup placeholders resolved, the message ones are kept.
+func (e Endpoint) String() string {
+	var endpoint strings.Builder
+	for _, part := range e.parts {
+		switch {
+		case part.attribute != "":
+			endpoint.WriteString("{" + attributePlaceholder + part.attribute + "}")
+		case part.field != "":
+			endpoint.WriteString("{" + part.field + "}")
+		default:
+			endpoint.WriteString(part.text)
+		}
+	}
+
+	return endpoint.String()
+}
+
+// Resolve fails when the message has not an attribute or a field, or it is a json object or array.
+func (e Endpoint) Resolve(message *queue.MessageDTO) (string, error) {
+	envelope := newRoutingEnvelope(message)
+
+	escape := url.PathEscape
+	var endpoint strings.Builder
+	for _, part := range e.parts {
+		switch {
+		case part.attribute != "":
+			value, found := envelope.attributes[part.attribute]
+			if !found || value == "" {
+				return "", fmt.Errorf("attribute %s not found", part.attribute)
+			}
+			endpoint.WriteString(escape(value))
+		case part.field != "":
+			value, found := envelope.field(part.field)
+			if !found || value == "" {
+				return "", fmt.Errorf("field %s not found", part.field)
+			}
+			endpoint.WriteString(escape(value))
+		default:
+			endpoint.WriteString(part.text)
+			if strings.Contains(part.text, "?") {
+				escape = url.QueryEscape
+			}
+		}
+	}
+
+	return endpoint.String(), nil
+}
+
+// EndpointPusher pushes each message to its resolved endpoint with the pusher of newPusher, i.e. an HTTPPusher. A
+// message without the placeholders of the endpoint is rejected since a redelivery would fail again.
+type EndpointPusher struct {
+	endpoint  *Endpoint
+	newPusher func(endpoint string) Pusher
+}
+
+func NewEndpointPusher(endpoint *Endpoint, newPusher func(endpoint string) Pusher) *EndpointPusher {
+	return &EndpointPusher{
+		endpoint:  endpoint,
+		newPusher: newPusher,
+	}
+}
+
+func (e EndpointPusher) SendMessage(message *queue.MessageDTO) error {
+	endpoint, err := e.endpoint.Resolve(message)
+	if err != nil {
+		log.Warnf("endpoint: message %s: %s", message.MessageID, err)
+		metrics.Collector.IncrementCounter(metrics.EndpointErrors)
+		err = &client.RejectError{Err: fmt.Errorf("endpoint %s: %w", e.endpoint, err)}
+		countError(err)
+		return err
+	}
+
+	return e.newPusher(endpoint).SendMessage(message)
+}
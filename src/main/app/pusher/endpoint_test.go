@@ -0,0 +1,134 @@
+package pusher_test
+
+import (
+	"testing"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEndpoint(t *testing.T) {
+	t.Setenv("SCOPE", "Test")
+
+	endpoint, err := pusher.ParseEndpoint("https://{config:app.name}.{SCOPE}.internal.com/{ENV}/orders")
+
+	assert.NoError(t, err)
+	assert.True(t, endpoint.Static())
+	assert.Equal(t, "https://go-consumer-app.test.internal.com/prod/orders", endpoint.String())
+}
+
+func TestParseEndpoint_Static(t *testing.T) {
+	endpoint, err := pusher.ParseEndpoint("http://localhost:4000/orders-consumer")
+
+	assert.NoError(t, err)
+	assert.True(t, endpoint.Static())
+	assert.Equal(t, "http://localhost:4000/orders-consumer", endpoint.String())
+}
+
+func TestParseEndpointErr(t *testing.T) {
+	t.Setenv("SCOPE", "")
+
+	for _, endpoint := range []string{
+		"https://{MY_APP}.internal.com/orders",
+		"https://orders.{SCOPE}.internal.com/orders",
+		"https://{config:orders.missing}.internal.com/orders",
+		"https://orders.internal.com/{}",
+		"https://orders.internal.com/{tenant_id/orders",
+		"https://orders.internal.com/tenant_id}/orders",
+		"https://orders.internal.com/{attribute:}/orders",
+	} {
+		_, err := pusher.ParseEndpoint(endpoint)
+		assert.Error(t, err, endpoint)
+	}
+}
+
+func TestEndpoint_Resolve(t *testing.T) {
+	endpoint, err := pusher.ParseEndpoint("https://orders.internal.com/tenants/{tenant.id}/{attribute:event}/{id}")
+	assert.NoError(t, err)
+	assert.False(t, endpoint.Static())
+	assert.Equal(t, "https://orders.internal.com/tenants/{tenant.id}/{attribute:event}/{id}", endpoint.String())
+
+	resolved, err := endpoint.Resolve(&queue.MessageDTO{
+		Body:       `{"MessageId":"123","Message":"{\"id\":1,\"tenant\":{\"id\":\"es/01\"}}"}`,
+		Attributes: map[string]string{"event": "created"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://orders.internal.com/tenants/es%2F01/created/1", resolved)
+}
+
+func TestEndpoint_ResolveQuery(t *testing.T) {
+	endpoint, err := pusher.ParseEndpoint("https://orders.internal.com/tenants/{tenant_id}/orders?customer={customer}&event={attribute:event}")
+	assert.NoError(t, err)
+
+	resolved, err := endpoint.Resolve(&queue.MessageDTO{
+		Body:       `{"tenant_id":"es/01","customer":"john doe&admin=true"}`,
+		Attributes: map[string]string{"event": "order created"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://orders.internal.com/tenants/es%2F01/orders?customer=john+doe%26admin%3Dtrue&event=order+created", resolved)
+}
+
+func TestEndpoint_ResolveRaw(t *testing.T) {
+	endpoint, err := pusher.ParseEndpoint("https://orders.internal.com/tenants/{tenant_id}/orders")
+	assert.NoError(t, err)
+
+	resolved, err := endpoint.Resolve(&queue.MessageDTO{Body: `{"tenant_id":"es"}`})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://orders.internal.com/tenants/es/orders", resolved)
+}
+
+func TestEndpoint_ResolveErr(t *testing.T) {
+	endpoint, err := pusher.ParseEndpoint("https://orders.internal.com/tenants/{tenant_id}/{attribute:event}")
+	assert.NoError(t, err)
+
+	for _, message := range []*queue.MessageDTO{
+		{Body: `{"order_id":1}`, Attributes: map[string]string{"event": "created"}},
+		{Body: `{"tenant_id":{"id":"es"}}`, Attributes: map[string]string{"event": "created"}},
+		{Body: `{"tenant_id":"es"}`},
+		{Body: "orders"},
+	} {
+		_, err = endpoint.Resolve(message)
+		assert.Error(t, err, message.Body)
+	}
+}
+
+func TestEndpointPusher(t *testing.T) {
+	endpoint, err := pusher.ParseEndpoint("https://orders.internal.com/tenants/{tenant_id}/orders")
+	assert.NoError(t, err)
+
+	tenantPusher := new(MockPusher)
+	tenantPusher.On("SendMessage").Return(nil)
+
+	var endpoints []string
+	endpointPusher := pusher.NewEndpointPusher(endpoint, func(endpoint string) pusher.Pusher {
+		endpoints = append(endpoints, endpoint)
+		return tenantPusher
+	})
+
+	err = endpointPusher.SendMessage(&queue.MessageDTO{Body: `{"tenant_id":"es"}`})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://orders.internal.com/tenants/es/orders"}, endpoints)
+	tenantPusher.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestEndpointPusherErr(t *testing.T) {
+	endpoint, err := pusher.ParseEndpoint("https://orders.internal.com/tenants/{tenant_id}/orders")
+	assert.NoError(t, err)
+
+	tenantPusher := new(MockPusher)
+	endpointPusher := pusher.NewEndpointPusher(endpoint, func(string) pusher.Pusher {
+		return tenantPusher
+	})
+
+	err = endpointPusher.SendMessage(&queue.MessageDTO{Body: `{"order_id":1}`})
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+	tenantPusher.AssertNotCalled(t, "SendMessage")
+}
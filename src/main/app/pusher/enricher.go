@@ -0,0 +1,199 @@
+package pusher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/arielsrv/ikp_go-restclient/rest"
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/helpers/caching"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+	"github.com/src/main/app/server"
+)
+
+type LookupPolicy string
+
+const (
+	// LookupFail fails the push, the message is retried.
+	LookupFail LookupPolicy = "fail"
+	// LookupSkip pushes the message without the field of the lookup.
+	LookupSkip LookupPolicy = "skip"
+)
+
+type LookupConfig struct {
+	Name string
+	// URL is a go template with TemplateData, the message values must be escaped, i.e.
+	// https://api.orders.com/orders/{{pathEscape .Body.order_id}}.
+	URL string
+	// Field of the payload with the response, the name by default.
+	Field     string
+	OnError   LookupPolicy
+	CacheSize int
+	CacheTTL  time.Duration
+}
+
+// Lookup gets a json resource of a side api with keys of the message, the responses are cached by url.
+type Lookup struct {
+	name    string
+	field   string
+	onError LookupPolicy
+	url     *template.Template
+	rb      rest.IRequestBuilder
+	cache   caching.ICache[string, json.RawMessage]
+}
+
+func NewLookup(config LookupConfig, rb rest.IRequestBuilder) (*Lookup, error) {
+	if config.Name == "" || config.URL == "" {
+		return nil, errors.New("lookup: name and url are required")
+	}
+
+	switch config.OnError {
+	case "":
+		config.OnError = LookupFail
+	case LookupFail, LookupSkip:
+	default:
+		return nil, fmt.Errorf("lookup %s: invalid on error policy %s", config.Name, config.OnError)
+	}
+
+	// a missing field fails the lookup instead of getting and caching .../<no value>
+	url, err := template.New(config.Name).Option("missingkey=error").Funcs(templateFuncs).Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("lookup %s: %w", config.Name, err)
+	}
+
+	cache, err := caching.NewBuilder[string, json.RawMessage]().
+		Size(config.CacheSize).
+		ExpireAfterWrite(config.CacheTTL).
+		Build()
+	if err != nil {
+		return nil, fmt.Errorf("lookup %s: %w", config.Name, err)
+	}
+
+	field := config.Field
+	if field == "" {
+		field = config.Name
+	}
+
+	return &Lookup{
+		name:    config.Name,
+		field:   field,
+		onError: config.OnError,
+		url:     url,
+		rb:      rb,
+		cache:   cache,
+	}, nil
+}
+
+func (l Lookup) get(data TemplateData) (json.RawMessage, error) {
+	var url bytes.Buffer
+	if err := l.url.Execute(&url, data); err != nil {
+		// a redelivery would fail again
+		return nil, &client.RejectError{Err: fmt.Errorf("url: %w", err)}
+	}
+
+	if value, found := l.cache.GetIfPresent(url.String()); found {
+		metrics.Collector.IncrementLabeledCounter(metrics.LookupCacheHits, l.name)
+		return value, nil
+	}
+
+	response := l.rb.Get(url.String())
+	if response.Err != nil {
+		return nil, response.Err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, server.NewError(response.StatusCode, response.String())
+	}
+
+	value := json.RawMessage(response.Bytes())
+	if !json.Valid(value) {
+		return nil, errors.New("invalid json response")
+	}
+
+	l.cache.Put(url.String(), value)
+
+	return value, nil
+}
+
+// Enricher merges the lookup responses into the json object of the sns Message, or of the raw payload, before
+// the push.
+type Enricher struct {
+	lookups []*Lookup
+	next    Pusher
+}
+
+func NewEnricher(lookups []*Lookup, next Pusher) *Enricher {
+	return &Enricher{
+		lookups: lookups,
+		next:    next,
+	}
+}
+
+func (e Enricher) SendMessage(message *queue.MessageDTO) error {
+	data := newTemplateData(message)
+	payload, ok := data.Body.(map[string]any)
+	if !ok {
+		log.Warnf("enrich: message %s is not a json object, it is pushed without lookups", message.MessageID)
+		return e.next.SendMessage(message)
+	}
+
+	for _, lookup := range e.lookups {
+		value, err := lookup.get(data)
+		if err != nil {
+			metrics.Collector.IncrementLabeledCounter(metrics.LookupErrors, lookup.name)
+			if lookup.onError == LookupFail {
+				return fmt.Errorf("lookup %s: %w", lookup.name, err)
+			}
+			log.Warnf("enrich: lookup %s skipped for message %s: %s", lookup.name, message.MessageID, err)
+			continue
+		}
+		payload[lookup.field] = value
+	}
+
+	body, err := enrichedBody(message.Body, payload)
+	if err != nil {
+		return fmt.Errorf("enrich: %w", err)
+	}
+
+	enriched := *message
+	enriched.Body = body
+
+	return e.next.SendMessage(&enriched)
+}
+
+// enrichedBody replaces the sns Message, the other envelope fields are kept, or the raw payload.
+func enrichedBody(body string, payload map[string]any) (string, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return replacedBody(body, encoded)
+}
+
+// replacedBody replaces the sns Message with the json payload, or the whole body when it is not a notification.
+func replacedBody(body string, payload []byte) (string, error) {
+	var envelope map[string]any
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		// i.e. a json array or a binary raw payload
+		return string(payload), nil
+	}
+
+	if message, found := envelope["Message"].(string); !found || message == "" {
+		return string(payload), nil
+	}
+
+	envelope["Message"] = string(payload)
+	encoded, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encoded), nil
+}
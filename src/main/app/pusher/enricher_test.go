@@ -0,0 +1,194 @@
+package pusher_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arielsrv/ikp_go-restclient/rest"
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockMessagePusher struct {
+	mock.Mock
+	messages []queue.MessageDTO
+}
+
+func (m *MockMessagePusher) SendMessage(message *queue.MessageDTO) error {
+	m.messages = append(m.messages, *message)
+	args := m.Called()
+	return args.Error(0)
+}
+
+// newOrdersAPI answers the orders by id, 1 to 2, counting the requests.
+func newOrdersAPI(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	requests := new(atomic.Int32)
+	ordersAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/orders/1":
+			_, _ = w.Write([]byte(`{"customer_id":123}`))
+		case "/orders/2":
+			_, _ = w.Write([]byte(`{"customer_id":456}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ordersAPI.Close)
+
+	return ordersAPI, requests
+}
+
+func newLookup(t *testing.T, baseURL string, onError pusher.LookupPolicy) *pusher.Lookup {
+	lookup, err := pusher.NewLookup(pusher.LookupConfig{
+		Name:      "order",
+		URL:       baseURL + "/orders/{{pathEscape .Body.order_id}}",
+		Field:     "order_detail",
+		OnError:   onError,
+		CacheSize: 10,
+		CacheTTL:  time.Minute,
+	}, &rest.RequestBuilder{Timeout: time.Second})
+	assert.NoError(t, err)
+
+	return lookup
+}
+
+func TestNewLookupErr(t *testing.T) {
+	rb := new(rest.RequestBuilder)
+
+	_, err := pusher.NewLookup(pusher.LookupConfig{Name: "order"}, rb)
+	assert.Error(t, err)
+
+	_, err = pusher.NewLookup(pusher.LookupConfig{Name: "order", URL: "https://api/{{.Body.id", CacheSize: 1, CacheTTL: time.Minute}, rb)
+	assert.Error(t, err)
+
+	_, err = pusher.NewLookup(pusher.LookupConfig{Name: "order", URL: "https://api", OnError: "retry", CacheSize: 1, CacheTTL: time.Minute}, rb)
+	assert.Error(t, err)
+
+	_, err = pusher.NewLookup(pusher.LookupConfig{Name: "order", URL: "https://api"}, rb)
+	assert.Error(t, err)
+}
+
+func TestEnricher_SendMessage(t *testing.T) {
+	ordersAPI, requests := newOrdersAPI(t)
+
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	enricher := pusher.NewEnricher([]*pusher.Lookup{newLookup(t, ordersAPI.URL, pusher.LookupFail)}, next)
+	message := &queue.MessageDTO{
+		MessageID: "1",
+		Body:      `{"MessageId":"sns-1","Subject":"Order","Message":"{\"order_id\":1}"}`,
+	}
+
+	assert.NoError(t, enricher.SendMessage(message))
+	// the second lookup is cached
+	assert.NoError(t, enricher.SendMessage(message))
+
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Len(t, next.messages, 2)
+	assert.JSONEq(t, `{"MessageId":"sns-1","Subject":"Order","Message":"{\"order_detail\":{\"customer_id\":123},\"order_id\":1}"}`,
+		next.messages[0].Body)
+	assert.Equal(t, `{"MessageId":"sns-1","Subject":"Order","Message":"{\"order_id\":1}"}`, message.Body)
+}
+
+func TestEnricher_SendMessageRaw(t *testing.T) {
+	ordersAPI, _ := newOrdersAPI(t)
+
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	err := pusher.NewEnricher([]*pusher.Lookup{newLookup(t, ordersAPI.URL, pusher.LookupFail)}, next).
+		SendMessage(&queue.MessageDTO{MessageID: "orders-0-1", Body: `{"order_id":2}`})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"order_id":2,"order_detail":{"customer_id":456}}`, next.messages[0].Body)
+}
+
+func TestEnricher_SendMessageFail(t *testing.T) {
+	ordersAPI, _ := newOrdersAPI(t)
+	next := new(MockMessagePusher)
+
+	err := pusher.NewEnricher([]*pusher.Lookup{newLookup(t, ordersAPI.URL, pusher.LookupFail)}, next).
+		SendMessage(&queue.MessageDTO{Body: `{"order_id":3}`})
+
+	assert.Error(t, err)
+	next.AssertNotCalled(t, "SendMessage")
+}
+
+func TestEnricher_SendMessageSkip(t *testing.T) {
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	err := pusher.NewEnricher([]*pusher.Lookup{newLookup(t, "http://localhost:1", pusher.LookupSkip)}, next).
+		SendMessage(&queue.MessageDTO{Body: `{"order_id":4}`})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"order_id":4}`, next.messages[0].Body)
+}
+
+func TestEnricher_SendMessageNotObject(t *testing.T) {
+	ordersAPI, requests := newOrdersAPI(t)
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	err := pusher.NewEnricher([]*pusher.Lookup{newLookup(t, ordersAPI.URL, pusher.LookupFail)}, next).
+		SendMessage(&queue.MessageDTO{Body: "ping"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), requests.Load())
+	assert.Equal(t, "ping", next.messages[0].Body)
+}
+
+func TestEnricher_SendMessageMissingField(t *testing.T) {
+	ordersAPI, requests := newOrdersAPI(t)
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	err := pusher.NewEnricher([]*pusher.Lookup{newLookup(t, ordersAPI.URL, pusher.LookupFail)}, next).
+		SendMessage(&queue.MessageDTO{Body: `{"id":1}`})
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+	assert.Equal(t, int32(0), requests.Load())
+	next.AssertNotCalled(t, "SendMessage")
+
+	err = pusher.NewEnricher([]*pusher.Lookup{newLookup(t, ordersAPI.URL, pusher.LookupSkip)}, next).
+		SendMessage(&queue.MessageDTO{Body: `{"id":1}`})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), requests.Load())
+	assert.JSONEq(t, `{"id":1}`, next.messages[0].Body)
+}
+
+func TestEnricher_SendMessageEscaped(t *testing.T) {
+	var requestURIs []string
+	ordersAPI := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestURIs = append(requestURIs, r.RequestURI)
+		_, _ = w.Write([]byte(`{"customer_id":123}`))
+	}))
+	t.Cleanup(ordersAPI.Close)
+
+	lookup, err := pusher.NewLookup(pusher.LookupConfig{
+		Name:      "order",
+		URL:       ordersAPI.URL + "/orders/{{pathEscape .Body.order_id}}?tenant={{queryEscape .Body.tenant}}",
+		CacheSize: 10,
+		CacheTTL:  time.Minute,
+	}, &rest.RequestBuilder{Timeout: time.Second})
+	assert.NoError(t, err)
+
+	next := new(MockMessagePusher)
+	next.On("SendMessage").Return(nil)
+
+	err = pusher.NewEnricher([]*pusher.Lookup{lookup}, next).
+		SendMessage(&queue.MessageDTO{Body: `{"order_id":"1/../admin?x=","tenant":"es&admin=true"}`})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/orders/1%2F..%2Fadmin%3Fx=?tenant=es%26admin%3Dtrue"}, requestURIs)
+}
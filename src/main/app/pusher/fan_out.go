@@ -0,0 +1,147 @@
+package pusher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/src/main/app/infrastructure/kvs"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+	"github.com/src/main/app/model"
+)
+
+type AckPolicy string
+
+const (
+	// AckAll acks the message when every target succeeded.
+	AckAll AckPolicy = "all"
+	// AckPrimary acks the message when the primary target succeeded, the other failures are logged.
+	AckPrimary AckPolicy = "primary"
+)
+
+type Target struct {
+	Name   string
+	Pusher Pusher
+}
+
+type FanOutConfig struct {
+	// Targets receive each message in order, the first one is the primary.
+	Targets   []Target
+	AckPolicy AckPolicy
+	// StateTTL expires the delivery state of the messages never acked.
+	StateTTL time.Duration
+}
+
+// FanOut pushes each message to several targets. The delivered targets are kept in the kvs, so a redelivery
+// only retries the failed ones.
+type FanOut struct {
+	targets   []Target
+	ackPolicy AckPolicy
+	stateTTL  time.Duration
+	kvsClient kvs.Client[model.FanOutStateDTO]
+}
+
+func NewFanOut(config FanOutConfig, kvsClient kvs.Client[model.FanOutStateDTO]) (*FanOut, error) {
+	if len(config.Targets) == 0 {
+		return nil, errors.New("fan-out: targets are required")
+	}
+
+	switch config.AckPolicy {
+	case "":
+		config.AckPolicy = AckAll
+	case AckAll, AckPrimary:
+	default:
+		return nil, fmt.Errorf("fan-out: invalid ack policy %s", config.AckPolicy)
+	}
+
+	return &FanOut{
+		targets:   config.Targets,
+		ackPolicy: config.AckPolicy,
+		stateTTL:  config.StateTTL,
+		kvsClient: kvsClient,
+	}, nil
+}
+
+func (f FanOut) SendMessage(message *queue.MessageDTO) error {
+	key := f.stateKey(message)
+
+	state, err := f.kvsClient.Get(key)
+	if err != nil {
+		log.Warnf("fan-out: state of %s not read, all targets are pushed: %s", key, err)
+	}
+	if state == nil {
+		state = new(model.FanOutStateDTO)
+	}
+
+	delivered := len(state.Delivered)
+	var primaryErr, errs error
+	for i, target := range f.targets {
+		if slices.Contains(state.Delivered, target.Name) {
+			continue
+		}
+
+		if err = target.Pusher.SendMessage(message); err != nil {
+			metrics.Collector.IncrementLabeledCounter(metrics.FanOutFailed, target.Name)
+			err = fmt.Errorf("target %s: %w", target.Name, err)
+			if i == 0 {
+				primaryErr = err
+			}
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		metrics.Collector.IncrementLabeledCounter(metrics.FanOutDelivered, target.Name)
+		state.Delivered = append(state.Delivered, target.Name)
+	}
+
+	if f.ackPolicy == AckPrimary && errs != nil {
+		if primaryErr == nil {
+			log.Errorf("fan-out: message %s acked by the primary target: %s", key, errs)
+		}
+		errs = primaryErr
+	}
+
+	if errs == nil {
+		if delivered > 0 {
+			f.deleteState(key)
+		}
+		return nil
+	}
+
+	if len(state.Delivered) > delivered {
+		if err = f.kvsClient.SaveWithTTL(key, state, f.stateTTL); err != nil {
+			log.Warnf("fan-out: state of %s not saved, all targets are retried: %s", key, err)
+		}
+	}
+
+	return errs
+}
+
+func (f FanOut) deleteState(key string) {
+	if err := f.kvsClient.Delete(key); err != nil {
+		log.Warnf("fan-out: state of %s not deleted: %s", key, err)
+	}
+}
+
+func (f FanOut) stateKey(message *queue.MessageDTO) string {
+	return stateKey("fan-out", message)
+}
+
+// stateKey is keyed by the stable id, so a message re-published by a retry or a kafka nack keeps its state.
+func stateKey(prefix string, message *queue.MessageDTO) string {
+	return prefix + ":" + stableID(message)
+}
+
+// stableID is the sns MessageId, or the id of the first delivery of a raw payload.
+func stableID(message *queue.MessageDTO) string {
+	var messageDTO MessageDTO
+	if err := json.Unmarshal([]byte(message.Body), &messageDTO); err == nil && messageDTO.ID != "" {
+		return messageDTO.ID
+	}
+
+	return message.OriginalID()
+}
@@ -0,0 +1,111 @@
+package pusher_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/container"
+	"github.com/src/main/app/infrastructure/kvs"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/pusher"
+	"github.com/src/main/app/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFanOut(t *testing.T, ackPolicy pusher.AckPolicy, primaryErr error, auditErr error) (*pusher.FanOut, *MockPusher, *MockPusher) {
+	primary, audit := new(MockPusher), new(MockPusher)
+	primary.On("SendMessage").Return(primaryErr)
+	audit.On("SendMessage").Return(auditErr)
+
+	fanOut, err := pusher.NewFanOut(pusher.FanOutConfig{
+		Targets:   []pusher.Target{{Name: "primary", Pusher: primary}, {Name: "audit", Pusher: audit}},
+		AckPolicy: ackPolicy,
+		StateTTL:  time.Minute,
+	}, kvs.NewElasticCacheClient[model.FanOutStateDTO](container.ProvideKVSClient()))
+	assert.NoError(t, err)
+
+	return fanOut, primary, audit
+}
+
+func TestNewFanOutErr(t *testing.T) {
+	kvsClient := kvs.NewElasticCacheClient[model.FanOutStateDTO](container.ProvideKVSClient())
+
+	_, err := pusher.NewFanOut(pusher.FanOutConfig{}, kvsClient)
+	assert.Error(t, err)
+
+	_, err = pusher.NewFanOut(pusher.FanOutConfig{
+		Targets:   []pusher.Target{{Name: "primary", Pusher: new(MockPusher)}},
+		AckPolicy: "any",
+	}, kvsClient)
+	assert.Error(t, err)
+}
+
+func TestFanOut_SendMessage(t *testing.T) {
+	fanOut, primary, audit := newFanOut(t, pusher.AckAll, nil, nil)
+
+	err := fanOut.SendMessage(&queue.MessageDTO{MessageID: "fan-out-1", Body: "{}"})
+
+	assert.NoError(t, err)
+	primary.AssertNumberOfCalls(t, "SendMessage", 1)
+	audit.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestFanOut_SendMessageRetryFailedTargets(t *testing.T) {
+	fanOut, primary, audit := newFanOut(t, pusher.AckAll, nil, errors.New("audit unavailable"))
+	message := &queue.MessageDTO{MessageID: "fan-out-2", Body: `{"MessageId":"sns-2","Message":"hello"}`}
+
+	err := fanOut.SendMessage(message)
+	assert.Error(t, err)
+
+	// a re-published message keeps the sns message id
+	message.MessageID = "fan-out-3"
+	err = fanOut.SendMessage(message)
+	assert.Error(t, err)
+
+	primary.AssertNumberOfCalls(t, "SendMessage", 1)
+	audit.AssertNumberOfCalls(t, "SendMessage", 2)
+
+	state, err := kvs.NewElasticCacheClient[model.FanOutStateDTO](container.ProvideKVSClient()).Get("fan-out:sns-2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"primary"}, state.Delivered)
+}
+
+func TestFanOut_SendMessageRetryFailedTargetsRaw(t *testing.T) {
+	fanOut, primary, audit := newFanOut(t, pusher.AckAll, nil, errors.New("audit unavailable"))
+	message := &queue.MessageDTO{MessageID: "orders-0-7", Body: "order 7", Raw: true}
+
+	err := fanOut.SendMessage(message)
+	assert.Error(t, err)
+
+	// a re-produced kafka record has a new offset, the first one is carried in an attribute
+	message.MessageID = "orders-0-9"
+	message.Attributes = map[string]string{queue.OriginalMessageIDAttribute: "orders-0-7"}
+	err = fanOut.SendMessage(message)
+	assert.Error(t, err)
+
+	primary.AssertNumberOfCalls(t, "SendMessage", 1)
+	audit.AssertNumberOfCalls(t, "SendMessage", 2)
+}
+
+func TestFanOut_SendMessageAckPrimary(t *testing.T) {
+	fanOut, primary, audit := newFanOut(t, pusher.AckPrimary, nil, errors.New("audit unavailable"))
+
+	err := fanOut.SendMessage(&queue.MessageDTO{MessageID: "fan-out-4", Body: "{}"})
+
+	assert.NoError(t, err)
+	primary.AssertNumberOfCalls(t, "SendMessage", 1)
+	audit.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestFanOut_SendMessageAckPrimaryErr(t *testing.T) {
+	rejectErr := &client.RejectError{Err: server.NewError(422, "invalid")}
+	fanOut, _, _ := newFanOut(t, pusher.AckPrimary, rejectErr, errors.New("audit unavailable"))
+
+	err := fanOut.SendMessage(&queue.MessageDTO{MessageID: "fan-out-5", Body: "{}"})
+
+	var actual *client.RejectError
+	assert.ErrorAs(t, err, &actual)
+}
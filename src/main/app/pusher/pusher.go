@@ -2,6 +2,7 @@ package pusher
 
 import (
 	"encoding/json"
+	"errors"
 
 	"github.com/src/main/app/client"
 	"github.com/src/main/app/infrastructure/queue"
@@ -9,55 +10,120 @@ import (
 	"github.com/src/main/app/metrics"
 )
 
+// redacted replaces the sensitive message bodies in the logs.
+const redacted = "[redacted]"
+
 type Pusher interface {
 	SendMessage(message *queue.MessageDTO) error
 }
 
 type HTTPPusher struct {
 	httpClient client.AppClient
+	template   *Template
 }
 
 type MessageDTO struct {
 	ID        string `json:"MessageId,omitempty"`
 	Message   string `json:"Message,omitempty"`
+	Subject   string `json:"Subject,omitempty"`
 	Timestamp string `json:"Timestamp,omitempty"`
 }
 
-func NewHTTPPusher(httpClient client.AppClient) *HTTPPusher {
-	return &HTTPPusher{
+// NewHTTPPusher pushes client.RequestBody, or the json rendered by the template when it is given.
+func NewHTTPPusher(httpClient client.AppClient, template ...*Template) *HTTPPusher {
+	httpPusher := &HTTPPusher{
 		httpClient: httpClient,
 	}
+
+	if len(template) > 0 {
+		httpPusher.template = template[0]
+	}
+
+	return httpPusher
 }
 
 func (h HTTPPusher) SendMessage(message *queue.MessageDTO) error {
-	var messageDTO MessageDTO
-	err := json.Unmarshal([]byte(message.Body), &messageDTO)
+	requestBody, err := newRequestBody(message)
 	if err != nil {
 		log.Error(err)
 		return err
 	}
 
-	requestBody := new(client.RequestBody)
-	requestBody.ID = messageDTO.ID
-	requestBody.Msg = messageDTO.Message
-	requestBody.Timestamp = messageDTO.Timestamp
+	loggedMsg := requestBody.Msg
+	if message.Sensitive {
+		loggedMsg = redacted
+	}
 
-	log.Warnf("[pushing]: message id: %s, msg: %s, timestamp: %s", requestBody.ID, requestBody.Msg, requestBody.Timestamp)
+	log.Warnf("[pushing]: message id: %s, msg: %s, timestamp: %s", requestBody.ID, loggedMsg, requestBody.Timestamp)
 
-	err = h.httpClient.PostMessage(requestBody)
+	err = h.post(message, requestBody)
 
 	if err != nil {
 		log.Errorf("[nack]   : message id: %s, msg: %s, timestamp: %s",
 			requestBody.ID,
-			requestBody.Msg,
+			loggedMsg,
 			requestBody.Timestamp)
 
-		metrics.Collector.IncrementCounter(metrics.PusherError)
+		countError(err)
+
 		return err
 	}
 
-	log.Infof("[ack]    : message id: %s, msg: %s, timestamp: %s", requestBody.ID, requestBody.Msg, requestBody.Timestamp)
+	log.Infof("[ack]    : message id: %s, msg: %s, timestamp: %s", requestBody.ID, loggedMsg, requestBody.Timestamp)
 	metrics.Collector.IncrementCounter(metrics.PusherSuccess)
 
 	return nil
 }
+
+// newRequestBody is the sns notification of the message, raw payloads are pushed as they are, i.e. kafka records or
+// json objects without sns Message.
+func newRequestBody(message *queue.MessageDTO) (*client.RequestBody, error) {
+	if message.Raw {
+		return &client.RequestBody{ID: message.MessageID, Msg: message.Body}, nil
+	}
+
+	var messageDTO MessageDTO
+	if err := json.Unmarshal([]byte(message.Body), &messageDTO); err != nil {
+		return nil, err
+	}
+
+	requestBody := new(client.RequestBody)
+	requestBody.ID = messageDTO.ID
+	requestBody.Msg = messageDTO.Message
+	requestBody.Timestamp = messageDTO.Timestamp
+
+	if messageDTO.Message == "" {
+		requestBody.ID = message.MessageID
+		requestBody.Msg = message.Body
+	}
+
+	return requestBody, nil
+}
+
+// post renders the template of the message, a render error is a reject since a redelivery would fail again.
+func (h HTTPPusher) post(message *queue.MessageDTO, requestBody *client.RequestBody) error {
+	if h.template == nil {
+		return h.httpClient.PostMessage(requestBody)
+	}
+
+	payload, err := h.template.Execute(message)
+	if err != nil {
+		return &client.RejectError{Err: err}
+	}
+
+	return h.httpClient.PostPayload(requestBody.ID, payload)
+}
+
+// countError counts a failed push, and its reject or retry after outcome.
+func countError(err error) {
+	metrics.Collector.IncrementCounter(metrics.PusherError)
+
+	var retryErr *client.RetryError
+	var rejectErr *client.RejectError
+	switch {
+	case errors.As(err, &rejectErr):
+		metrics.Collector.IncrementCounter(metrics.PusherReject)
+	case errors.As(err, &retryErr):
+		metrics.Collector.IncrementCounter(metrics.PusherRetry)
+	}
+}
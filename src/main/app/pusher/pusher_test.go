@@ -1,6 +1,7 @@
 package pusher_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/src/main/app/client"
@@ -15,8 +16,18 @@ type MockHTTPClient struct {
 	mock.Mock
 }
 
-func (m *MockHTTPClient) PostMessage(*client.RequestBody) error {
-	args := m.Called()
+func (m *MockHTTPClient) PostMessage(body *client.RequestBody) error {
+	args := m.Called(body)
+	return args.Error(0)
+}
+
+func (m *MockHTTPClient) PostPayload(id string, payload json.RawMessage) error {
+	args := m.Called(id, string(payload))
+	return args.Error(0)
+}
+
+func (m *MockHTTPClient) PostBinary(id string, payload []byte) error {
+	args := m.Called(id, payload)
 	return args.Error(0)
 }
 
@@ -24,7 +35,7 @@ func TestHttpPusher_SendMessage(t *testing.T) {
 	httpClient := new(MockHTTPClient)
 	httpPusher := pusher.NewHTTPPusher(httpClient)
 
-	httpClient.On("PostMessage").Return(nil)
+	httpClient.On("PostMessage", mock.Anything).Return(nil)
 
 	message := new(queue.MessageDTO)
 	message.Body = "{\"MessageId\":\"123\", \"Message\": \"Hello world\"}"
@@ -33,24 +44,39 @@ func TestHttpPusher_SendMessage(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestHttpPusher_SendMessageErr(t *testing.T) {
+func TestHttpPusher_SendMessageRaw(t *testing.T) {
 	httpClient := new(MockHTTPClient)
 	httpPusher := pusher.NewHTTPPusher(httpClient)
 
-	httpClient.On("PostMessage").Return(server.NewError(504, "gateway timeout"))
+	httpClient.On("PostMessage", mock.Anything).Return(nil)
 
 	message := new(queue.MessageDTO)
-	message.Body = "{\"MessageId\":\"123\", \"Message\": \"Hello world\"}"
+	message.MessageID = "orders-0-1"
+	message.Body = "{\"order_id\": 1}"
 
 	err := httpPusher.SendMessage(message)
-	assert.Error(t, err)
+	assert.NoError(t, err)
+	httpClient.AssertCalled(t, "PostMessage", &client.RequestBody{ID: "orders-0-1", Msg: "{\"order_id\": 1}"})
+}
+
+func TestHttpPusher_SendMessageRawNotJSONObject(t *testing.T) {
+	for _, body := range []string{"invalid message", "1;order;150", `[{"order_id":1}]`, "1"} {
+		httpClient := new(MockHTTPClient)
+		httpPusher := pusher.NewHTTPPusher(httpClient)
+
+		httpClient.On("PostMessage", mock.Anything).Return(nil)
+
+		err := httpPusher.SendMessage(&queue.MessageDTO{MessageID: "orders-0-1", Body: body, Raw: true})
+		assert.NoError(t, err)
+		httpClient.AssertCalled(t, "PostMessage", &client.RequestBody{ID: "orders-0-1", Msg: body})
+	}
 }
 
 func TestHttpPusher_SendMessageParsingErr(t *testing.T) {
 	httpClient := new(MockHTTPClient)
 	httpPusher := pusher.NewHTTPPusher(httpClient)
 
-	httpClient.On("PostMessage").Return(server.NewError(504, "gateway timeout"))
+	httpClient.On("PostMessage", mock.Anything).Return(server.NewError(504, "gateway timeout"))
 
 	message := new(queue.MessageDTO)
 	message.Body = "invalid message"
@@ -58,3 +84,62 @@ func TestHttpPusher_SendMessageParsingErr(t *testing.T) {
 	err := httpPusher.SendMessage(message)
 	assert.Error(t, err)
 }
+
+func TestHttpPusher_SendMessageErr(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	httpPusher := pusher.NewHTTPPusher(httpClient)
+
+	httpClient.On("PostMessage", mock.Anything).Return(server.NewError(504, "gateway timeout"))
+
+	message := new(queue.MessageDTO)
+	message.Body = "{\"MessageId\":\"123\", \"Message\": \"Hello world\"}"
+
+	err := httpPusher.SendMessage(message)
+	assert.Error(t, err)
+}
+
+func TestHttpPusher_SendMessageTemplate(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{
+		Text: `{"order_id":{{json .Body.id}},"source":"orders"}`,
+	})
+	assert.NoError(t, err)
+	httpPusher := pusher.NewHTTPPusher(httpClient, template)
+
+	httpClient.On("PostPayload", "123", `{"order_id":1,"source":"orders"}`).Return(nil)
+
+	message := new(queue.MessageDTO)
+	message.Body = "{\"MessageId\":\"123\", \"Message\": \"{\\\"id\\\":1}\"}"
+
+	err = httpPusher.SendMessage(message)
+	assert.NoError(t, err)
+	httpClient.AssertCalled(t, "PostPayload", "123", `{"order_id":1,"source":"orders"}`)
+}
+
+func TestHttpPusher_SendMessageTemplateErr(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"id":{{.Body.id}}`})
+	assert.NoError(t, err)
+	httpPusher := pusher.NewHTTPPusher(httpClient, template)
+
+	message := new(queue.MessageDTO)
+	message.Body = "{\"id\":1}"
+
+	err = httpPusher.SendMessage(message)
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+	httpClient.AssertNotCalled(t, "PostPayload", mock.Anything, mock.Anything)
+}
+
+func TestHttpPusher_SendMessageTemplateMissingField(t *testing.T) {
+	httpClient := new(MockHTTPClient)
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"order_id":{{json .Body.order_id}}}`})
+	assert.NoError(t, err)
+
+	err = pusher.NewHTTPPusher(httpClient, template).SendMessage(&queue.MessageDTO{Body: `{"id":1}`})
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+	httpClient.AssertNotCalled(t, "PostPayload", mock.Anything, mock.Anything)
+}
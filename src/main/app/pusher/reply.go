@@ -0,0 +1,152 @@
+package pusher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+)
+
+// Reply attributes of the published target responses, for the filters of the next step.
+const (
+	CorrelationIDAttribute = "correlation-id"
+	ReplyStatusAttribute   = "reply-status"
+)
+
+type ReplyConfig struct {
+	Sender Sender
+	// Template renders the pushed json instead of client.RequestBody.
+	Template *Template
+}
+
+// ReplyDTO is the published reply, the body is the json of the response, a json string otherwise.
+type ReplyDTO struct {
+	CorrelationID string          `json:"correlation_id"`
+	Status        int             `json:"status"`
+	Body          json.RawMessage `json:"body,omitempty"`
+}
+
+// ReplyPusher pushes as the HTTPPusher and publishes the target responses, the 2xx and the rejects, to a reply
+// queue or topic. The correlation id is the one carried by the message, the sns MessageId or the original message
+// id of a raw payload otherwise.
+type ReplyPusher struct {
+	replyClient client.ReplyClient
+	sender      Sender
+	template    *Template
+}
+
+func NewReplyPusher(config ReplyConfig, replyClient client.ReplyClient) (*ReplyPusher, error) {
+	if config.Sender == nil {
+		return nil, errors.New("reply: sender is required")
+	}
+
+	return &ReplyPusher{
+		replyClient: replyClient,
+		sender:      config.Sender,
+		template:    config.Template,
+	}, nil
+}
+
+// WithReplyClient is a copy of the pusher for another client, i.e. the one of a resolved endpoint, built per message
+// without validating the config again.
+func (r ReplyPusher) WithReplyClient(replyClient client.ReplyClient) *ReplyPusher {
+	r.replyClient = replyClient
+	return &r
+}
+
+// SendMessage fails when the reply of a 2xx is not published, so the message is pushed again, the target must
+// handle the duplicates.
+func (r ReplyPusher) SendMessage(message *queue.MessageDTO) error {
+	requestBody, err := newRequestBody(message)
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	var body any = requestBody
+	if r.template != nil {
+		payload, renderErr := r.template.Execute(message)
+		if renderErr != nil {
+			err = &client.RejectError{Err: renderErr}
+			countError(err)
+			return err
+		}
+		body = payload
+	}
+
+	log.Warnf("[pushing]: message id: %s, reply", requestBody.ID)
+
+	reply, err := r.replyClient.PostReply(requestBody.ID, body)
+	if reply != nil {
+		if publishErr := r.publish(correlationID(message), reply); publishErr != nil {
+			metrics.Collector.IncrementCounter(metrics.ReplyErrors)
+			log.Errorf("reply of message %s not published: %s", requestBody.ID, publishErr)
+			if err == nil {
+				err = fmt.Errorf("reply: %w", publishErr)
+			}
+		} else {
+			metrics.Collector.IncrementCounter(metrics.RepliesPublished)
+		}
+	}
+
+	if err != nil {
+		log.Errorf("[nack]   : message id: %s, error: %s", requestBody.ID, err.Error())
+		countError(err)
+		return err
+	}
+
+	log.Infof("[ack]    : message id: %s, reply", requestBody.ID)
+	metrics.Collector.IncrementCounter(metrics.PusherSuccess)
+
+	return nil
+}
+
+// correlationID keeps the correlation-id attribute of the message through the steps, the stable id starts one.
+func correlationID(message *queue.MessageDTO) string {
+	if id := message.Attributes[CorrelationIDAttribute]; id != "" {
+		return id
+	}
+
+	return stableID(message)
+}
+
+func (r ReplyPusher) publish(correlationID string, reply *client.Reply) error {
+	replyDTO := ReplyDTO{
+		CorrelationID: correlationID,
+		Status:        reply.StatusCode,
+	}
+
+	switch {
+	case len(reply.Body) == 0:
+	case json.Valid(reply.Body):
+		replyDTO.Body = reply.Body
+	default:
+		encoded, err := json.Marshal(string(reply.Body))
+		if err != nil {
+			return err
+		}
+		replyDTO.Body = encoded
+	}
+
+	encoded, err := json.Marshal(replyDTO)
+	if err != nil {
+		return err
+	}
+
+	// the senders apply the timeout of their queue or topic
+	_, err = r.sender.Send(context.Background(), queue.SendMessageDTO{
+		Body: string(encoded),
+		Attributes: map[string]string{
+			CorrelationIDAttribute: correlationID,
+			ReplyStatusAttribute:   strconv.Itoa(reply.StatusCode),
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,159 @@
+package pusher_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/src/main/app/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReplyClient struct {
+	mock.Mock
+}
+
+func (m *MockReplyClient) PostReply(id string, _ any) (*client.Reply, error) {
+	args := m.Called(id)
+	reply, _ := args.Get(0).(*client.Reply)
+	return reply, args.Error(1)
+}
+
+type failingSender struct{}
+
+func (failingSender) Send(context.Context, queue.SendMessageDTO) (string, error) {
+	return "", errors.New("queue unavailable")
+}
+
+func newReplyQueue(t *testing.T) *queue.MemoryQueueService {
+	replyQueue, err := queue.NewMemoryClient(queue.MemoryConfig{Name: "replies", Parallel: 10, Timeout: 50},
+		queue.NewMemoryBroker())
+	assert.NoError(t, err)
+
+	return replyQueue
+}
+
+func TestNewReplyPusherErr(t *testing.T) {
+	_, err := pusher.NewReplyPusher(pusher.ReplyConfig{}, new(MockReplyClient))
+	assert.Error(t, err)
+}
+
+func TestReplyPusher_SendMessage(t *testing.T) {
+	replyClient := new(MockReplyClient)
+	replyClient.On("PostReply", "sns-1").Return(&client.Reply{StatusCode: http.StatusCreated, Body: []byte(`{"invoice_id": 10}`)}, nil)
+
+	replyQueue := newReplyQueue(t)
+	replyPusher, err := pusher.NewReplyPusher(pusher.ReplyConfig{Sender: replyQueue}, replyClient)
+	assert.NoError(t, err)
+
+	err = replyPusher.SendMessage(&queue.MessageDTO{MessageID: "1", Body: `{"MessageId": "sns-1", "Message": "hello"}`})
+	assert.NoError(t, err)
+
+	replies, err := replyQueue.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, replies, 1)
+	assert.JSONEq(t, `{"correlation_id": "sns-1", "status": 201, "body": {"invoice_id": 10}}`, replies[0].Body)
+	assert.Equal(t, map[string]string{
+		pusher.CorrelationIDAttribute: "sns-1",
+		pusher.ReplyStatusAttribute:   "201",
+	}, replies[0].Attributes)
+}
+
+func TestReplyPusher_WithReplyClient(t *testing.T) {
+	replyPusher, err := pusher.NewReplyPusher(pusher.ReplyConfig{Sender: newReplyQueue(t)}, nil)
+	assert.NoError(t, err)
+
+	replyClient := new(MockReplyClient)
+	replyClient.On("PostReply", "sns-1").Return(&client.Reply{StatusCode: http.StatusOK}, nil)
+
+	err = replyPusher.WithReplyClient(replyClient).SendMessage(&queue.MessageDTO{
+		MessageID: "1",
+		Body:      `{"MessageId": "sns-1", "Message": "hello"}`,
+	})
+
+	assert.NoError(t, err)
+	replyClient.AssertExpectations(t)
+}
+
+func TestReplyPusher_SendMessageReject(t *testing.T) {
+	rejectErr := &client.RejectError{Err: server.NewError(http.StatusUnprocessableEntity, "invalid order")}
+	replyClient := new(MockReplyClient)
+	replyClient.On("PostReply", "1").Return(&client.Reply{StatusCode: http.StatusUnprocessableEntity, Body: []byte("invalid order")}, rejectErr)
+
+	replyQueue := newReplyQueue(t)
+	replyPusher, err := pusher.NewReplyPusher(pusher.ReplyConfig{Sender: replyQueue}, replyClient)
+	assert.NoError(t, err)
+
+	err = replyPusher.SendMessage(&queue.MessageDTO{MessageID: "1", Body: `{"order_id": 1}`})
+	assert.ErrorAs(t, err, &rejectErr)
+
+	replies, err := replyQueue.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, replies, 1)
+	assert.JSONEq(t, `{"correlation_id": "1", "status": 422, "body": "invalid order"}`, replies[0].Body)
+}
+
+func TestReplyPusher_SendMessageCorrelationID(t *testing.T) {
+	replyClient := new(MockReplyClient)
+	replyClient.On("PostReply", mock.Anything).Return(&client.Reply{StatusCode: http.StatusOK}, nil)
+
+	replyQueue := newReplyQueue(t)
+	replyPusher, err := pusher.NewReplyPusher(pusher.ReplyConfig{Sender: replyQueue}, replyClient)
+	assert.NoError(t, err)
+
+	// a re-produced kafka record keeps the id of its first delivery
+	err = replyPusher.SendMessage(&queue.MessageDTO{
+		MessageID:  "orders-0-9",
+		Body:       `{"order_id": 1}`,
+		Attributes: map[string]string{queue.OriginalMessageIDAttribute: "orders-0-7"},
+		Raw:        true,
+	})
+	assert.NoError(t, err)
+
+	// a reply of a previous step keeps its correlation id
+	err = replyPusher.SendMessage(&queue.MessageDTO{
+		MessageID:  "orders-0-10",
+		Body:       `{"order_id": 2}`,
+		Attributes: map[string]string{pusher.CorrelationIDAttribute: "sns-2"},
+		Raw:        true,
+	})
+	assert.NoError(t, err)
+
+	replies, err := replyQueue.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, replies, 2)
+	assert.JSONEq(t, `{"correlation_id": "orders-0-7", "status": 200}`, replies[0].Body)
+	assert.JSONEq(t, `{"correlation_id": "sns-2", "status": 200}`, replies[1].Body)
+}
+
+func TestReplyPusher_SendMessageRetry(t *testing.T) {
+	replyClient := new(MockReplyClient)
+	replyClient.On("PostReply", "1").Return(nil, errors.New("internal server error"))
+
+	replyQueue := newReplyQueue(t)
+	replyPusher, err := pusher.NewReplyPusher(pusher.ReplyConfig{Sender: replyQueue}, replyClient)
+	assert.NoError(t, err)
+
+	err = replyPusher.SendMessage(&queue.MessageDTO{MessageID: "1", Body: `{"order_id": 1}`})
+	assert.Error(t, err)
+
+	count, err := replyQueue.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, *count)
+}
+
+func TestReplyPusher_SendMessagePublishErr(t *testing.T) {
+	replyClient := new(MockReplyClient)
+	replyClient.On("PostReply", "1").Return(&client.Reply{StatusCode: http.StatusNoContent}, nil)
+
+	replyPusher, err := pusher.NewReplyPusher(pusher.ReplyConfig{Sender: failingSender{}}, replyClient)
+	assert.NoError(t, err)
+
+	err = replyPusher.SendMessage(&queue.MessageDTO{MessageID: "1", Body: `{"order_id": 1}`})
+	assert.Error(t, err)
+}
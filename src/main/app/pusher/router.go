@@ -0,0 +1,148 @@
+package pusher
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+)
+
+// DefaultRoute is the name of the route of the messages not matched by any route.
+const DefaultRoute = "default"
+
+// Predicate matches a message when all its conditions match, an empty predicate matches any message.
+type Predicate struct {
+	// Attributes are message attribute values.
+	Attributes map[string]string
+	// Fields are values of the sns Message json by dot path, i.e. order.type, or of the body for raw payloads.
+	Fields map[string]string
+	// Subject is the sns Subject.
+	Subject string
+}
+
+// Route pushes the matched messages to its pusher, a drop route acks them without a push.
+type Route struct {
+	Name      string
+	Predicate Predicate
+	Pusher    Pusher
+	Drop      bool
+}
+
+// Router pushes each message by the first matched route, in order, or by the default route.
+type Router struct {
+	routes       []Route
+	defaultRoute Route
+}
+
+func NewRouter(routes []Route, defaultRoute Route) *Router {
+	if defaultRoute.Name == "" {
+		defaultRoute.Name = DefaultRoute
+	}
+
+	return &Router{
+		routes:       routes,
+		defaultRoute: defaultRoute,
+	}
+}
+
+func (r Router) SendMessage(message *queue.MessageDTO) error {
+	route := r.route(message)
+	metrics.Collector.IncrementLabeledCounter(metrics.RouteMessages, route.Name)
+
+	if route.Drop {
+		log.Infof("[drop]   : message id: %s, route: %s", message.MessageID, route.Name)
+		return nil
+	}
+
+	if err := route.Pusher.SendMessage(message); err != nil {
+		metrics.Collector.IncrementLabeledCounter(metrics.RouteErrors, route.Name)
+		return fmt.Errorf("route %s: %w", route.Name, err)
+	}
+
+	return nil
+}
+
+func (r Router) route(message *queue.MessageDTO) Route {
+	envelope := newRoutingEnvelope(message)
+	for _, route := range r.routes {
+		if route.Predicate.matches(envelope) {
+			return route
+		}
+	}
+
+	return r.defaultRoute
+}
+
+func (p Predicate) matches(envelope *routingEnvelope) bool {
+	for name, value := range p.Attributes {
+		if attribute, found := envelope.attributes[name]; !found || attribute != value {
+			return false
+		}
+	}
+
+	if p.Subject != "" && p.Subject != envelope.subject {
+		return false
+	}
+
+	for path, value := range p.Fields {
+		if field, found := envelope.field(path); !found || field != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// routingEnvelope is the message parsed once for all the routes, the payload is decoded on the first field lookup.
+type routingEnvelope struct {
+	attributes map[string]string
+	subject    string
+	payload    string
+	decoded    bool
+	document   any
+}
+
+func newRoutingEnvelope(message *queue.MessageDTO) *routingEnvelope {
+	envelope := &routingEnvelope{
+		attributes: message.Attributes,
+		payload:    message.Body,
+	}
+
+	var messageDTO MessageDTO
+	if err := json.Unmarshal([]byte(message.Body), &messageDTO); err == nil && messageDTO.Message != "" {
+		envelope.subject = messageDTO.Subject
+		envelope.payload = messageDTO.Message
+	}
+
+	return envelope
+}
+
+func (e *routingEnvelope) field(path string) (string, bool) {
+	if !e.decoded {
+		e.decoded = true
+		if err := json.Unmarshal([]byte(e.payload), &e.document); err != nil {
+			log.Debugf("route: payload is not json: %s", err)
+		}
+	}
+
+	value := e.document
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if value, ok = object[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch value.(type) {
+	case map[string]any, []any, nil:
+		return "", false
+	default:
+		return fmt.Sprint(value), true
+	}
+}
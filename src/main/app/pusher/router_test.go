@@ -0,0 +1,117 @@
+package pusher_test
+
+import (
+	"testing"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/src/main/app/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockPusher struct {
+	mock.Mock
+}
+
+func (m *MockPusher) SendMessage(*queue.MessageDTO) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func newRouter() (*pusher.Router, *MockPusher, *MockPusher, *MockPusher) {
+	ordersPusher, shipmentsPusher, defaultPusher := new(MockPusher), new(MockPusher), new(MockPusher)
+	for _, mockPusher := range []*MockPusher{ordersPusher, shipmentsPusher, defaultPusher} {
+		mockPusher.On("SendMessage").Return(nil)
+	}
+
+	router := pusher.NewRouter([]pusher.Route{
+		{
+			Name:      "orders",
+			Predicate: pusher.Predicate{Attributes: map[string]string{"event": "order.created"}},
+			Pusher:    ordersPusher,
+		},
+		{
+			Name:      "shipments",
+			Predicate: pusher.Predicate{Subject: "Shipment", Fields: map[string]string{"shipment.status": "sent", "priority": "1"}},
+			Pusher:    shipmentsPusher,
+		},
+		{
+			Name:      "ignored",
+			Predicate: pusher.Predicate{Fields: map[string]string{"type": "ping"}},
+			Drop:      true,
+		},
+	}, pusher.Route{Pusher: defaultPusher})
+
+	return router, ordersPusher, shipmentsPusher, defaultPusher
+}
+
+func TestRouter_SendMessageAttribute(t *testing.T) {
+	router, ordersPusher, shipmentsPusher, defaultPusher := newRouter()
+
+	err := router.SendMessage(&queue.MessageDTO{
+		Body:       `{"order_id": 1}`,
+		Attributes: map[string]string{"event": "order.created"},
+	})
+
+	assert.NoError(t, err)
+	ordersPusher.AssertNumberOfCalls(t, "SendMessage", 1)
+	shipmentsPusher.AssertNotCalled(t, "SendMessage")
+	defaultPusher.AssertNotCalled(t, "SendMessage")
+}
+
+func TestRouter_SendMessageSNSFields(t *testing.T) {
+	router, ordersPusher, shipmentsPusher, defaultPusher := newRouter()
+
+	err := router.SendMessage(&queue.MessageDTO{
+		Body: `{"MessageId":"1","Subject":"Shipment","Message":"{\"priority\":1,\"shipment\":{\"status\":\"sent\"}}"}`,
+	})
+
+	assert.NoError(t, err)
+	shipmentsPusher.AssertNumberOfCalls(t, "SendMessage", 1)
+	ordersPusher.AssertNotCalled(t, "SendMessage")
+	defaultPusher.AssertNotCalled(t, "SendMessage")
+}
+
+func TestRouter_SendMessageSubjectMismatch(t *testing.T) {
+	router, _, shipmentsPusher, defaultPusher := newRouter()
+
+	err := router.SendMessage(&queue.MessageDTO{
+		Body: `{"MessageId":"1","Subject":"Order","Message":"{\"priority\":1,\"shipment\":{\"status\":\"sent\"}}"}`,
+	})
+
+	assert.NoError(t, err)
+	shipmentsPusher.AssertNotCalled(t, "SendMessage")
+	defaultPusher.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestRouter_SendMessageDrop(t *testing.T) {
+	router, ordersPusher, shipmentsPusher, defaultPusher := newRouter()
+
+	err := router.SendMessage(&queue.MessageDTO{Body: `{"type": "ping"}`})
+
+	assert.NoError(t, err)
+	ordersPusher.AssertNotCalled(t, "SendMessage")
+	shipmentsPusher.AssertNotCalled(t, "SendMessage")
+	defaultPusher.AssertNotCalled(t, "SendMessage")
+}
+
+func TestRouter_SendMessageDefault(t *testing.T) {
+	router, _, _, defaultPusher := newRouter()
+
+	err := router.SendMessage(&queue.MessageDTO{Body: "not json"})
+
+	assert.NoError(t, err)
+	defaultPusher.AssertNumberOfCalls(t, "SendMessage", 1)
+}
+
+func TestRouter_SendMessageErr(t *testing.T) {
+	defaultPusher := new(MockPusher)
+	defaultPusher.On("SendMessage").Return(&client.RejectError{Err: server.NewError(422, "invalid")})
+
+	err := pusher.NewRouter(nil, pusher.Route{Pusher: defaultPusher}).SendMessage(&queue.MessageDTO{Body: "{}"})
+
+	var rejectErr *client.RejectError
+	assert.ErrorAs(t, err, &rejectErr)
+}
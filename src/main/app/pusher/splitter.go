@@ -0,0 +1,194 @@
+package pusher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/infrastructure/kvs"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+	"github.com/src/main/app/model"
+)
+
+// Split attributes of the parts, for the templates and the routes.
+const (
+	SplitIndexAttribute = "split-index"
+	SplitCountAttribute = "split-count"
+)
+
+type SplitterConfig struct {
+	// Path is a json path of the parts in the json of the sns Message, or of the raw payload, i.e. $.orders or
+	// $.orders[*]. A path of a single array splits its elements.
+	Path string
+	// StateTTL expires the delivery state of the messages never acked.
+	StateTTL time.Duration
+}
+
+// Splitter pushes each part of a message as its own delivery. The delivered parts are kept in the kvs, so a
+// redelivery only pushes the failed ones.
+type Splitter struct {
+	path      jp.Expr
+	stateTTL  time.Duration
+	next      Pusher
+	kvsClient kvs.Client[model.SplitStateDTO]
+}
+
+func NewSplitter(config SplitterConfig, next Pusher, kvsClient kvs.Client[model.SplitStateDTO]) (*Splitter, error) {
+	if config.Path == "" {
+		return nil, errors.New("splitter: path is required")
+	}
+
+	path, err := jp.ParseString(config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("splitter: path %s: %w", config.Path, err)
+	}
+
+	return &Splitter{
+		path:      path,
+		stateTTL:  config.StateTTL,
+		next:      next,
+		kvsClient: kvsClient,
+	}, nil
+}
+
+// SendMessage acks the message when every part is delivered. The rejected parts are a reject of the message
+// once the other parts are delivered, until then they are retried with it.
+func (s Splitter) SendMessage(message *queue.MessageDTO) error {
+	parts, err := s.parts(message)
+	if err != nil {
+		return &client.RejectError{Err: fmt.Errorf("splitter: %w", err)}
+	}
+
+	key := stateKey("split", message)
+
+	state, err := s.kvsClient.Get(key)
+	if err != nil {
+		log.Warnf("splitter: state of %s not read, all parts are pushed: %s", key, err)
+	}
+	if state == nil {
+		state = new(model.SplitStateDTO)
+	}
+
+	delivered := len(state.Delivered)
+	var retryErrs, rejectErrs error
+	for i, part := range parts {
+		if slices.Contains(state.Delivered, i) {
+			continue
+		}
+
+		partMessage, partErr := newPart(message, part, i, len(parts))
+		if partErr == nil {
+			partErr = s.next.SendMessage(partMessage)
+		}
+
+		if partErr != nil {
+			metrics.Collector.IncrementCounter(metrics.SplitErrors)
+			partErr = fmt.Errorf("part %d: %w", i, partErr)
+			var rejectErr *client.RejectError
+			if errors.As(partErr, &rejectErr) {
+				rejectErrs = errors.Join(rejectErrs, partErr)
+			} else {
+				retryErrs = errors.Join(retryErrs, partErr)
+			}
+			continue
+		}
+
+		metrics.Collector.IncrementCounter(metrics.SplitParts)
+		state.Delivered = append(state.Delivered, i)
+	}
+
+	if retryErrs == nil && rejectErrs == nil {
+		if delivered > 0 {
+			s.deleteState(key)
+		}
+		return nil
+	}
+
+	if len(state.Delivered) > delivered {
+		if err = s.kvsClient.SaveWithTTL(key, state, s.stateTTL); err != nil {
+			log.Warnf("splitter: state of %s not saved, all parts are retried: %s", key, err)
+		}
+	}
+
+	if retryErrs != nil {
+		return fmt.Errorf("splitter: %w", retryErrs)
+	}
+
+	return &client.RejectError{Err: fmt.Errorf("splitter: %w", rejectErrs)}
+}
+
+// parts are the values selected by the path, the elements of a single array.
+func (s Splitter) parts(message *queue.MessageDTO) ([]any, error) {
+	body := newTemplateData(message).Body
+	if body == nil {
+		return nil, errors.New("payload is not json")
+	}
+
+	values := s.path.Get(body)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("path %s not found", s.path)
+	}
+
+	if elements, ok := values[0].([]any); ok && len(values) == 1 {
+		return elements, nil
+	}
+
+	return values, nil
+}
+
+func (s Splitter) deleteState(key string) {
+	if err := s.kvsClient.Delete(key); err != nil {
+		log.Warnf("splitter: state of %s not deleted: %s", key, err)
+	}
+}
+
+// newPart is a message with the part as sns Message, or as raw payload. The message ids get the part index, so
+// the targets and the fan-out tell the parts apart. The raw part ids are built on the original message id, so they
+// are kept through the re-publishes of the message.
+func newPart(message *queue.MessageDTO, part any, index int, count int) (*queue.MessageDTO, error) {
+	payload, err := json.Marshal(part)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := replacedBody(message.Body, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var messageDTO MessageDTO
+	if err = json.Unmarshal([]byte(body), &messageDTO); err == nil && messageDTO.ID != "" && messageDTO.Message != "" {
+		var envelope map[string]any
+		if err = json.Unmarshal([]byte(body), &envelope); err != nil {
+			return nil, err
+		}
+		envelope["MessageId"] = fmt.Sprintf("%s:%d", messageDTO.ID, index)
+		encoded, marshalErr := json.Marshal(envelope)
+		if marshalErr != nil {
+			return nil, marshalErr
+		}
+		body = string(encoded)
+	}
+
+	attributes := make(map[string]string, len(message.Attributes)+2)
+	for name, value := range message.Attributes {
+		attributes[name] = value
+	}
+	attributes[SplitIndexAttribute] = strconv.Itoa(index)
+	attributes[SplitCountAttribute] = strconv.Itoa(count)
+	delete(attributes, queue.OriginalMessageIDAttribute)
+
+	partMessage := *message
+	partMessage.MessageID = fmt.Sprintf("%s:%d", message.OriginalID(), index)
+	partMessage.Body = body
+	partMessage.Attributes = attributes
+
+	return &partMessage, nil
+}
@@ -0,0 +1,158 @@
+package pusher_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/src/main/app/client"
+	"github.com/src/main/app/container"
+	"github.com/src/main/app/infrastructure/kvs"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/pusher"
+	"github.com/src/main/app/server"
+	"github.com/stretchr/testify/assert"
+)
+
+// partPusher records the parts and fails the ones of errs, by split index.
+type partPusher struct {
+	messages []queue.MessageDTO
+	errs     map[string]error
+}
+
+func (p *partPusher) SendMessage(message *queue.MessageDTO) error {
+	p.messages = append(p.messages, *message)
+	return p.errs[message.Attributes[pusher.SplitIndexAttribute]]
+}
+
+func newSplitter(t *testing.T, path string, next pusher.Pusher) *pusher.Splitter {
+	splitter, err := pusher.NewSplitter(pusher.SplitterConfig{Path: path, StateTTL: time.Minute}, next,
+		kvs.NewElasticCacheClient[model.SplitStateDTO](container.ProvideKVSClient()))
+	assert.NoError(t, err)
+
+	return splitter
+}
+
+func TestNewSplitterErr(t *testing.T) {
+	kvsClient := kvs.NewElasticCacheClient[model.SplitStateDTO](container.ProvideKVSClient())
+
+	_, err := pusher.NewSplitter(pusher.SplitterConfig{}, new(MockPusher), kvsClient)
+	assert.Error(t, err)
+
+	_, err = pusher.NewSplitter(pusher.SplitterConfig{Path: "$.orders[("}, new(MockPusher), kvsClient)
+	assert.Error(t, err)
+}
+
+func TestSplitter_SendMessage(t *testing.T) {
+	next := new(partPusher)
+	splitter := newSplitter(t, "$.orders", next)
+
+	body, err := json.Marshal(pusher.MessageDTO{ID: "split-sns-1", Message: `{"orders": [{"order_id": 1}, {"order_id": 2}]}`})
+	assert.NoError(t, err)
+
+	err = splitter.SendMessage(&queue.MessageDTO{MessageID: "1", Body: string(body), Attributes: map[string]string{"event": "created"}})
+	assert.NoError(t, err)
+
+	assert.Len(t, next.messages, 2)
+	for i, part := range next.messages {
+		var messageDTO pusher.MessageDTO
+		assert.NoError(t, json.Unmarshal([]byte(part.Body), &messageDTO))
+		assert.Equal(t, []string{"split-sns-1:0", "split-sns-1:1"}[i], messageDTO.ID)
+		assert.JSONEq(t, []string{`{"order_id": 1}`, `{"order_id": 2}`}[i], messageDTO.Message)
+		assert.Equal(t, "created", part.Attributes["event"])
+		assert.Equal(t, "2", part.Attributes[pusher.SplitCountAttribute])
+	}
+}
+
+func TestSplitter_SendMessageRaw(t *testing.T) {
+	next := new(partPusher)
+	splitter := newSplitter(t, "$[*].order_id", next)
+
+	err := splitter.SendMessage(&queue.MessageDTO{MessageID: "split-2", Body: `[{"order_id": 1}, {"order_id": 2}]`})
+	assert.NoError(t, err)
+
+	assert.Len(t, next.messages, 2)
+	assert.Equal(t, "split-2:1", next.messages[1].MessageID)
+	assert.Equal(t, "2", next.messages[1].Body)
+}
+
+func TestSplitter_SendMessageRetryFailedParts(t *testing.T) {
+	next := &partPusher{errs: map[string]error{"1": errors.New("internal server error")}}
+	splitter := newSplitter(t, "$.orders", next)
+	message := &queue.MessageDTO{MessageID: "split-3", Body: `{"orders": [{"order_id": 1}, {"order_id": 2}, {"order_id": 3}]}`}
+
+	err := splitter.SendMessage(message)
+	assert.Error(t, err)
+
+	state, err := kvs.NewElasticCacheClient[model.SplitStateDTO](container.ProvideKVSClient()).Get("split:split-3")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, state.Delivered)
+
+	// the redelivery only pushes the failed part
+	next.errs = nil
+	err = splitter.SendMessage(message)
+	assert.NoError(t, err)
+
+	assert.Len(t, next.messages, 4)
+	assert.Equal(t, "split-3:1", next.messages[3].MessageID)
+
+	state, err = kvs.NewElasticCacheClient[model.SplitStateDTO](container.ProvideKVSClient()).Get("split:split-3")
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestSplitter_SendMessageRetryFailedPartsRaw(t *testing.T) {
+	next := &partPusher{errs: map[string]error{"1": errors.New("internal server error")}}
+	splitter := newSplitter(t, "$.orders", next)
+	message := &queue.MessageDTO{MessageID: "orders-0-3", Body: `{"orders": [{"order_id": 1}, {"order_id": 2}]}`, Raw: true}
+
+	err := splitter.SendMessage(message)
+	assert.Error(t, err)
+
+	// a re-produced kafka record has a new offset, the parts keep the ids of the first delivery
+	next.errs = nil
+	message.MessageID = "orders-0-5"
+	message.Attributes = map[string]string{queue.OriginalMessageIDAttribute: "orders-0-3"}
+	err = splitter.SendMessage(message)
+	assert.NoError(t, err)
+
+	assert.Len(t, next.messages, 3)
+	assert.Equal(t, "orders-0-3:1", next.messages[2].MessageID)
+	assert.Equal(t, "orders-0-3:1", next.messages[2].OriginalID())
+}
+
+func TestSplitter_SendMessageReject(t *testing.T) {
+	rejectErr := &client.RejectError{Err: server.NewError(http.StatusUnprocessableEntity, "invalid order")}
+	next := &partPusher{errs: map[string]error{"0": rejectErr, "1": errors.New("internal server error")}}
+	splitter := newSplitter(t, "$.orders", next)
+	message := &queue.MessageDTO{MessageID: "split-4", Body: `{"orders": [{"order_id": 1}, {"order_id": 2}]}`}
+	t.Cleanup(func() {
+		assert.NoError(t, kvs.NewElasticCacheClient[model.SplitStateDTO](container.ProvideKVSClient()).Delete("split:split-4"))
+	})
+
+	// a rejected part is retried until the other parts are delivered
+	err := splitter.SendMessage(message)
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &rejectErr))
+
+	delete(next.errs, "1")
+	err = splitter.SendMessage(message)
+	assert.ErrorAs(t, err, &rejectErr)
+}
+
+func TestSplitter_SendMessageInvalid(t *testing.T) {
+	next := new(partPusher)
+	splitter := newSplitter(t, "$.orders", next)
+
+	for _, body := range []string{"orders", `{"items": []}`} {
+		err := splitter.SendMessage(&queue.MessageDTO{MessageID: "split-5", Body: body})
+		var rejectErr *client.RejectError
+		assert.ErrorAs(t, err, &rejectErr)
+	}
+
+	assert.NoError(t, splitter.SendMessage(&queue.MessageDTO{MessageID: "split-6", Body: `{"orders": []}`}))
+	assert.Empty(t, next.messages)
+}
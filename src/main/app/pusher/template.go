@@ -0,0 +1,158 @@
+package pusher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"text/template"
+
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/infrastructure/queue"
+)
+
+// TemplateConfig is a go template of the json pushed to the target, instead of client.RequestBody.
+type TemplateConfig struct {
+	Text string
+	// Headers are templates rendered once at startup, so they can use constants and config values only.
+	Headers map[string]string
+	// Sample is a message body rendered at startup to validate the template output.
+	Sample string
+	// SampleAttributes are the message attributes of the sample.
+	SampleAttributes map[string]string
+}
+
+// Template renders the pushed json with TemplateData, the funcs are:
+// * json: encodes a value as json, i.e. {"id": {{json .Body.order_id}}},
+// * unwrap: decodes a nested json string, i.e. {{json (unwrap .Body.payload).id}},
+// * config: reads a config value, i.e. {{config "app.name"}},
+// * pathEscape and queryEscape: escape a value of an url, i.e. /orders/{{pathEscape .Body.order_id}}.
+type Template struct {
+	template *template.Template
+	headers  http.Header
+}
+
+// TemplateData is the message pushed, Body is the decoded json of Message or nil when it is not json.
+type TemplateData struct {
+	ID         string
+	Message    string
+	Subject    string
+	Timestamp  string
+	Attributes map[string]string
+	Body       any
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(value any) (string, error) {
+		encoded, err := json.Marshal(value)
+		return string(encoded), err
+	},
+	"unwrap": func(value any) (any, error) {
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("unwrap: %T is not a json string", value)
+		}
+		var decoded any
+		if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+			return nil, fmt.Errorf("unwrap: %w", err)
+		}
+		return decoded, nil
+	},
+	"config": func(key string) string {
+		return config.TryString(key, "")
+	},
+	"pathEscape": func(value any) string {
+		return url.PathEscape(fmt.Sprint(value))
+	},
+	"queryEscape": func(value any) string {
+		return url.QueryEscape(fmt.Sprint(value))
+	},
+}
+
+func NewTemplate(templateConfig TemplateConfig) (*Template, error) {
+	if templateConfig.Text == "" {
+		return nil, errors.New("template: text is required")
+	}
+
+	// a missing field fails the render instead of pushing <no value>, index reads the optional fields
+	parsed, err := template.New("body").Option("missingkey=error").Funcs(templateFuncs).Parse(templateConfig.Text)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+
+	headers := http.Header{}
+	for name, text := range templateConfig.Headers {
+		header, headerErr := template.New(name).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+		if headerErr != nil {
+			return nil, fmt.Errorf("template header %s: %w", name, headerErr)
+		}
+		var value bytes.Buffer
+		// no message data, a header with a message field fails at startup
+		if headerErr = header.Execute(&value, struct{}{}); headerErr != nil {
+			return nil, fmt.Errorf("template header %s: %w", name, headerErr)
+		}
+		headers.Set(name, value.String())
+	}
+
+	bodyTemplate := &Template{
+		template: parsed,
+		headers:  headers,
+	}
+
+	if templateConfig.Sample != "" {
+		if _, err = bodyTemplate.Execute(&queue.MessageDTO{
+			MessageID:  "sample",
+			Body:       templateConfig.Sample,
+			Attributes: templateConfig.SampleAttributes,
+		}); err != nil {
+			return nil, fmt.Errorf("template sample: %w", err)
+		}
+	}
+
+	return bodyTemplate, nil
+}
+
+func (t Template) Headers() http.Header {
+	return t.headers
+}
+
+// Execute renders the json of the message, an output that is not json is an error.
+func (t Template) Execute(message *queue.MessageDTO) (json.RawMessage, error) {
+	var buffer bytes.Buffer
+	if err := t.template.Execute(&buffer, newTemplateData(message)); err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+
+	if !json.Valid(buffer.Bytes()) {
+		if message.Sensitive {
+			return nil, errors.New("template: invalid json output")
+		}
+		return nil, fmt.Errorf("template: invalid json output %s", buffer.String())
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func newTemplateData(message *queue.MessageDTO) TemplateData {
+	data := TemplateData{
+		ID:         message.MessageID,
+		Message:    message.Body,
+		Attributes: message.Attributes,
+	}
+
+	var messageDTO MessageDTO
+	if err := json.Unmarshal([]byte(message.Body), &messageDTO); err == nil && messageDTO.Message != "" {
+		data.ID = messageDTO.ID
+		data.Message = messageDTO.Message
+		data.Subject = messageDTO.Subject
+		data.Timestamp = messageDTO.Timestamp
+	}
+
+	if err := json.Unmarshal([]byte(data.Message), &data.Body); err != nil {
+		data.Body = nil
+	}
+
+	return data
+}
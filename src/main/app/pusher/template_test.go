@@ -0,0 +1,89 @@
+package pusher_test
+
+import (
+	"testing"
+
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/pusher"
+	"github.com/stretchr/testify/assert"
+)
+
+const orderNotification = `{"MessageId":"1","Subject":"Order","Timestamp":"2023-01-01T00:00:00Z",` +
+	`"Message":"{\"id\":1,\"payload\":\"{\\\"total\\\":150}\"}"}`
+
+func TestNewTemplateErr(t *testing.T) {
+	_, err := pusher.NewTemplate(pusher.TemplateConfig{})
+	assert.Error(t, err)
+
+	_, err = pusher.NewTemplate(pusher.TemplateConfig{Text: `{"id": {{json .Body.id}`})
+	assert.Error(t, err)
+
+	_, err = pusher.NewTemplate(pusher.TemplateConfig{Text: `{"id": {{unknown .Body.id}}}`})
+	assert.Error(t, err)
+
+	_, err = pusher.NewTemplate(pusher.TemplateConfig{Text: "{}", Headers: map[string]string{"X-Id": "{{.Body.id}}"}})
+	assert.Error(t, err)
+
+	_, err = pusher.NewTemplate(pusher.TemplateConfig{Text: `{"id": {{.Body.id}}`, Sample: orderNotification})
+	assert.Error(t, err)
+}
+
+func TestTemplate_Execute(t *testing.T) {
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{
+		Text: `{"orderId":{{json .Body.id}},"total":{{json (unwrap .Body.payload).total}},` +
+			`"subject":{{json .Subject}},"event":{{json .Attributes.event}},"app":{{json (config "app.name")}},"source":"orders"}`,
+		Headers:          map[string]string{"X-Source": "orders", "X-App": `{{config "app.name"}}`},
+		Sample:           orderNotification,
+		SampleAttributes: map[string]string{"event": "created"},
+	})
+	assert.NoError(t, err)
+
+	payload, err := template.Execute(&queue.MessageDTO{
+		Body:       orderNotification,
+		Attributes: map[string]string{"event": "created"},
+	})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"orderId":1,"total":150,"subject":"Order","event":"created","app":"go-consumer-app","source":"orders"}`,
+		string(payload))
+	assert.Equal(t, "orders", template.Headers().Get("X-Source"))
+	assert.Equal(t, "go-consumer-app", template.Headers().Get("X-App"))
+}
+
+func TestTemplate_ExecuteRaw(t *testing.T) {
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"id":{{json .ID}},"body":{{json .Body}}}`})
+	assert.NoError(t, err)
+
+	payload, err := template.Execute(&queue.MessageDTO{MessageID: "orders-0-1", Body: `{"order_id":1}`})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"orders-0-1","body":{"order_id":1}}`, string(payload))
+}
+
+func TestTemplate_ExecuteSensitive(t *testing.T) {
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"email": {{.Body.email}}}`})
+	assert.NoError(t, err)
+
+	_, err = template.Execute(&queue.MessageDTO{Body: `{"email": "john@doe.com"}`, Sensitive: true})
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "john@doe.com")
+}
+
+func TestTemplate_ExecuteMissingField(t *testing.T) {
+	template, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"order_id":{{json .Body.order_id}},"event":"{{.Attributes.event}}"}`})
+	assert.NoError(t, err)
+
+	_, err = template.Execute(&queue.MessageDTO{Body: `{"id":1}`, Attributes: map[string]string{"event": "created"}})
+	assert.Error(t, err)
+
+	_, err = template.Execute(&queue.MessageDTO{Body: `{"order_id":1}`})
+	assert.Error(t, err)
+
+	// index reads an optional field
+	optional, err := pusher.NewTemplate(pusher.TemplateConfig{Text: `{"order_id":{{json (index .Body "order_id")}}}`})
+	assert.NoError(t, err)
+
+	payload, err := optional.Execute(&queue.MessageDTO{Body: `{"id":1}`})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"order_id":null}`, string(payload))
+}
@@ -0,0 +1,76 @@
+package registrylocal
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/src/main/app/codec"
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/server"
+)
+
+const (
+	contentType      = "application/vnd.schemaregistry.v1+json"
+	schemaNotFound   = 40403
+	descriptorSetExt = ".pb"
+)
+
+// Server implements GET /schemas/ids/{id} of a schema registry over local files, so codec.Registry can point to it.
+// The .pb files are protobuf descriptor sets served as base64, the other ones avro schemas.
+type Server struct {
+	schemas map[int]codec.SchemaDTO
+}
+
+type Config struct {
+	// Schemas are files by id, the paths are relative to src/resources or absolute.
+	Schemas map[int]string
+}
+
+func New(registryConfig Config) (*Server, error) {
+	schemas := make(map[int]codec.SchemaDTO, len(registryConfig.Schemas))
+	for id, location := range registryConfig.Schemas {
+		if !filepath.IsAbs(location) {
+			location = config.Resource(location)
+		}
+
+		schema, err := os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("registry local: schema %d: %w", id, err)
+		}
+
+		if filepath.Ext(location) == descriptorSetExt {
+			schemas[id] = codec.SchemaDTO{
+				Schema:     base64.StdEncoding.EncodeToString(schema),
+				SchemaType: codec.ProtobufSchemaType,
+			}
+			continue
+		}
+
+		schemas[id] = codec.SchemaDTO{Schema: string(schema), SchemaType: codec.AvroSchemaType}
+	}
+
+	return &Server{schemas: schemas}, nil
+}
+
+func (s Server) Register(app *server.App) {
+	app.Route(http.MethodGet, "/schemas/ids/:id", s.GetSchema)
+}
+
+func (s Server) GetSchema(ctx *fiber.Ctx) error {
+	id, err := strconv.Atoi(ctx.Params("id"))
+	schema, found := s.schemas[id]
+	if err != nil || !found {
+		ctx.Status(http.StatusNotFound)
+		return ctx.JSON(map[string]any{
+			"error_code": schemaNotFound,
+			"message":    fmt.Sprintf("Schema %s not found", ctx.Params("id")),
+		}, contentType)
+	}
+
+	return ctx.JSON(schema, contentType)
+}
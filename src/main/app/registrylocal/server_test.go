@@ -0,0 +1,77 @@
+package registrylocal_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/src/main/app/codec"
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/registrylocal"
+	"github.com/src/main/app/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func newApp(t *testing.T) *server.App {
+	registry, err := registrylocal.New(registrylocal.Config{
+		Schemas: map[int]string{
+			1: "schemas/order_created.avsc",
+			2: "schemas/order_created.pb",
+		},
+	})
+	assert.NoError(t, err)
+
+	app := server.New()
+	registry.Register(app)
+
+	return app
+}
+
+func getSchema(t *testing.T, app *server.App, path string) (int, codec.SchemaDTO) {
+	response, err := app.Server.Test(httptest.NewRequest(http.MethodGet, path, nil))
+	assert.NoError(t, err)
+	defer response.Body.Close()
+
+	var schemaDTO codec.SchemaDTO
+	assert.NoError(t, json.NewDecoder(response.Body).Decode(&schemaDTO))
+
+	return response.StatusCode, schemaDTO
+}
+
+func TestServer_GetSchema(t *testing.T) {
+	app := newApp(t)
+
+	avroSchema, err := os.ReadFile(config.Resource("schemas/order_created.avsc"))
+	assert.NoError(t, err)
+	statusCode, schemaDTO := getSchema(t, app, "/schemas/ids/1")
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, codec.AvroSchemaType, schemaDTO.SchemaType)
+	assert.Equal(t, string(avroSchema), schemaDTO.Schema)
+
+	descriptorSet, err := os.ReadFile(config.Resource("schemas/order_created.pb"))
+	assert.NoError(t, err)
+	statusCode, schemaDTO = getSchema(t, app, "/schemas/ids/2")
+	assert.Equal(t, http.StatusOK, statusCode)
+	assert.Equal(t, codec.ProtobufSchemaType, schemaDTO.SchemaType)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(descriptorSet), schemaDTO.Schema)
+}
+
+func TestServer_GetSchemaNotFound(t *testing.T) {
+	app := newApp(t)
+
+	statusCode, _ := getSchema(t, app, "/schemas/ids/9")
+	assert.Equal(t, http.StatusNotFound, statusCode)
+
+	statusCode, _ = getSchema(t, app, "/schemas/ids/orders")
+	assert.Equal(t, http.StatusNotFound, statusCode)
+}
+
+func TestNewErr(t *testing.T) {
+	_, err := registrylocal.New(registrylocal.Config{
+		Schemas: map[int]string{1: "schemas/missing.avsc"},
+	})
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"net/http"
 
+	"github.com/src/main/app/config"
 	"github.com/src/main/app/container"
 	"github.com/src/main/app/server"
 )
@@ -12,4 +13,12 @@ func RegisterRoutes(app *server.App) {
 	app.Route(http.MethodGet, "/consumer/status", container.ProvideConsumerHandler().GetStatus)
 	app.Route(http.MethodPut, "/consumer/start", container.ProvideConsumerHandler().Start)
 	app.Route(http.MethodPut, "/consumer/stop", container.ProvideConsumerHandler().Stop)
+
+	if config.TryBool("sns.enabled", false) {
+		app.Route(http.MethodPost, "/sns", container.ProvideSNSHandler().Receive)
+	}
+
+	app.Route(http.MethodPost, "/queues/:name/messages", container.ProvideAdminAuth(), container.ProvideQueueHandler().SendMessages)
+	app.Route(http.MethodPost, "/queues/:name/redrive", container.ProvideAdminAuth(), container.ProvideQueueHandler().Redrive)
+	app.Route(http.MethodGet, "/queues/:name/peek", container.ProvideAdminAuth(), container.ProvideQueueHandler().Peek)
 }
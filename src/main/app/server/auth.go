@@ -0,0 +1,23 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// BearerAuth protects the admin routes with a static bearer token, an empty token denies every request.
+func BearerAuth(token string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		authorization := ctx.Get(fiber.HeaderAuthorization)
+		bearer, found := strings.CutPrefix(authorization, "Bearer ")
+
+		if token == "" || !found || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			return NewError(http.StatusUnauthorized, "unauthorized")
+		}
+
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,59 @@
+package server_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/src/main/app/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthApp(token string) *server.App {
+	app := server.New()
+	app.Route(http.MethodPost, "/admin", server.BearerAuth(token), func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(http.StatusOK)
+	})
+
+	return app
+}
+
+func TestBearerAuth(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/admin", nil)
+	request.Header.Set("Authorization", "Bearer secret")
+
+	response, err := newAuthApp("secret").Server.Test(request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestBearerAuthInvalidToken(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/admin", nil)
+	request.Header.Set("Authorization", "Bearer invalid")
+
+	response, err := newAuthApp("secret").Server.Test(request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestBearerAuthMissingHeader(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/admin", nil)
+
+	response, err := newAuthApp("secret").Server.Test(request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestBearerAuthNotConfigured(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/admin", nil)
+	request.Header.Set("Authorization", "Bearer ")
+
+	response, err := newAuthApp("").Server.Test(request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
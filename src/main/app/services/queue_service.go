@@ -0,0 +1,375 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/src/main/app/helpers/redact"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/server"
+	"golang.org/x/time/rate"
+)
+
+// MaxSendMessages per publish request, larger batches are chunked by each backend.
+const MaxSendMessages = 100
+
+// MaxDelaySeconds of a sent message, the sqs limit.
+const MaxDelaySeconds = 900
+
+// DefaultRedriveMessages moved by a redrive without max messages.
+const DefaultRedriveMessages = 100
+
+// MaxRedriveMessages moved by a single redrive.
+const MaxRedriveMessages = 10_000
+
+// MaxPeekMessages by peek, the sqs receive limit.
+const MaxPeekMessages = 10
+
+type IQueueService interface {
+	Send(ctx context.Context, name string, sendMessagesDTO *model.SendMessagesDTO) (*model.SendResultsDTO, error)
+	Redrive(ctx context.Context, name string, redriveDTO *model.RedriveDTO) (*model.RedriveResultDTO, error)
+	Peek(ctx context.Context, name string, maxMsg int) (*model.PeekDTO, error)
+}
+
+// QueueService exposes the configured queues to operators, only the allowed queues are resolved.
+type QueueService struct {
+	queues   map[string]bool
+	redactor *redact.Redactor
+	resolver func(name string) (queue.Service, error)
+}
+
+type QueueConfig struct {
+	// Queues allowed by name of the queues.<name> config block, empty allows none.
+	Queues []string
+	// Redact masks the body fields and attributes by name in the peeked messages.
+	Redact []string
+}
+
+func NewQueueService(config QueueConfig, resolver func(name string) (queue.Service, error)) *QueueService {
+	queues := make(map[string]bool)
+	for _, name := range config.Queues {
+		queues[name] = true
+	}
+
+	return &QueueService{
+		queues:   queues,
+		redactor: redact.NewRedactor(config.Redact),
+		resolver: resolver,
+	}
+}
+
+// Send publishes the messages to the queue, the result of each message is returned in order.
+func (s QueueService) Send(ctx context.Context, name string, sendMessagesDTO *model.SendMessagesDTO) (*model.SendResultsDTO, error) {
+	if len(sendMessagesDTO.Messages) == 0 || len(sendMessagesDTO.Messages) > MaxSendMessages {
+		return nil, server.NewError(http.StatusBadRequest, fmt.Sprintf("messages must be between 1 and %d", MaxSendMessages))
+	}
+
+	messages := make([]queue.SendMessageDTO, len(sendMessagesDTO.Messages))
+	for i, message := range sendMessagesDTO.Messages {
+		if message.Body == "" {
+			return nil, server.NewError(http.StatusBadRequest, fmt.Sprintf("message %d: empty body", i))
+		}
+		if message.DelaySeconds < 0 || message.DelaySeconds > MaxDelaySeconds {
+			return nil, server.NewError(http.StatusBadRequest,
+				fmt.Sprintf("message %d: delay_seconds must be between 0 and %d", i, MaxDelaySeconds))
+		}
+		messages[i] = queue.SendMessageDTO{
+			Body:       message.Body,
+			Attributes: message.Attributes,
+			Delay:      time.Duration(message.DelaySeconds) * time.Second,
+		}
+	}
+
+	queueService, err := s.queue(name)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := queueService.SendBatch(ctx, messages)
+	if err != nil {
+		if errors.Is(err, queue.ErrDelayNotSupported) {
+			return nil, server.NewError(http.StatusBadRequest, err.Error())
+		}
+		return nil, err
+	}
+
+	sendResultsDTO := &model.SendResultsDTO{Results: make([]model.SendResultDTO, len(results))}
+	for i, result := range results {
+		sendResultsDTO.Results[i].MessageID = result.MessageID
+		if result.Err != nil {
+			sendResultsDTO.Results[i].Error = result.Err.Error()
+		}
+	}
+
+	log.Infof("%d messages sent to queue %s", len(messages), name)
+
+	return sendResultsDTO, nil
+}
+
+// Redrive moves the messages matching the filter from the queue, usually a dead letter queue, to the destination.
+// It stops at max messages, when the queue has no visible messages left, when a message is received again, after
+// one pass over the messages counted at the start or at the first send error, the destination is likely still down.
+// The skipped and not moved messages are nacked, they are available again after the nack delay of the backend, on
+// kafka they are produced again with another id. A dry run peeks the queue, nothing is received.
+func (s QueueService) Redrive(ctx context.Context, name string, redriveDTO *model.RedriveDTO) (*model.RedriveResultDTO, error) {
+	if redriveDTO.Destination == "" || redriveDTO.Destination == name {
+		return nil, server.NewError(http.StatusBadRequest, "destination must be another queue")
+	}
+
+	maxMessages := redriveDTO.MaxMessages
+	if maxMessages == 0 {
+		maxMessages = DefaultRedriveMessages
+	}
+	if maxMessages < 0 || maxMessages > MaxRedriveMessages {
+		return nil, server.NewError(http.StatusBadRequest, fmt.Sprintf("max_messages must be between 1 and %d", MaxRedriveMessages))
+	}
+
+	if redriveDTO.Rate < 0 {
+		return nil, server.NewError(http.StatusBadRequest, "rate must be positive")
+	}
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if redriveDTO.Rate > 0 {
+		limiter = rate.NewLimiter(rate.Limit(redriveDTO.Rate), 1)
+	}
+
+	source, err := s.queue(name)
+	if err != nil {
+		return nil, err
+	}
+
+	destination, err := s.queue(redriveDTO.Destination)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &redriveRun{
+		source:      source,
+		destination: destination,
+		filter:      redriveDTO.Filter,
+		maxMessages: maxMessages,
+		limiter:     limiter,
+		received:    make(map[string]bool),
+		pending:     -1,
+		result:      &model.RedriveResultDTO{DryRun: redriveDTO.DryRun},
+	}
+
+	if !redriveDTO.DryRun {
+		count, countErr := source.Count(ctx)
+		if countErr != nil {
+			log.Warnf("redrive from queue %s: count error, the redrive is not limited to one pass: %s", name, countErr)
+		} else {
+			run.pending = aws.ToInt(count)
+		}
+	}
+
+	if redriveDTO.DryRun {
+		if err = run.dryRun(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	for !redriveDTO.DryRun && run.result.Matched < maxMessages && run.pending != 0 {
+		messages, receiveErr := source.Receive(ctx)
+		if receiveErr != nil {
+			return nil, receiveErr
+		}
+
+		if len(messages) == 0 || run.redrive(ctx, messages) {
+			break
+		}
+	}
+
+	result := run.result
+	log.Infof("redrive from queue %s to queue %s: %d matched, %d moved, %d skipped, %d failed, dry run %t",
+		name, redriveDTO.Destination, result.Matched, result.Moved, result.Skipped, result.Failed, result.DryRun)
+
+	return result, nil
+}
+
+type redriveRun struct {
+	source      queue.Service
+	destination queue.Service
+	filter      *model.RedriveFilterDTO
+	maxMessages int
+	limiter     *rate.Limiter
+	// received message ids, a message received again means the whole queue was seen
+	received map[string]bool
+	// pending messages of the pass, -1 without count, the skipped kafka records come back with another id
+	pending int
+	result  *model.RedriveResultDTO
+}
+
+// dryRun counts the matching messages of a peek, the messages are left available to the consumers.
+func (r *redriveRun) dryRun(ctx context.Context, name string) error {
+	peeker, ok := r.source.(queue.Peeker)
+	if !ok {
+		return server.NewError(http.StatusNotImplemented, fmt.Sprintf("dry run not supported by queue %s", name))
+	}
+
+	messages, err := peeker.Peek(ctx, r.maxMessages)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if r.result.Matched >= r.maxMessages {
+			break
+		}
+
+		if !matches(r.filter, message) {
+			r.result.Skipped++
+			continue
+		}
+
+		r.result.Matched++
+	}
+
+	return nil
+}
+
+// redrive moves a received batch, it returns true when the redrive must stop. The messages that are not moved are
+// nacked.
+func (r *redriveRun) redrive(ctx context.Context, messages []queue.MessageDTO) bool {
+	for i, message := range messages {
+		if r.result.Matched >= r.maxMessages || r.received[message.MessageID] || r.pending == 0 {
+			r.release(ctx, messages[i:])
+			return true
+		}
+		r.received[message.MessageID] = true
+		if r.pending > 0 {
+			r.pending--
+		}
+
+		if !matches(r.filter, message) {
+			r.result.Skipped++
+			metrics.Collector.IncrementCounter(metrics.RedriveSkipped)
+			r.release(ctx, messages[i:i+1])
+			continue
+		}
+
+		r.result.Matched++
+
+		if err := r.limiter.Wait(ctx); err != nil {
+			r.release(ctx, messages[i:])
+			return true
+		}
+
+		if _, err := r.destination.Send(ctx, queue.SendMessageDTO{Body: message.Body, Attributes: message.Attributes}); err != nil {
+			r.result.Failed++
+			metrics.Collector.IncrementCounter(metrics.RedriveFailed)
+			log.Errorf("redrive message %s: %s", message.MessageID, err)
+			r.release(ctx, messages[i:])
+			return true
+		}
+
+		// sent but not deleted, the message is sent again by the next redrive
+		if err := r.source.Delete(ctx, message.ReceiptHandle); err != nil {
+			r.result.Failed++
+			metrics.Collector.IncrementCounter(metrics.RedriveFailed)
+			log.Errorf("redrive message %s sent but not deleted: %s", message.MessageID, err)
+			r.release(ctx, messages[i:i+1])
+			continue
+		}
+
+		r.result.Moved++
+		metrics.Collector.IncrementCounter(metrics.RedriveMoved)
+	}
+
+	return false
+}
+
+// release nacks the messages with the backend default delay, so the backends tracking the in flight messages stop
+// it, i.e. the in-progress acks of nats. Without nack the messages are visible again after the visibility timeout.
+func (r *redriveRun) release(ctx context.Context, messages []queue.MessageDTO) {
+	nacker, ok := r.source.(queue.Nacker)
+	if !ok {
+		return
+	}
+
+	for _, message := range messages {
+		if err := nacker.Nack(ctx, message.ReceiptHandle, 0); err != nil {
+			log.Warnf("redrive message %s not released: %s", message.MessageID, err)
+		}
+	}
+}
+
+// matches the message body and every filter attribute, a nil filter matches any message.
+func matches(filter *model.RedriveFilterDTO, message queue.MessageDTO) bool {
+	if filter == nil {
+		return true
+	}
+
+	if filter.BodyContains != "" && !strings.Contains(message.Body, filter.BodyContains) {
+		return false
+	}
+
+	for key, value := range filter.Attributes {
+		if actual, found := message.Attributes[key]; !found || actual != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Peek returns the decoded and redacted messages of the queue, with its depth. The messages are left available
+// to the consumers, see the Peek of each backend for its visibility impact.
+func (s QueueService) Peek(ctx context.Context, name string, maxMsg int) (*model.PeekDTO, error) {
+	if maxMsg < 1 || maxMsg > MaxPeekMessages {
+		return nil, server.NewError(http.StatusBadRequest, fmt.Sprintf("max must be between 1 and %d", MaxPeekMessages))
+	}
+
+	queueService, err := s.queue(name)
+	if err != nil {
+		return nil, err
+	}
+
+	peeker, ok := queueService.(queue.Peeker)
+	if !ok {
+		return nil, server.NewError(http.StatusNotImplemented, fmt.Sprintf("peek not supported by queue %s", name))
+	}
+
+	messages, err := peeker.Peek(ctx, maxMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	peekDTO := &model.PeekDTO{Queue: name, Messages: make([]model.PeekMessageDTO, len(messages))}
+	for i, message := range messages {
+		peekDTO.Messages[i] = model.PeekMessageDTO{
+			MessageID:    message.MessageID,
+			ReceiveCount: message.ReceiveCount,
+			Attributes:   s.redactor.Attributes(message.Attributes),
+			Body:         s.redactor.Body(message.Body),
+		}
+	}
+
+	count, err := queueService.Count(ctx)
+	if err != nil {
+		log.Warnf("peek: queue %s count: %s", name, err)
+	}
+	peekDTO.Count = count
+
+	return peekDTO, nil
+}
+
+func (s QueueService) queue(name string) (queue.Service, error) {
+	if !s.queues[name] {
+		return nil, server.NewError(http.StatusNotFound, fmt.Sprintf("queue %s not found", name))
+	}
+
+	queueService, err := s.resolver(name)
+	if err != nil {
+		return nil, server.NewError(http.StatusInternalServerError, fmt.Sprintf("queue %s: %s", name, err))
+	}
+
+	return queueService, nil
+}
@@ -0,0 +1,375 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockQueueService struct {
+	mock.Mock
+}
+
+func (m *MockQueueService) Receive(_ context.Context) ([]queue.MessageDTO, error) {
+	args := m.Called()
+	return args.Get(0).([]queue.MessageDTO), args.Error(1)
+}
+
+func (m *MockQueueService) Send(_ context.Context, message queue.SendMessageDTO) (string, error) {
+	args := m.Called(message)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockQueueService) SendBatch(_ context.Context, messages []queue.SendMessageDTO) ([]queue.SendResultDTO, error) {
+	args := m.Called(messages)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]queue.SendResultDTO), args.Error(1)
+}
+
+func (m *MockQueueService) Delete(_ context.Context, receiptHandle string) error {
+	args := m.Called(receiptHandle)
+	return args.Error(0)
+}
+
+func (m *MockQueueService) Nack(_ context.Context, receiptHandle string, delay time.Duration) error {
+	args := m.Called(receiptHandle, delay)
+	return args.Error(0)
+}
+
+func (m *MockQueueService) Count(_ context.Context) (*int, error) {
+	args := m.Called()
+	return args.Get(0).(*int), args.Error(1)
+}
+
+func newQueueService(queueService queue.Service) *services.QueueService {
+	return services.NewQueueService(services.QueueConfig{Queues: []string{"orders"}},
+		func(name string) (queue.Service, error) {
+			if name != "orders" {
+				return nil, errors.New("invalid queue")
+			}
+			return queueService, nil
+		})
+}
+
+func TestQueueService_Send(t *testing.T) {
+	queueService := new(MockQueueService)
+	queueService.On("SendBatch", []queue.SendMessageDTO{
+		{Body: "msg1", Attributes: map[string]string{"event": "created"}},
+		{Body: "msg2", Delay: 10 * time.Second},
+	}).Return([]queue.SendResultDTO{{MessageID: "1"}, {Err: errors.New("throttled")}}, nil)
+
+	actual, err := newQueueService(queueService).Send(context.Background(), "orders", &model.SendMessagesDTO{
+		Messages: []model.SendMessageDTO{
+			{Body: "msg1", Attributes: map[string]string{"event": "created"}},
+			{Body: "msg2", DelaySeconds: 10},
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.SendResultDTO{{MessageID: "1"}, {Error: "throttled"}}, actual.Results)
+}
+
+func TestQueueService_SendQueueNotAllowed(t *testing.T) {
+	_, err := newQueueService(new(MockQueueService)).Send(context.Background(), "users", &model.SendMessagesDTO{
+		Messages: []model.SendMessageDTO{{Body: "msg1"}},
+	})
+
+	assert.Equal(t, http.StatusNotFound, statusCode(err))
+}
+
+func TestQueueService_SendInvalidMessages(t *testing.T) {
+	queueService := newQueueService(new(MockQueueService))
+
+	_, err := queueService.Send(context.Background(), "orders", &model.SendMessagesDTO{})
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+
+	_, err = queueService.Send(context.Background(), "orders", &model.SendMessagesDTO{
+		Messages: []model.SendMessageDTO{{Body: ""}},
+	})
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+
+	_, err = queueService.Send(context.Background(), "orders", &model.SendMessagesDTO{
+		Messages: []model.SendMessageDTO{{Body: "msg1", DelaySeconds: services.MaxDelaySeconds + 1}},
+	})
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+}
+
+func TestQueueService_SendDelayNotSupported(t *testing.T) {
+	queueService := new(MockQueueService)
+	queueService.On("SendBatch", mock.Anything).Return(nil, queue.ErrDelayNotSupported)
+
+	_, err := newQueueService(queueService).Send(context.Background(), "orders", &model.SendMessagesDTO{
+		Messages: []model.SendMessageDTO{{Body: "msg1", DelaySeconds: 10}},
+	})
+
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+}
+
+func TestQueueService_SendErr(t *testing.T) {
+	queueService := new(MockQueueService)
+	queueService.On("SendBatch", mock.Anything).Return(nil, errors.New("internal server error"))
+
+	_, err := newQueueService(queueService).Send(context.Background(), "orders", &model.SendMessagesDTO{
+		Messages: []model.SendMessageDTO{{Body: "msg1"}},
+	})
+
+	assert.Equal(t, http.StatusInternalServerError, statusCode(err))
+}
+
+func newRedriveQueues(t *testing.T, messages ...queue.SendMessageDTO) (*services.QueueService, *queue.MemoryQueueService, *queue.MemoryQueueService) {
+	broker := queue.NewMemoryBroker()
+	queues := make(map[string]*queue.MemoryQueueService)
+	for _, name := range []string{"orders", "orders-dlq"} {
+		queueClient, err := queue.NewMemoryClient(queue.MemoryConfig{
+			Name:              name,
+			Parallel:          2,
+			Timeout:           10,
+			VisibilityTimeout: 30000,
+		}, broker)
+		assert.NoError(t, err)
+		queues[name] = queueClient
+	}
+
+	_, err := queues["orders-dlq"].SendBatch(context.Background(), messages)
+	assert.NoError(t, err)
+
+	queueService := services.NewQueueService(services.QueueConfig{Queues: []string{"orders", "orders-dlq"}},
+		func(name string) (queue.Service, error) {
+			return queues[name], nil
+		})
+
+	return queueService, queues["orders-dlq"], queues["orders"]
+}
+
+func TestQueueService_Redrive(t *testing.T) {
+	queueService, dlq, orders := newRedriveQueues(t,
+		queue.SendMessageDTO{Body: "msg1", Attributes: map[string]string{"event": "created"}},
+		queue.SendMessageDTO{Body: "msg2", Attributes: map[string]string{"event": "deleted"}},
+		queue.SendMessageDTO{Body: "msg3", Attributes: map[string]string{"event": "created"}})
+
+	actual, err := queueService.Redrive(context.Background(), "orders-dlq", &model.RedriveDTO{
+		Destination: "orders",
+		Filter:      &model.RedriveFilterDTO{Attributes: map[string]string{"event": "created"}},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &model.RedriveResultDTO{Matched: 2, Moved: 2, Skipped: 1}, actual)
+
+	messages, err := orders.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "msg1", messages[0].Body)
+	assert.Equal(t, map[string]string{"event": "created"}, messages[0].Attributes)
+
+	count, err := dlq.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, *count)
+}
+
+func TestQueueService_RedriveMaxMessages(t *testing.T) {
+	queueService, _, orders := newRedriveQueues(t,
+		queue.SendMessageDTO{Body: "msg1"}, queue.SendMessageDTO{Body: "msg2"}, queue.SendMessageDTO{Body: "msg3"})
+
+	actual, err := queueService.Redrive(context.Background(), "orders-dlq", &model.RedriveDTO{
+		Destination: "orders",
+		MaxMessages: 2,
+		Rate:        1000,
+		Filter:      &model.RedriveFilterDTO{BodyContains: "msg"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, actual.Moved)
+
+	count, err := orders.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, *count)
+}
+
+func TestQueueService_RedriveDryRun(t *testing.T) {
+	queueService, dlq, orders := newRedriveQueues(t, queue.SendMessageDTO{Body: "msg1"}, queue.SendMessageDTO{Body: "msg2"})
+
+	actual, err := queueService.Redrive(context.Background(), "orders-dlq", &model.RedriveDTO{
+		Destination: "orders",
+		DryRun:      true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &model.RedriveResultDTO{Matched: 2, DryRun: true}, actual)
+
+	count, err := orders.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, *count)
+
+	// the messages are not received, they are still visible
+	messages, err := dlq.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 2)
+}
+
+func TestQueueService_RedriveDryRunNotSupported(t *testing.T) {
+	queueService := services.NewQueueService(services.QueueConfig{Queues: []string{"orders", "orders-dlq"}},
+		func(string) (queue.Service, error) {
+			return new(MockQueueService), nil
+		})
+
+	_, err := queueService.Redrive(context.Background(), "orders-dlq", &model.RedriveDTO{Destination: "orders", DryRun: true})
+
+	assert.Equal(t, http.StatusNotImplemented, statusCode(err))
+}
+
+func TestQueueService_RedriveSkipped(t *testing.T) {
+	dlq := new(MockQueueService)
+	dlq.On("Receive").Return([]queue.MessageDTO{
+		{MessageID: "1", Body: "msg1", ReceiptHandle: "r1"},
+		{MessageID: "2", Body: "other", ReceiptHandle: "r2"},
+	}, nil)
+	dlq.On("Count").Return(aws.Int(5), nil)
+	dlq.On("Delete", "r1").Return(nil)
+	dlq.On("Nack", mock.Anything, time.Duration(0)).Return(nil)
+	orders := new(MockQueueService)
+	orders.On("Send", mock.Anything).Return("3", nil)
+
+	queueService := services.NewQueueService(services.QueueConfig{Queues: []string{"orders", "orders-dlq"}},
+		func(name string) (queue.Service, error) {
+			if name == "orders" {
+				return orders, nil
+			}
+			return dlq, nil
+		})
+
+	actual, err := queueService.Redrive(context.Background(), "orders-dlq", &model.RedriveDTO{
+		Destination: "orders",
+		Filter:      &model.RedriveFilterDTO{BodyContains: "msg"},
+	})
+
+	assert.NoError(t, err)
+	// the second receive returns the same messages, the redrive stops
+	assert.Equal(t, &model.RedriveResultDTO{Matched: 1, Moved: 1, Skipped: 1}, actual)
+	dlq.AssertNumberOfCalls(t, "Receive", 2)
+	dlq.AssertCalled(t, "Nack", "r2", time.Duration(0))
+	dlq.AssertNumberOfCalls(t, "Delete", 1)
+}
+
+func TestQueueService_RedriveOnePass(t *testing.T) {
+	// kafka produces the skipped records again, they come back with another id
+	dlq := new(MockQueueService)
+	dlq.On("Count").Return(aws.Int(2), nil)
+	dlq.On("Receive").Return([]queue.MessageDTO{{MessageID: "1", Body: "other", ReceiptHandle: "r1"}}, nil).Once()
+	dlq.On("Receive").Return([]queue.MessageDTO{{MessageID: "2", Body: "other", ReceiptHandle: "r2"}}, nil).Once()
+	dlq.On("Receive").Return([]queue.MessageDTO{{MessageID: "3", Body: "other", ReceiptHandle: "r3"}}, nil)
+	dlq.On("Nack", mock.Anything, time.Duration(0)).Return(nil)
+
+	queueService := services.NewQueueService(services.QueueConfig{Queues: []string{"orders", "orders-dlq"}},
+		func(name string) (queue.Service, error) {
+			if name == "orders" {
+				return new(MockQueueService), nil
+			}
+			return dlq, nil
+		})
+
+	actual, err := queueService.Redrive(context.Background(), "orders-dlq", &model.RedriveDTO{
+		Destination: "orders",
+		Filter:      &model.RedriveFilterDTO{BodyContains: "msg"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &model.RedriveResultDTO{Skipped: 2}, actual)
+	dlq.AssertNumberOfCalls(t, "Receive", 2)
+	dlq.AssertNumberOfCalls(t, "Nack", 2)
+}
+
+func TestQueueService_RedriveSendErr(t *testing.T) {
+	dlq := new(MockQueueService)
+	dlq.On("Receive").Return([]queue.MessageDTO{{MessageID: "1", Body: "msg1"}, {MessageID: "2", Body: "msg2"}}, nil)
+	dlq.On("Count").Return(aws.Int(2), nil)
+	dlq.On("Nack", mock.Anything, time.Duration(0)).Return(nil)
+	orders := new(MockQueueService)
+	orders.On("Send", mock.Anything).Return("", errors.New("internal server error"))
+
+	queueService := services.NewQueueService(services.QueueConfig{Queues: []string{"orders", "orders-dlq"}},
+		func(name string) (queue.Service, error) {
+			if name == "orders" {
+				return orders, nil
+			}
+			return dlq, nil
+		})
+
+	actual, err := queueService.Redrive(context.Background(), "orders-dlq", &model.RedriveDTO{Destination: "orders"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &model.RedriveResultDTO{Matched: 1, Failed: 1}, actual)
+	orders.AssertNumberOfCalls(t, "Send", 1)
+	dlq.AssertNotCalled(t, "Delete", mock.Anything)
+	dlq.AssertNumberOfCalls(t, "Nack", 2)
+}
+
+func TestQueueService_RedriveInvalid(t *testing.T) {
+	queueService := newQueueService(new(MockQueueService))
+
+	_, err := queueService.Redrive(context.Background(), "orders", &model.RedriveDTO{Destination: "orders"})
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+
+	_, err = queueService.Redrive(context.Background(), "orders", &model.RedriveDTO{Destination: "users"})
+	assert.Equal(t, http.StatusNotFound, statusCode(err))
+
+	_, err = queueService.Redrive(context.Background(), "orders", &model.RedriveDTO{Destination: "users", MaxMessages: -1})
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+
+	_, err = queueService.Redrive(context.Background(), "orders", &model.RedriveDTO{Destination: "users", Rate: -1})
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+}
+
+func TestQueueService_Peek(t *testing.T) {
+	broker := queue.NewMemoryBroker()
+	orders, err := queue.NewMemoryClient(queue.MemoryConfig{Name: "orders", Parallel: 10, Timeout: 10}, broker)
+	assert.NoError(t, err)
+	_, err = orders.Send(context.Background(), queue.SendMessageDTO{
+		Body:       `{"Message":"{\"order_id\":1,\"card\":\"4111\"}"}`,
+		Attributes: map[string]string{"token": "secret"},
+	})
+	assert.NoError(t, err)
+
+	queueService := services.NewQueueService(services.QueueConfig{
+		Queues: []string{"orders"},
+		Redact: []string{"card", "token"},
+	}, func(_ string) (queue.Service, error) {
+		return orders, nil
+	})
+
+	actual, err := queueService.Peek(context.Background(), "orders", 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "orders", actual.Queue)
+	assert.Equal(t, 1, *actual.Count)
+	assert.Len(t, actual.Messages, 1)
+	assert.Equal(t, map[string]string{"token": "[REDACTED]"}, actual.Messages[0].Attributes)
+	assert.Equal(t, map[string]any{"Message": map[string]any{"order_id": float64(1), "card": "[REDACTED]"}},
+		actual.Messages[0].Body)
+
+	messages, err := orders.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+}
+
+func TestQueueService_PeekNotSupported(t *testing.T) {
+	_, err := newQueueService(new(MockQueueService)).Peek(context.Background(), "orders", 10)
+
+	assert.Equal(t, http.StatusNotImplemented, statusCode(err))
+}
+
+func TestQueueService_PeekInvalidMax(t *testing.T) {
+	_, err := newQueueService(new(MockQueueService)).Peek(context.Background(), "orders", services.MaxPeekMessages+1)
+
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+}
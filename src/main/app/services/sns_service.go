@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/infrastructure/sns"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/metrics"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/server"
+)
+
+type ISNSService interface {
+	Receive(ctx context.Context, body []byte) error
+}
+
+// MessageIngester runs a message through the consumer pipeline, see consumer.Consumer.Ingest.
+type MessageIngester interface {
+	Ingest(ctx context.Context, message *queue.MessageDTO) error
+}
+
+// SNSService ingests sns http notifications into the consumer pipeline: filter, validation, decode, decrypt, split,
+// enrich, push and dead letter queue, as the queue messages.
+type SNSService struct {
+	snsClient       sns.IClient
+	ingester        MessageIngester
+	consumerService IConsumerService
+	topicArns       map[string]bool
+}
+
+type SNSConfig struct {
+	// TopicArns accepted, empty accepts any topic.
+	TopicArns []string
+}
+
+func NewSNSService(config SNSConfig, snsClient sns.IClient, ingester MessageIngester, consumerService IConsumerService) *SNSService {
+	topicArns := make(map[string]bool)
+	for _, topicArn := range config.TopicArns {
+		topicArns[topicArn] = true
+	}
+
+	return &SNSService{
+		snsClient:       snsClient,
+		ingester:        ingester,
+		consumerService: consumerService,
+		topicArns:       topicArns,
+	}
+}
+
+// Receive verifies the notification and confirms subscriptions or ingests notifications.
+// An ingest error is returned so sns retries the delivery, as a not deleted queue message.
+func (s SNSService) Receive(ctx context.Context, body []byte) error {
+	notification := new(sns.Notification)
+	if err := json.Unmarshal(body, notification); err != nil {
+		return server.NewError(http.StatusBadRequest, fmt.Sprintf("invalid sns notification: %s", err))
+	}
+
+	if len(s.topicArns) > 0 && !s.topicArns[notification.TopicArn] {
+		return server.NewError(http.StatusForbidden, fmt.Sprintf("topic %s not allowed", notification.TopicArn))
+	}
+
+	if err := s.snsClient.Verify(ctx, notification); err != nil {
+		metrics.Collector.IncrementCounter(metrics.SNSInvalidSignature)
+		return server.NewError(http.StatusForbidden, err.Error())
+	}
+
+	switch notification.Type {
+	case sns.SubscriptionConfirmationType:
+		if err := s.snsClient.ConfirmSubscription(ctx, notification); err != nil {
+			return err
+		}
+		log.Infof("sns subscription to topic %s confirmed", notification.TopicArn)
+		return nil
+	case sns.UnsubscribeConfirmationType:
+		log.Warnf("sns subscription to topic %s unsubscribed", notification.TopicArn)
+		return nil
+	}
+
+	if s.consumerService.GetAppStatus().Status == model.Stopped {
+		return server.NewError(http.StatusServiceUnavailable, "consumer stopped")
+	}
+
+	metrics.Collector.IncrementCounter(metrics.SNSNotifications)
+
+	return s.ingester.Ingest(ctx, &queue.MessageDTO{
+		MessageID:  notification.MessageID,
+		Body:       string(body),
+		Attributes: notification.Attributes(),
+	})
+}
@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/src/main/app/consumer"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/infrastructure/sns"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/pusher"
+	"github.com/src/main/app/server"
+	"github.com/src/main/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSNSClient struct {
+	mock.Mock
+}
+
+func (m *MockSNSClient) Verify(_ context.Context, notification *sns.Notification) error {
+	args := m.Called(notification.Type)
+	return args.Error(0)
+}
+
+func (m *MockSNSClient) ConfirmSubscription(_ context.Context, notification *sns.Notification) error {
+	args := m.Called(notification.SubscribeURL)
+	return args.Error(0)
+}
+
+type MockIngester struct {
+	mock.Mock
+}
+
+func (m *MockIngester) Ingest(_ context.Context, message *queue.MessageDTO) error {
+	args := m.Called(message.MessageID)
+	return args.Error(0)
+}
+
+type MockPusher struct {
+	mock.Mock
+}
+
+func (m *MockPusher) SendMessage(message *queue.MessageDTO) error {
+	args := m.Called(message.MessageID)
+	return args.Error(0)
+}
+
+type MockConsumerService struct {
+	mock.Mock
+}
+
+func (m *MockConsumerService) GetAppStatus() *model.AppStatusDTO {
+	args := m.Called()
+	return args.Get(0).(*model.AppStatusDTO)
+}
+
+func (m *MockConsumerService) Stop() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockConsumerService) Start() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+const notification = `{"Type":"Notification","MessageId":"1","TopicArn":"arn:aws:sns:us-east-1:000000000000:orders-topic",` +
+	`"Message":"{\"order_id\": 1}","Timestamp":"2023-11-20T12:00:00.000Z","SignatureVersion":"2"}`
+
+const attributeNotification = `{"Type":"Notification","MessageId":"1",` +
+	`"TopicArn":"arn:aws:sns:us-east-1:000000000000:orders-topic","Message":"{\"order_id\": 1}",` +
+	`"MessageAttributes":{"event":{"Type":"String","Value":"order.created"}}}`
+
+const subscriptionConfirmation = `{"Type":"SubscriptionConfirmation","MessageId":"1",` +
+	`"TopicArn":"arn:aws:sns:us-east-1:000000000000:orders-topic","SubscribeURL":"https://sns.us-east-1.amazonaws.com/confirm"}`
+
+func newSNSService(config services.SNSConfig, status model.Status) (*services.SNSService, *MockSNSClient, *MockIngester) {
+	snsClient, ingester, consumerService := new(MockSNSClient), new(MockIngester), new(MockConsumerService)
+	consumerService.On("GetAppStatus").Return(&model.AppStatusDTO{Status: status})
+
+	return services.NewSNSService(config, snsClient, ingester, consumerService), snsClient, ingester
+}
+
+func statusCode(err error) int {
+	var apiError *server.Error
+	if errors.As(err, &apiError) {
+		return apiError.StatusCode
+	}
+	return http.StatusInternalServerError
+}
+
+func TestSNSService_Receive(t *testing.T) {
+	snsService, snsClient, ingester := newSNSService(services.SNSConfig{}, model.Started)
+	snsClient.On("Verify", sns.NotificationType).Return(nil)
+	ingester.On("Ingest", "1").Return(nil)
+
+	err := snsService.Receive(context.Background(), []byte(notification))
+
+	assert.NoError(t, err)
+	ingester.AssertExpectations(t)
+}
+
+func TestSNSService_ReceiveIngestErr(t *testing.T) {
+	snsService, snsClient, ingester := newSNSService(services.SNSConfig{}, model.Started)
+	snsClient.On("Verify", sns.NotificationType).Return(nil)
+	ingester.On("Ingest", "1").Return(errors.New("internal server error"))
+
+	err := snsService.Receive(context.Background(), []byte(notification))
+
+	assert.Error(t, err)
+}
+
+func TestSNSService_ReceiveStopped(t *testing.T) {
+	snsService, snsClient, ingester := newSNSService(services.SNSConfig{}, model.Stopped)
+	snsClient.On("Verify", sns.NotificationType).Return(nil)
+
+	err := snsService.Receive(context.Background(), []byte(notification))
+
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode(err))
+	ingester.AssertNotCalled(t, "Ingest", mock.Anything)
+}
+
+func TestSNSService_ReceiveInvalidSignature(t *testing.T) {
+	snsService, snsClient, ingester := newSNSService(services.SNSConfig{}, model.Started)
+	snsClient.On("Verify", sns.NotificationType).Return(errors.New("invalid signature"))
+
+	err := snsService.Receive(context.Background(), []byte(notification))
+
+	assert.Equal(t, http.StatusForbidden, statusCode(err))
+	ingester.AssertNotCalled(t, "Ingest", mock.Anything)
+}
+
+func TestSNSService_ReceiveTopicNotAllowed(t *testing.T) {
+	snsService, snsClient, _ := newSNSService(services.SNSConfig{
+		TopicArns: []string{"arn:aws:sns:us-east-1:000000000000:users-topic"},
+	}, model.Started)
+
+	err := snsService.Receive(context.Background(), []byte(notification))
+
+	assert.Equal(t, http.StatusForbidden, statusCode(err))
+	snsClient.AssertNotCalled(t, "Verify", mock.Anything)
+}
+
+func TestSNSService_ReceiveInvalidBody(t *testing.T) {
+	snsService, _, _ := newSNSService(services.SNSConfig{}, model.Started)
+
+	err := snsService.Receive(context.Background(), []byte("invalid"))
+
+	assert.Equal(t, http.StatusBadRequest, statusCode(err))
+}
+
+func TestSNSService_ConfirmSubscription(t *testing.T) {
+	snsService, snsClient, ingester := newSNSService(services.SNSConfig{}, model.Stopped)
+	snsClient.On("Verify", sns.SubscriptionConfirmationType).Return(nil)
+	snsClient.On("ConfirmSubscription", "https://sns.us-east-1.amazonaws.com/confirm").Return(nil)
+
+	err := snsService.Receive(context.Background(), []byte(subscriptionConfirmation))
+
+	assert.NoError(t, err)
+	snsClient.AssertExpectations(t)
+	ingester.AssertNotCalled(t, "Ingest", mock.Anything)
+}
+
+func TestSNSService_ReceiveRouteByAttribute(t *testing.T) {
+	snsClient, consumerService := new(MockSNSClient), new(MockConsumerService)
+	consumerService.On("GetAppStatus").Return(&model.AppStatusDTO{Status: model.Started})
+	snsClient.On("Verify", sns.NotificationType).Return(nil)
+
+	createdPusher, defaultPusher := new(MockPusher), new(MockPusher)
+	createdPusher.On("SendMessage", "1").Return(nil)
+
+	orderConsumer := consumer.NewConsumer(consumer.Config{
+		Pusher: pusher.NewRouter([]pusher.Route{{
+			Name:      "created",
+			Predicate: pusher.Predicate{Attributes: map[string]string{"event": "order.created"}},
+			Pusher:    createdPusher,
+		}}, pusher.Route{Pusher: defaultPusher}),
+	}, consumerService)
+	snsService := services.NewSNSService(services.SNSConfig{}, snsClient, orderConsumer, consumerService)
+
+	err := snsService.Receive(context.Background(), []byte(attributeNotification))
+
+	assert.NoError(t, err)
+	createdPusher.AssertExpectations(t)
+	defaultPusher.AssertNotCalled(t, "SendMessage", mock.Anything)
+}
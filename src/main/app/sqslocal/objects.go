@@ -0,0 +1,100 @@
+package sqslocal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/src/main/app/server"
+)
+
+// ObjectStore implements the path style s3 PutObject, GetObject and DeleteObject over memory, for the objects of
+// the extended client pointers. Buckets are created on the first put.
+type ObjectStore struct {
+	mutex   sync.RWMutex
+	objects map[string][]byte
+}
+
+type objectError struct {
+	XMLName xml.Name `xml:"Error"`
+	Code    string   `xml:"Code"`
+	Message string   `xml:"Message"`
+}
+
+func NewObjectStore() *ObjectStore {
+	return &ObjectStore{
+		objects: map[string][]byte{},
+	}
+}
+
+func (o *ObjectStore) Register(app *server.App) {
+	app.Route(http.MethodPut, "/:bucket/*", o.PutObject)
+	app.Route(http.MethodGet, "/:bucket/*", o.GetObject)
+	app.Route(http.MethodDelete, "/:bucket/*", o.DeleteObject)
+}
+
+func (o *ObjectStore) PutObject(ctx *fiber.Ctx) error {
+	location, err := objectLocation(ctx)
+	if err != nil {
+		return writeObjectError(ctx, http.StatusBadRequest, "InvalidArgument", err.Error())
+	}
+
+	o.mutex.Lock()
+	o.objects[location] = append([]byte(nil), ctx.Body()...)
+	o.mutex.Unlock()
+
+	return ctx.SendStatus(http.StatusOK)
+}
+
+func (o *ObjectStore) GetObject(ctx *fiber.Ctx) error {
+	location, err := objectLocation(ctx)
+	if err != nil {
+		return writeObjectError(ctx, http.StatusBadRequest, "InvalidArgument", err.Error())
+	}
+
+	o.mutex.RLock()
+	object, found := o.objects[location]
+	o.mutex.RUnlock()
+
+	if !found {
+		return writeObjectError(ctx, http.StatusNotFound, "NoSuchKey", fmt.Sprintf("%s does not exist", location))
+	}
+
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	return ctx.Send(object)
+}
+
+// DeleteObject of a missing object succeeds, as s3.
+func (o *ObjectStore) DeleteObject(ctx *fiber.Ctx) error {
+	location, err := objectLocation(ctx)
+	if err != nil {
+		return writeObjectError(ctx, http.StatusBadRequest, "InvalidArgument", err.Error())
+	}
+
+	o.mutex.Lock()
+	delete(o.objects, location)
+	o.mutex.Unlock()
+
+	return ctx.SendStatus(http.StatusNoContent)
+}
+
+func objectLocation(ctx *fiber.Ctx) (string, error) {
+	key, err := url.PathUnescape(ctx.Params("*"))
+	if err != nil {
+		return "", err
+	}
+
+	if key == "" {
+		return "", fmt.Errorf("key is required")
+	}
+
+	return ctx.Params("bucket") + "/" + key, nil
+}
+
+func writeObjectError(ctx *fiber.Ctx, statusCode int, code string, message string) error {
+	ctx.Status(statusCode)
+	return ctx.XML(objectError{Code: code, Message: message})
+}
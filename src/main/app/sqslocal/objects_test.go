@@ -0,0 +1,130 @@
+package sqslocal_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/server"
+	"github.com/src/main/app/sqslocal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newObjectServer(t *testing.T) aws.Config {
+	app := server.New()
+	sqslocal.New(sqslocal.Config{
+		Account: "000000000000",
+		Region:  "us-east-1",
+	}, queue.NewMemoryBroker()).Register(app)
+	sqslocal.NewObjectStore().Register(app)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	go func() {
+		assert.NoError(t, app.Starter(listener))
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, app.Server.Shutdown())
+	})
+
+	return aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(_ context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		BaseEndpoint: aws.String(fmt.Sprintf("http://%s", listener.Addr())),
+	}
+}
+
+func newS3Client(awsConfig aws.Config) *s3.Client {
+	return s3.NewFromConfig(awsConfig, func(options *s3.Options) {
+		options.UsePathStyle = true
+	})
+}
+
+func TestObjectStore_PutGetDelete(t *testing.T) {
+	client := newS3Client(newObjectServer(t))
+
+	_, err := client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String("orders-payloads"),
+		Key:    aws.String("orders/7c0e3f1a"),
+		Body:   bytes.NewReader([]byte(`{"order_id": 1}`)),
+	})
+	assert.NoError(t, err)
+
+	output, err := client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("orders-payloads"),
+		Key:    aws.String("orders/7c0e3f1a"),
+	})
+	assert.NoError(t, err)
+	object, err := io.ReadAll(output.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"order_id": 1}`, string(object))
+
+	_, err = client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String("orders-payloads"),
+		Key:    aws.String("orders/7c0e3f1a"),
+	})
+	assert.NoError(t, err)
+
+	_, err = client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("orders-payloads"),
+		Key:    aws.String("orders/7c0e3f1a"),
+	})
+	var noSuchKey *s3types.NoSuchKey
+	assert.True(t, errors.As(err, &noSuchKey))
+}
+
+func TestObjectStore_ExtendedPayload(t *testing.T) {
+	awsConfig := newObjectServer(t)
+	sqsClient, queueURL := newQueue(t, awsConfig)
+	s3Client := newS3Client(awsConfig)
+
+	_, err := s3Client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket: aws.String("orders-payloads"),
+		Key:    aws.String("7c0e3f1a"),
+		Body:   bytes.NewReader([]byte(`{"order_id": 1}`)),
+	})
+	assert.NoError(t, err)
+
+	_, err = sqsClient.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl: aws.String(queueURL),
+		MessageBody: aws.String(`["software.amazon.payloadoffloading.PayloadS3Pointer",` +
+			`{"s3BucketName":"orders-payloads","s3Key":"7c0e3f1a"}]`),
+	})
+	assert.NoError(t, err)
+
+	queueClient, err := queue.NewClient(queue.Config{
+		Name:             "orders-consumer",
+		URL:              queueURL,
+		Parallel:         10,
+		Timeout:          1000,
+		ExtendedPayloads: true,
+		DeletePayloads:   true,
+		S3PathStyle:      true,
+	}, awsConfig)
+	assert.NoError(t, err)
+
+	messages, err := queueClient.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, `{"order_id": 1}`, messages[0].Body)
+
+	assert.NoError(t, queueClient.Delete(context.Background(), messages[0].ReceiptHandle))
+
+	_, err = s3Client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String("orders-payloads"),
+		Key:    aws.String("7c0e3f1a"),
+	})
+	assert.Error(t, err)
+}
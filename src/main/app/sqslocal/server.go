@@ -0,0 +1,631 @@
+package sqslocal
+
+import (
+	"crypto/md5" //nolint:gosec // sqs checksums message bodies with md5
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/server"
+)
+
+const (
+	targetPrefix  = "AmazonSQS."
+	errorPrefix   = "com.amazonaws.sqs#"
+	maxBatch      = 10
+	maxWait       = 20
+	contentType   = "application/x-amz-json-1.0"
+	allAttributes = "All"
+)
+
+// Server implements the subset of the sqs json protocol used by the app over a queue.MemoryBroker,
+// so the aws sdk client can point to it through aws.url.
+type Server struct {
+	config Config
+	broker *queue.MemoryBroker
+}
+
+type Config struct {
+	Account string
+	Region  string
+}
+
+func New(config Config, broker *queue.MemoryBroker) *Server {
+	return &Server{
+		config: config,
+		broker: broker,
+	}
+}
+
+func (s Server) Register(app *server.App) {
+	app.Route(http.MethodPost, "/", s.Handle)
+}
+
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func NewError(code string, message string) *Error {
+	return &Error{StatusCode: http.StatusBadRequest, Code: code, Message: message}
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// Handle dispatches the action of the X-Amz-Target header, errors are written as sqs json errors.
+func (s Server) Handle(ctx *fiber.Ctx) error {
+	target := ctx.Get("X-Amz-Target")
+	action := strings.TrimPrefix(target, targetPrefix)
+
+	response, err := s.dispatch(ctx, action)
+	if err != nil {
+		var apiError *Error
+		if !errors.As(err, &apiError) {
+			apiError = &Error{StatusCode: http.StatusInternalServerError, Code: "InternalError", Message: err.Error()}
+		}
+		log.Debugf("sqs local %s error: %s", action, apiError)
+		ctx.Status(apiError.StatusCode)
+		return ctx.JSON(map[string]string{
+			"__type":  errorPrefix + apiError.Code,
+			"message": apiError.Message,
+		}, contentType)
+	}
+
+	return ctx.JSON(response, contentType)
+}
+
+func (s Server) dispatch(ctx *fiber.Ctx, action string) (any, error) {
+	switch action {
+	case "CreateQueue":
+		return handle(ctx, s.createQueue)
+	case "GetQueueUrl":
+		return handle(ctx, s.getQueueURL)
+	case "ListQueues":
+		return handle(ctx, s.listQueues)
+	case "PurgeQueue":
+		return handle(ctx, s.purgeQueue)
+	case "GetQueueAttributes":
+		return handle(ctx, s.getQueueAttributes)
+	case "SendMessage":
+		return handle(ctx, s.sendMessage)
+	case "SendMessageBatch":
+		return handle(ctx, s.sendMessageBatch)
+	case "ReceiveMessage":
+		return handle(ctx, s.receiveMessage)
+	case "DeleteMessage":
+		return handle(ctx, s.deleteMessage)
+	case "DeleteMessageBatch":
+		return handle(ctx, s.deleteMessageBatch)
+	case "ChangeMessageVisibility":
+		return handle(ctx, s.changeMessageVisibility)
+	case "ChangeMessageVisibilityBatch":
+		return handle(ctx, s.changeMessageVisibilityBatch)
+	default:
+		return nil, NewError("UnsupportedOperation", fmt.Sprintf("action %s is not supported", action))
+	}
+}
+
+func handle[T any](ctx *fiber.Ctx, action func(ctx *fiber.Ctx, request T) (any, error)) (any, error) {
+	request := new(T)
+	if err := json.Unmarshal(ctx.Body(), request); err != nil {
+		return nil, NewError("InvalidParameterValue", err.Error())
+	}
+
+	return action(ctx, *request)
+}
+
+type createQueueRequest struct {
+	QueueName  string            `json:"QueueName"`
+	Attributes map[string]string `json:"Attributes"`
+}
+
+type queueURLResponse struct {
+	QueueURL string `json:"QueueUrl"`
+}
+
+func (s Server) createQueue(ctx *fiber.Ctx, request createQueueRequest) (any, error) {
+	if request.QueueName == "" {
+		return nil, NewError("InvalidParameterValue", "queue name is required")
+	}
+
+	attributes, err := s.parseAttributes(request.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	s.broker.GetOrCreate(request.QueueName, *attributes)
+	return queueURLResponse{QueueURL: s.queueURL(ctx, request.QueueName)}, nil
+}
+
+type getQueueURLRequest struct {
+	QueueName string `json:"QueueName"`
+}
+
+func (s Server) getQueueURL(ctx *fiber.Ctx, request getQueueURLRequest) (any, error) {
+	if _, found := s.broker.Get(request.QueueName); !found {
+		return nil, queueDoesNotExist(request.QueueName)
+	}
+
+	return queueURLResponse{QueueURL: s.queueURL(ctx, request.QueueName)}, nil
+}
+
+type listQueuesRequest struct {
+	QueueNamePrefix string `json:"QueueNamePrefix"`
+}
+
+type listQueuesResponse struct {
+	QueueURLs []string `json:"QueueUrls"`
+}
+
+func (s Server) listQueues(ctx *fiber.Ctx, request listQueuesRequest) (any, error) {
+	response := listQueuesResponse{QueueURLs: []string{}}
+	for _, name := range s.broker.Names() {
+		if strings.HasPrefix(name, request.QueueNamePrefix) {
+			response.QueueURLs = append(response.QueueURLs, s.queueURL(ctx, name))
+		}
+	}
+
+	return response, nil
+}
+
+type queueRequest struct {
+	QueueURL string `json:"QueueUrl"`
+}
+
+func (s Server) purgeQueue(_ *fiber.Ctx, request queueRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	memoryQueue.Purge()
+	return struct{}{}, nil
+}
+
+type getQueueAttributesRequest struct {
+	QueueURL       string   `json:"QueueUrl"`
+	AttributeNames []string `json:"AttributeNames"`
+}
+
+type attributesResponse struct {
+	Attributes map[string]string `json:"Attributes,omitempty"`
+}
+
+func (s Server) getQueueAttributes(_ *fiber.Ctx, request getQueueAttributesRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := memoryQueue.Stats()
+	attributes := memoryQueue.Attributes()
+	values := map[string]string{
+		"QueueArn":                              s.queueArn(memoryQueue.Name),
+		"ApproximateNumberOfMessages":           strconv.Itoa(stats.Visible),
+		"ApproximateNumberOfMessagesNotVisible": strconv.Itoa(stats.InFlight),
+		"ApproximateNumberOfMessagesDelayed":    strconv.Itoa(stats.Delayed),
+		"VisibilityTimeout":                     strconv.Itoa(int(attributes.VisibilityTimeout.Seconds())),
+		"DelaySeconds":                          strconv.Itoa(int(attributes.Delay.Seconds())),
+	}
+
+	if attributes.DeadLetterQueue != nil {
+		redrivePolicy, _ := json.Marshal(map[string]string{
+			"deadLetterTargetArn": s.queueArn(attributes.DeadLetterQueue.Name),
+			"maxReceiveCount":     strconv.Itoa(attributes.MaxReceiveCount),
+		})
+		values["RedrivePolicy"] = string(redrivePolicy)
+	}
+
+	return attributesResponse{Attributes: filter(values, request.AttributeNames)}, nil
+}
+
+// messageAttribute only keeps string values, binary attributes are not supported.
+type messageAttribute struct {
+	DataType    string `json:"DataType"`
+	StringValue string `json:"StringValue"`
+}
+
+type sendMessageRequest struct {
+	QueueURL          string                      `json:"QueueUrl"`
+	MessageBody       string                      `json:"MessageBody"`
+	DelaySeconds      int                         `json:"DelaySeconds"`
+	MessageAttributes map[string]messageAttribute `json:"MessageAttributes"`
+}
+
+type sendMessageResponse struct {
+	MessageID        string `json:"MessageId"`
+	MD5OfMessageBody string `json:"MD5OfMessageBody"`
+}
+
+func (s Server) sendMessage(_ *fiber.Ctx, request sendMessageRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.MessageBody == "" {
+		return nil, NewError("MissingParameter", "message body is required")
+	}
+
+	messageID := memoryQueue.Send(queue.SendMessageDTO{
+		Body:       request.MessageBody,
+		Attributes: toAttributes(request.MessageAttributes),
+		Delay:      time.Second * time.Duration(request.DelaySeconds),
+	})
+
+	return sendMessageResponse{MessageID: messageID, MD5OfMessageBody: checksum(request.MessageBody)}, nil
+}
+
+type sendMessageBatchRequest struct {
+	QueueURL string `json:"QueueUrl"`
+	Entries  []struct {
+		ID                string                      `json:"Id"`
+		MessageBody       string                      `json:"MessageBody"`
+		DelaySeconds      int                         `json:"DelaySeconds"`
+		MessageAttributes map[string]messageAttribute `json:"MessageAttributes"`
+	} `json:"Entries"`
+}
+
+type sendMessageBatchEntry struct {
+	ID               string `json:"Id"`
+	MessageID        string `json:"MessageId"`
+	MD5OfMessageBody string `json:"MD5OfMessageBody"`
+}
+
+type batchErrorEntry struct {
+	ID          string `json:"Id"`
+	Code        string `json:"Code"`
+	Message     string `json:"Message"`
+	SenderFault bool   `json:"SenderFault"`
+}
+
+type sendMessageBatchResponse struct {
+	Successful []sendMessageBatchEntry `json:"Successful"`
+	Failed     []batchErrorEntry       `json:"Failed"`
+}
+
+func (s Server) sendMessageBatch(_ *fiber.Ctx, request sendMessageBatchRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateBatch(len(request.Entries)); err != nil {
+		return nil, err
+	}
+
+	response := sendMessageBatchResponse{Successful: []sendMessageBatchEntry{}, Failed: []batchErrorEntry{}}
+	for _, entry := range request.Entries {
+		if entry.MessageBody == "" {
+			response.Failed = append(response.Failed, senderFault(entry.ID, "MissingParameter", "message body is required"))
+			continue
+		}
+
+		messageID := memoryQueue.Send(queue.SendMessageDTO{
+			Body:       entry.MessageBody,
+			Attributes: toAttributes(entry.MessageAttributes),
+			Delay:      time.Second * time.Duration(entry.DelaySeconds),
+		})
+		response.Successful = append(response.Successful, sendMessageBatchEntry{
+			ID:               entry.ID,
+			MessageID:        messageID,
+			MD5OfMessageBody: checksum(entry.MessageBody),
+		})
+	}
+
+	return response, nil
+}
+
+type receiveMessageRequest struct {
+	QueueURL              string   `json:"QueueUrl"`
+	MaxNumberOfMessages   int      `json:"MaxNumberOfMessages"`
+	VisibilityTimeout     *int     `json:"VisibilityTimeout"`
+	WaitTimeSeconds       int      `json:"WaitTimeSeconds"`
+	AttributeNames        []string `json:"AttributeNames"`
+	MessageAttributeNames []string `json:"MessageAttributeNames"`
+}
+
+type message struct {
+	MessageID         string                      `json:"MessageId"`
+	ReceiptHandle     string                      `json:"ReceiptHandle"`
+	MD5OfBody         string                      `json:"MD5OfBody"`
+	Body              string                      `json:"Body"`
+	Attributes        map[string]string           `json:"Attributes,omitempty"`
+	MessageAttributes map[string]messageAttribute `json:"MessageAttributes,omitempty"`
+}
+
+type receiveMessageResponse struct {
+	Messages []message `json:"Messages"`
+}
+
+func (s Server) receiveMessage(ctx *fiber.Ctx, request receiveMessageRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	maxMsg := request.MaxNumberOfMessages
+	if maxMsg == 0 {
+		maxMsg = 1
+	}
+
+	if maxMsg < 1 || maxMsg > maxBatch {
+		return nil, NewError("InvalidParameterValue",
+			fmt.Sprintf("max number of messages valid values: 1 to %d: given %d", maxBatch, maxMsg))
+	}
+
+	if request.WaitTimeSeconds < 0 || request.WaitTimeSeconds > maxWait {
+		return nil, NewError("InvalidParameterValue",
+			fmt.Sprintf("wait time seconds valid values: 0 to %d: given %d", maxWait, request.WaitTimeSeconds))
+	}
+
+	visibilityTimeout := memoryQueue.Attributes().VisibilityTimeout
+	if request.VisibilityTimeout != nil {
+		visibilityTimeout = time.Second * time.Duration(*request.VisibilityTimeout)
+	}
+
+	messages := memoryQueue.ReceiveWait(ctx.UserContext(), maxMsg, visibilityTimeout,
+		time.Second*time.Duration(request.WaitTimeSeconds))
+
+	response := receiveMessageResponse{Messages: make([]message, len(messages))}
+	for i, messageDTO := range messages {
+		response.Messages[i] = message{
+			MessageID:     messageDTO.MessageID,
+			ReceiptHandle: messageDTO.ReceiptHandle,
+			MD5OfBody:     checksum(messageDTO.Body),
+			Body:          messageDTO.Body,
+			Attributes: filter(map[string]string{
+				"ApproximateReceiveCount": strconv.Itoa(messageDTO.ReceiveCount),
+			}, request.AttributeNames),
+			MessageAttributes: toMessageAttributes(filter(messageDTO.Attributes, request.MessageAttributeNames)),
+		}
+	}
+
+	return response, nil
+}
+
+type deleteMessageRequest struct {
+	QueueURL      string `json:"QueueUrl"`
+	ReceiptHandle string `json:"ReceiptHandle"`
+}
+
+func (s Server) deleteMessage(_ *fiber.Ctx, request deleteMessageRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = memoryQueue.Delete(request.ReceiptHandle); err != nil {
+		return nil, NewError("ReceiptHandleIsInvalid", err.Error())
+	}
+
+	return struct{}{}, nil
+}
+
+type deleteMessageBatchRequest struct {
+	QueueURL string `json:"QueueUrl"`
+	Entries  []struct {
+		ID            string `json:"Id"`
+		ReceiptHandle string `json:"ReceiptHandle"`
+	} `json:"Entries"`
+}
+
+type batchEntry struct {
+	ID string `json:"Id"`
+}
+
+type batchResponse struct {
+	Successful []batchEntry      `json:"Successful"`
+	Failed     []batchErrorEntry `json:"Failed"`
+}
+
+func (s Server) deleteMessageBatch(_ *fiber.Ctx, request deleteMessageBatchRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateBatch(len(request.Entries)); err != nil {
+		return nil, err
+	}
+
+	response := batchResponse{Successful: []batchEntry{}, Failed: []batchErrorEntry{}}
+	for _, entry := range request.Entries {
+		if err = memoryQueue.Delete(entry.ReceiptHandle); err != nil {
+			response.Failed = append(response.Failed, senderFault(entry.ID, "ReceiptHandleIsInvalid", err.Error()))
+			continue
+		}
+		response.Successful = append(response.Successful, batchEntry{ID: entry.ID})
+	}
+
+	return response, nil
+}
+
+type changeMessageVisibilityRequest struct {
+	QueueURL          string `json:"QueueUrl"`
+	ReceiptHandle     string `json:"ReceiptHandle"`
+	VisibilityTimeout int    `json:"VisibilityTimeout"`
+}
+
+func (s Server) changeMessageVisibility(_ *fiber.Ctx, request changeMessageVisibilityRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = memoryQueue.ChangeVisibility(request.ReceiptHandle,
+		time.Second*time.Duration(request.VisibilityTimeout)); err != nil {
+		return nil, NewError("ReceiptHandleIsInvalid", err.Error())
+	}
+
+	return struct{}{}, nil
+}
+
+type changeMessageVisibilityBatchRequest struct {
+	QueueURL string `json:"QueueUrl"`
+	Entries  []struct {
+		ID                string `json:"Id"`
+		ReceiptHandle     string `json:"ReceiptHandle"`
+		VisibilityTimeout int    `json:"VisibilityTimeout"`
+	} `json:"Entries"`
+}
+
+func (s Server) changeMessageVisibilityBatch(_ *fiber.Ctx, request changeMessageVisibilityBatchRequest) (any, error) {
+	memoryQueue, err := s.queue(request.QueueURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = validateBatch(len(request.Entries)); err != nil {
+		return nil, err
+	}
+
+	response := batchResponse{Successful: []batchEntry{}, Failed: []batchErrorEntry{}}
+	for _, entry := range request.Entries {
+		if err = memoryQueue.ChangeVisibility(entry.ReceiptHandle,
+			time.Second*time.Duration(entry.VisibilityTimeout)); err != nil {
+			response.Failed = append(response.Failed, senderFault(entry.ID, "ReceiptHandleIsInvalid", err.Error()))
+			continue
+		}
+		response.Successful = append(response.Successful, batchEntry{ID: entry.ID})
+	}
+
+	return response, nil
+}
+
+// parseAttributes maps the create queue attributes, a redrive policy target is created when missing.
+func (s Server) parseAttributes(values map[string]string) (*queue.MemoryQueueAttributes, error) {
+	attributes := new(queue.MemoryQueueAttributes)
+	attributes.VisibilityTimeout = time.Second * 30
+
+	for name, value := range values {
+		switch name {
+		case "VisibilityTimeout", "DelaySeconds":
+			seconds, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, NewError("InvalidAttributeValue", fmt.Sprintf("invalid %s: %s", name, value))
+			}
+			if name == "VisibilityTimeout" {
+				attributes.VisibilityTimeout = time.Second * time.Duration(seconds)
+			} else {
+				attributes.Delay = time.Second * time.Duration(seconds)
+			}
+		case "RedrivePolicy":
+			redrivePolicy := new(struct {
+				DeadLetterTargetArn string `json:"deadLetterTargetArn"`
+				MaxReceiveCount     any    `json:"maxReceiveCount"`
+			})
+			if err := json.Unmarshal([]byte(value), redrivePolicy); err != nil {
+				return nil, NewError("InvalidAttributeValue", fmt.Sprintf("invalid %s: %s", name, err))
+			}
+			maxReceiveCount, err := strconv.Atoi(fmt.Sprint(redrivePolicy.MaxReceiveCount))
+			if err != nil || maxReceiveCount < 1 {
+				return nil, NewError("InvalidAttributeValue", fmt.Sprintf("invalid %s max receive count", name))
+			}
+			deadLetterQueue := redrivePolicy.DeadLetterTargetArn[strings.LastIndex(redrivePolicy.DeadLetterTargetArn, ":")+1:]
+			if deadLetterQueue == "" {
+				return nil, NewError("InvalidAttributeValue", fmt.Sprintf("invalid %s dead letter target arn", name))
+			}
+			attributes.MaxReceiveCount = maxReceiveCount
+			attributes.DeadLetterQueue = s.broker.GetOrCreate(deadLetterQueue, queue.MemoryQueueAttributes{
+				VisibilityTimeout: time.Second * 30,
+			})
+		default:
+			log.Debugf("sqs local: ignored queue attribute %s", name)
+		}
+	}
+
+	return attributes, nil
+}
+
+// queue resolves the queue by the last path segment of the queue url, as any host or account is accepted.
+func (s Server) queue(queueURL string) (*queue.MemoryQueue, error) {
+	name := queueURL[strings.LastIndex(queueURL, "/")+1:]
+	memoryQueue, found := s.broker.Get(name)
+	if !found {
+		return nil, queueDoesNotExist(name)
+	}
+
+	return memoryQueue, nil
+}
+
+func (s Server) queueURL(ctx *fiber.Ctx, name string) string {
+	return fmt.Sprintf("%s/%s/%s", ctx.BaseURL(), s.config.Account, name)
+}
+
+func (s Server) queueArn(name string) string {
+	return fmt.Sprintf("arn:aws:sqs:%s:%s:%s", s.config.Region, s.config.Account, name)
+}
+
+func queueDoesNotExist(name string) *Error {
+	return NewError("QueueDoesNotExist", fmt.Sprintf("the specified queue %s does not exist", name))
+}
+
+func validateBatch(size int) error {
+	if size == 0 {
+		return NewError("EmptyBatchRequest", "there should be at least one entry in the request")
+	}
+
+	if size > maxBatch {
+		return NewError("TooManyEntriesInBatchRequest", fmt.Sprintf("maximum number of entries per request is %d", maxBatch))
+	}
+
+	return nil
+}
+
+func senderFault(id string, code string, message string) batchErrorEntry {
+	return batchErrorEntry{ID: id, Code: code, Message: message, SenderFault: true}
+}
+
+// filter keeps the requested attribute names, All keeps every attribute.
+func filter(values map[string]string, names []string) map[string]string {
+	filtered := make(map[string]string)
+	for _, name := range names {
+		if name == allAttributes {
+			return values
+		}
+		if value, found := values[name]; found {
+			filtered[name] = value
+		}
+	}
+
+	return filtered
+}
+
+func toAttributes(messageAttributes map[string]messageAttribute) map[string]string {
+	if len(messageAttributes) == 0 {
+		return nil
+	}
+
+	attributes := make(map[string]string, len(messageAttributes))
+	for name, value := range messageAttributes {
+		attributes[name] = value.StringValue
+	}
+
+	return attributes
+}
+
+func toMessageAttributes(attributes map[string]string) map[string]messageAttribute {
+	messageAttributes := make(map[string]messageAttribute, len(attributes))
+	for name, value := range attributes {
+		messageAttributes[name] = messageAttribute{DataType: "String", StringValue: value}
+	}
+
+	return messageAttributes
+}
+
+func checksum(body string) string {
+	sum := md5.Sum([]byte(body)) //nolint:gosec // sqs checksums message bodies with md5
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,339 @@
+package sqslocal_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/server"
+	"github.com/src/main/app/sqslocal"
+	"github.com/stretchr/testify/assert"
+)
+
+func newServer(t *testing.T) aws.Config {
+	app := server.New()
+	sqslocal.New(sqslocal.Config{
+		Account: "000000000000",
+		Region:  "us-east-1",
+	}, queue.NewMemoryBroker()).Register(app)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	go func() {
+		assert.NoError(t, app.Starter(listener))
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, app.Server.Shutdown())
+	})
+
+	return aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(_ context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+		BaseEndpoint: aws.String(fmt.Sprintf("http://%s", listener.Addr())),
+	}
+}
+
+func newClient(t *testing.T) (*sqs.Client, string) {
+	return newQueue(t, newServer(t))
+}
+
+func newQueue(t *testing.T, awsConfig aws.Config) (*sqs.Client, string) {
+	client := sqs.NewFromConfig(awsConfig)
+
+	output, err := client.CreateQueue(context.Background(), &sqs.CreateQueueInput{
+		QueueName: aws.String("orders-consumer"),
+	})
+	assert.NoError(t, err)
+
+	return client, aws.ToString(output.QueueUrl)
+}
+
+func TestServer_CreateQueue(t *testing.T) {
+	client, queueURL := newClient(t)
+
+	assert.Contains(t, queueURL, "/000000000000/orders-consumer")
+
+	output, err := client.GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{
+		QueueName: aws.String("orders-consumer"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, queueURL, aws.ToString(output.QueueUrl))
+
+	queues, err := client.ListQueues(context.Background(), &sqs.ListQueuesInput{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{queueURL}, queues.QueueUrls)
+}
+
+func TestServer_QueueDoesNotExist(t *testing.T) {
+	client := sqs.NewFromConfig(newServer(t))
+
+	_, err := client.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:    aws.String("http://localhost:4566/000000000000/missing"),
+		MessageBody: aws.String("msg1"),
+	})
+
+	var queueDoesNotExist *types.QueueDoesNotExist
+	assert.True(t, errors.As(err, &queueDoesNotExist))
+}
+
+func TestServer_SendReceiveDelete(t *testing.T) {
+	client, queueURL := newClient(t)
+
+	sent, err := client.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String(`{"order_id": 1}`),
+	})
+	assert.NoError(t, err)
+
+	received, err := client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(queueURL),
+		MaxNumberOfMessages: 10,
+		WaitTimeSeconds:     1,
+		AttributeNames:      []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, received.Messages, 1)
+	assert.Equal(t, aws.ToString(sent.MessageId), aws.ToString(received.Messages[0].MessageId))
+	assert.Equal(t, `{"order_id": 1}`, aws.ToString(received.Messages[0].Body))
+	assert.Equal(t, "1", received.Messages[0].Attributes["ApproximateReceiveCount"])
+
+	_, err = client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: received.Messages[0].ReceiptHandle,
+	})
+	assert.NoError(t, err)
+
+	_, err = client.DeleteMessage(context.Background(), &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(queueURL),
+		ReceiptHandle: received.Messages[0].ReceiptHandle,
+	})
+	assert.Error(t, err)
+}
+
+func TestServer_ChangeMessageVisibility(t *testing.T) {
+	client, queueURL := newClient(t)
+
+	_, err := client.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String("msg1"),
+	})
+	assert.NoError(t, err)
+
+	received, err := client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+		QueueUrl: aws.String(queueURL),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, received.Messages, 1)
+
+	empty, err := client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+		QueueUrl: aws.String(queueURL),
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, empty.Messages)
+
+	_, err = client.ChangeMessageVisibility(context.Background(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(queueURL),
+		ReceiptHandle:     received.Messages[0].ReceiptHandle,
+		VisibilityTimeout: 0,
+	})
+	assert.NoError(t, err)
+
+	redelivered, err := client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+		QueueUrl: aws.String(queueURL),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, redelivered.Messages, 1)
+}
+
+func TestServer_Batch(t *testing.T) {
+	client, queueURL := newClient(t)
+
+	sent, err := client.SendMessageBatch(context.Background(), &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries: []types.SendMessageBatchRequestEntry{
+			{Id: aws.String("1"), MessageBody: aws.String("msg1")},
+			{Id: aws.String("2"), MessageBody: aws.String("msg2")},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, sent.Successful, 2)
+	assert.Empty(t, sent.Failed)
+
+	received, err := client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+		QueueUrl:            aws.String(queueURL),
+		MaxNumberOfMessages: 10,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, received.Messages, 2)
+
+	changed, err := client.ChangeMessageVisibilityBatch(context.Background(), &sqs.ChangeMessageVisibilityBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries: []types.ChangeMessageVisibilityBatchRequestEntry{
+			{Id: aws.String("1"), ReceiptHandle: received.Messages[0].ReceiptHandle, VisibilityTimeout: 60},
+			{Id: aws.String("2"), ReceiptHandle: aws.String("invalid"), VisibilityTimeout: 60},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, changed.Successful, 1)
+	assert.Len(t, changed.Failed, 1)
+	assert.Equal(t, "2", aws.ToString(changed.Failed[0].Id))
+
+	deleted, err := client.DeleteMessageBatch(context.Background(), &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries: []types.DeleteMessageBatchRequestEntry{
+			{Id: aws.String("1"), ReceiptHandle: received.Messages[0].ReceiptHandle},
+			{Id: aws.String("2"), ReceiptHandle: received.Messages[1].ReceiptHandle},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, deleted.Successful, 2)
+	assert.Empty(t, deleted.Failed)
+}
+
+func TestServer_EmptyBatch(t *testing.T) {
+	client, queueURL := newClient(t)
+
+	_, err := client.DeleteMessageBatch(context.Background(), &sqs.DeleteMessageBatchInput{
+		QueueUrl: aws.String(queueURL),
+		Entries:  []types.DeleteMessageBatchRequestEntry{},
+	})
+
+	var emptyBatch *types.EmptyBatchRequest
+	assert.True(t, errors.As(err, &emptyBatch))
+}
+
+func TestServer_GetQueueAttributes(t *testing.T) {
+	client := sqs.NewFromConfig(newServer(t))
+
+	created, err := client.CreateQueue(context.Background(), &sqs.CreateQueueInput{
+		QueueName: aws.String("orders-consumer"),
+		Attributes: map[string]string{
+			"VisibilityTimeout": "60",
+			"RedrivePolicy":     `{"deadLetterTargetArn":"arn:aws:sqs:us-east-1:000000000000:orders-consumer-dlq","maxReceiveCount":"3"}`,
+		},
+	})
+	assert.NoError(t, err)
+
+	_, err = client.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:    created.QueueUrl,
+		MessageBody: aws.String("msg1"),
+	})
+	assert.NoError(t, err)
+
+	_, err = client.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:     created.QueueUrl,
+		MessageBody:  aws.String("msg2"),
+		DelaySeconds: 60,
+	})
+	assert.NoError(t, err)
+
+	output, err := client.GetQueueAttributes(context.Background(), &sqs.GetQueueAttributesInput{
+		QueueUrl:       created.QueueUrl,
+		AttributeNames: []types.QueueAttributeName{types.QueueAttributeNameAll},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", output.Attributes["ApproximateNumberOfMessages"])
+	assert.Equal(t, "0", output.Attributes["ApproximateNumberOfMessagesNotVisible"])
+	assert.Equal(t, "1", output.Attributes["ApproximateNumberOfMessagesDelayed"])
+	assert.Equal(t, "60", output.Attributes["VisibilityTimeout"])
+	assert.Equal(t, "arn:aws:sqs:us-east-1:000000000000:orders-consumer", output.Attributes["QueueArn"])
+	assert.Contains(t, output.Attributes["RedrivePolicy"], "orders-consumer-dlq")
+
+	queues, err := client.ListQueues(context.Background(), &sqs.ListQueuesInput{
+		QueueNamePrefix: aws.String("orders-consumer-dlq"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, queues.QueueUrls, 1)
+}
+
+func TestServer_AWSQueueService(t *testing.T) {
+	awsConfig := newServer(t)
+	client, queueURL := newQueue(t, awsConfig)
+
+	_, err := client.SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:    aws.String(queueURL),
+		MessageBody: aws.String("msg1"),
+	})
+	assert.NoError(t, err)
+
+	queueService, err := queue.NewClient(queue.Config{
+		URL:      queueURL,
+		Parallel: 10,
+		Timeout:  1000,
+	}, awsConfig)
+	assert.NoError(t, err)
+
+	messages, err := queueService.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "msg1", messages[0].String())
+
+	count, err := queueService.Count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, aws.ToInt(count))
+
+	err = queueService.Delete(context.Background(), messages[0].ReceiptHandle)
+	assert.NoError(t, err)
+}
+
+func TestServer_AWSQueueServiceSendBatch(t *testing.T) {
+	awsConfig := newServer(t)
+	_, queueURL := newQueue(t, awsConfig)
+
+	queueService, err := queue.NewClient(queue.Config{
+		URL:      queueURL,
+		Parallel: 10,
+		Timeout:  1000,
+	}, awsConfig)
+	assert.NoError(t, err)
+
+	results, err := queueService.SendBatch(context.Background(), []queue.SendMessageDTO{
+		{Body: "msg1", Attributes: map[string]string{"event": "created"}},
+		{Body: "msg2", Delay: time.Hour},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.NotEmpty(t, results[0].MessageID)
+	assert.NoError(t, results[1].Err)
+
+	messages, err := queueService.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, results[0].MessageID, messages[0].MessageID)
+	assert.Equal(t, map[string]string{"event": "created"}, messages[0].Attributes)
+}
+
+func TestServer_AWSQueueServicePeek(t *testing.T) {
+	awsConfig := newServer(t)
+	_, queueURL := newQueue(t, awsConfig)
+
+	queueService, err := queue.NewClient(queue.Config{
+		URL:      queueURL,
+		Parallel: 10,
+		Timeout:  1000,
+	}, awsConfig)
+	assert.NoError(t, err)
+
+	_, err = queueService.Send(context.Background(), queue.SendMessageDTO{Body: "msg1"})
+	assert.NoError(t, err)
+
+	actual, err := queueService.Peek(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Len(t, actual, 1)
+	assert.Equal(t, 1, actual[0].ReceiveCount)
+
+	messages, err := queueService.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "msg1", messages[0].String())
+}
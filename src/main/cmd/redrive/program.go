@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/src/main/app/container"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/model"
+	"github.com/src/main/app/services"
+)
+
+// Redrive moves messages between configured queues, usually from a dead letter queue back to its source queue.
+//
+//	go run ./src/main/cmd/redrive -from orders-dlq -to orders -max 100 -rate 10 -dry-run
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run() error {
+	from := flag.String("from", "", "queues.<name> block to move the messages from, usually a dead letter queue")
+	to := flag.String("to", "", "queues.<name> block to move the messages to")
+	maxMessages := flag.Int("max", services.DefaultRedriveMessages, "max messages to move")
+	messagesPerSecond := flag.Float64("rate", 0, "max messages by second, 0 is unlimited")
+	bodyContains := flag.String("body-contains", "", "only move the messages whose body contains the value")
+	attributes := flag.String("attributes", "", "only move the messages with the attributes, comma separated key=value")
+	dryRun := flag.Bool("dry-run", false, "count the matching messages without moving them")
+	flag.Parse()
+
+	if *from == "" || *to == "" {
+		flag.Usage()
+		return errors.New("from and to queues are required")
+	}
+
+	var filter *model.RedriveFilterDTO
+	if *bodyContains != "" || *attributes != "" {
+		filter = &model.RedriveFilterDTO{
+			BodyContains: *bodyContains,
+			Attributes:   make(map[string]string),
+		}
+		for _, attribute := range strings.Split(*attributes, ",") {
+			if key, value, found := strings.Cut(strings.TrimSpace(attribute), "="); found {
+				filter.Attributes[key] = value
+			}
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	queueService := services.NewQueueService(services.QueueConfig{
+		Queues: []string{*from, *to},
+	}, container.GetQueueService)
+
+	result, err := queueService.Redrive(ctx, *from, &model.RedriveDTO{
+		Destination: *to,
+		MaxMessages: *maxMessages,
+		Rate:        *messagesPerSecond,
+		Filter:      filter,
+		DryRun:      *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Infof("%d matched, %d moved, %d skipped, %d failed", result.Matched, result.Moved, result.Skipped, result.Failed)
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/registrylocal"
+	"github.com/src/main/app/server"
+)
+
+// Schema registry stand-in over src/resources/schemas, point consumers.<name>.decode.registry.url to it.
+func main() {
+	schemas := map[int]string{}
+	for _, item := range strings.Split(config.TryString("registry-local.schemas", ""), ",") {
+		id, location, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found {
+			continue
+		}
+		schemaID, err := strconv.Atoi(strings.TrimSpace(id))
+		if err != nil {
+			log.Fatal(fmt.Errorf("invalid schema id %s: %w", id, err))
+		}
+		schemas[schemaID] = strings.TrimSpace(location)
+		log.Infof("registry local schema %d: %s", schemaID, schemas[schemaID])
+	}
+
+	registry, err := registrylocal.New(registrylocal.Config{Schemas: schemas})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	app := server.New()
+	registry.Register(app)
+
+	address := fmt.Sprintf("%s:%d",
+		config.TryString("registry-local.host", "localhost"),
+		config.TryInt("registry-local.port", 8081))
+	log.Infof("registry local listening on %s", address)
+
+	if err = app.Start(address); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/src/main/app/config"
+	"github.com/src/main/app/infrastructure/queue"
+	"github.com/src/main/app/log"
+	"github.com/src/main/app/server"
+	"github.com/src/main/app/sqslocal"
+)
+
+// Embedded sqs compatible endpoint over in-memory queues, with the s3 objects of the extended client pointers,
+// point aws.url to it to run without localstack.
+func main() {
+	broker := queue.NewMemoryBroker()
+	for _, name := range strings.Split(config.TryString("sqs-local.queues", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			broker.GetOrCreate(name, queue.MemoryQueueAttributes{VisibilityTimeout: time.Second * 30})
+			log.Infof("sqs local queue %s created", name)
+		}
+	}
+
+	app := server.New()
+	sqslocal.New(sqslocal.Config{
+		Account: config.TryString("sqs-local.account", "000000000000"),
+		Region:  config.TryString("aws.region", "us-east-1"),
+	}, broker).Register(app)
+	// s3 objects of the extended client pointers, queues.<name>.extended-payloads
+	sqslocal.NewObjectStore().Register(app)
+
+	address := fmt.Sprintf("%s:%d",
+		config.TryString("sqs-local.host", "localhost"),
+		config.TryInt("sqs-local.port", 4566))
+	log.Infof("sqs local listening on %s", address)
+
+	if err := app.Start(address); err != nil {
+		log.Fatal(err)
+	}
+}
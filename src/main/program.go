@@ -9,6 +9,9 @@ import (
 // @title Golang Template API
 // @description This is a sample golang template api. Have fun.
 // @basePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 // @version v1.
 func main() {
 	if err := app.Run(); err != nil {
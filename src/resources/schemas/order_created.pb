@@ -0,0 +1,8 @@
+
+
+orders/order_created.protoorders"b
+OrderCreated
+order_id (RorderId
+customer_id (R
+customerId
+status (	Rstatusbproto3
\ No newline at end of file